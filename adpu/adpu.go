@@ -7,15 +7,27 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/ntchjb/ledger-go/device"
-	"github.com/ntchjb/ledger-go/log"
 )
 
 const (
 	// ADPU header length for a block
 	ADPU_BLOCK_HEADER_LENGTH uint16 = 5
 	FRAME_HEADER_TAG         uint8  = 0x05
+
+	// Maximum data length of short ADPU command, where Lc is encoded in 1 byte
+	MAX_SHORT_DATA_LENGTH int = 0xFF
+	// Maximum data length of extended ADPU command, where Lc is encoded in 3 bytes
+	// The whole command, [CLA, INS, P1, P2, Lc(3), data..., Le(2)], needs to fit in
+	// 2-byte command length of HID frames
+	MAX_EXTENDED_DATA_LENGTH int = 0xFFFF - 9
+	// Maximum length of ADPU response received from stream-based transport, including SW
+	MAX_STREAM_RESPONSE_LENGTH int = 0xFFFF + 2
+
+	// Duration without any frame from device, after which stale frames are considered drained
+	DEFAULT_DRAIN_TIMEOUT = 200 * time.Millisecond
 )
 
 var (
@@ -26,163 +38,445 @@ var (
 	ErrBlockChannelNotMatch  = errors.New("block channel not match")
 	ErrBlockTagNotMatch      = errors.New("block tag not match")
 	ErrBlockSequenceNotMatch = errors.New("block sequence not match")
+	ErrMalformedCommand      = errors.New("malformed ADPU command")
+	ErrDeviceBusy            = errors.New("device is busy with aborted exchange")
 )
 
 type Response struct {
-	Length uint16
+	Length int
 	Data   []byte
 }
 
 type Protocol interface {
 	Exchange(ctx context.Context, command []byte) ([]byte, error)
 	Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error)
+	// Discard stale frames left by an aborted exchange, so the next exchange starts on a clean channel.
+	// If the aborted command was fully written, it waits for the whole response of the command first.
+	// It returns when device has been idle for the drain timeout, or the context is done.
+	Reset(ctx context.Context) error
 }
 
 type protocolImpl struct {
-	Device     device.Device
-	logger     *slog.Logger
-	channel    uint16
-	packetSize uint16
+	Device         device.Device
+	logger         *slog.Logger
+	framer         Framer
+	extendedLength bool
+	drainTimeout   time.Duration
+	interceptors   []Interceptor
+	tracer         Tracer
+	metrics        Metrics
+	redaction      *RedactionPolicy
 
 	exchangeLock sync.RWMutex
+	// Whether previous exchange was aborted after command was written, so device may still send its response
+	desynchronized bool
+	// Response of the aborted exchange whose command was fully written, which device sends eventually
+	pending *pendingResponse
+}
+
+// Response being reassembled from frames, where `sequence` is the sequence of the next frame
+type pendingResponse struct {
+	res      Response
+	sequence uint16
 }
 
-func NewProtocol(device device.Device, channel uint16, logger *slog.Logger) Protocol {
-	return &protocolImpl{
-		Device:     device,
-		logger:     logger,
-		channel:    channel,
-		packetSize: 64,
+func (p *pendingResponse) done() bool {
+	return p.sequence > 0 && len(p.res.Data) >= p.res.Length
+}
+
+type ProtocolOption func(p *protocolImpl)
+
+// Encode ADPU commands in extended-length format, where Lc is 3 bytes (0x00, Lc1, Lc2)
+// and Le is 2 bytes, allowing command data up to `MAX_EXTENDED_DATA_LENGTH` bytes.
+// The device application must support extended-length ADPU.
+func WithExtendedLength() ProtocolOption {
+	return func(p *protocolImpl) {
+		p.extendedLength = true
 	}
 }
 
-func (a *protocolImpl) createDataFrames(data []byte) [][]byte {
-	a.logger.Debug("Creating data frames", "length", len(data))
-	var blocks [][]byte
-	dataLength := uint16(len(data))
-	// It's the length of this array: [dataLength..., data...]
-	noPaddingTotalLength := 2 + dataLength
-	blockSizeWithoutHeader := a.packetSize - ADPU_BLOCK_HEADER_LENGTH
-	numBlocks := noPaddingTotalLength / blockSizeWithoutHeader
-	if noPaddingTotalLength%blockSizeWithoutHeader != 0 {
-		numBlocks++
+// Use given framer instead of Ledger's HID report scheme i.e.
+// `NewStreamFramer()` for stream-based transports such as TCP transport of Speculos
+func WithFramer(framer Framer) ProtocolOption {
+	return func(p *protocolImpl) {
+		p.framer = framer
 	}
-	a.logger.Debug("Data frame properties", "numBlocks", numBlocks, "blockSizeWithoutHeader", blockSizeWithoutHeader)
+}
 
-	padding := make([]byte, numBlocks*blockSizeWithoutHeader-noPaddingTotalLength+1)
-	dataLengthBytes := make([]byte, 2)
-	binary.BigEndian.PutUint16(dataLengthBytes, dataLength)
+// Send and receive whole ADPU messages without HID framing,
+// for stream-based transports that frame messages by themselves i.e. TCP transport of Speculos.
+// It is the same as `WithFramer(NewStreamFramer())`.
+func WithStreamTransport() ProtocolOption {
+	return WithFramer(NewStreamFramer())
+}
 
-	// Final data should be
-	// [dataLength..., data..., padding...]
-	data = append(dataLengthBytes, data...)
-	data = append(data, padding...)
+// Duration without any frame from device, after which stale frames of an aborted exchange are considered drained.
+// It should be longer than the interval between frames of a response.
+func WithDrainTimeout(timeout time.Duration) ProtocolOption {
+	return func(p *protocolImpl) {
+		p.drainTimeout = timeout
+	}
+}
 
-	a.logger.Debug("Final data before convert to frames", "data", log.HexDisplay(data))
+// Create ADPU protocol over given device.
+// By default, ADPU messages are framed by Ledger's HID report scheme using given channel,
+// which can be changed by `WithFramer` option.
+func NewProtocol(device device.Device, channel uint16, logger *slog.Logger, opts ...ProtocolOption) Protocol {
+	proto := &protocolImpl{
+		Device:       device,
+		logger:       logger,
+		framer:       NewHIDFramer(channel, HID_PACKET_SIZE, logger),
+		drainTimeout: DEFAULT_DRAIN_TIMEOUT,
+		tracer:       NewNoopTracer(),
+		metrics:      NewNoopMetrics(),
+	}
 
-	for i := uint16(0); i < numBlocks; i++ {
-		header := make([]byte, 5)
-		binary.BigEndian.PutUint16(header[:2], a.channel)
-		header[2] = FRAME_HEADER_TAG
-		binary.BigEndian.PutUint16(header[3:5], i)
+	for _, opt := range opts {
+		opt(proto)
+	}
+
+	return proto
+}
 
-		blocks = append(blocks, append(header, data[i*blockSizeWithoutHeader:(i+1)*blockSizeWithoutHeader]...))
+// Create ADPU protocol over Bluetooth LE device.
+// MTU is negotiated with the device before creating the protocol,
+// so that every frame fits the negotiated MTU.
+func NewBLEProtocol(ctx context.Context, device device.BLEDevice, logger *slog.Logger, opts ...ProtocolOption) (Protocol, error) {
+	mtu, err := device.MTU(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to negotiate MTU: %w", err)
 	}
+	logger.Debug("BLE MTU negotiated", "mtu", mtu)
 
-	a.logger.Debug("Frames", "blockCount", len(blocks))
+	opts = append([]ProtocolOption{WithFramer(NewBLEFramer(mtu, logger))}, opts...)
 
-	return blocks
+	return NewProtocol(device, 0, logger, opts...), nil
 }
 
-func (a *protocolImpl) reduceDataFrames(res Response, expectedSequence uint16, block []byte) (Response, error) {
-	a.logger.Debug("Reducing a frame", "blockLength", len(block), "expectedSequence", expectedSequence, "resLength", res.Length)
-	channel := binary.BigEndian.Uint16(block[:2])
-	tag := block[2]
-	sequence := binary.BigEndian.Uint16(block[3:5])
-	if channel != a.channel {
-		return res, fmt.Errorf("channel does not match, expected %d, got %d: %w", a.channel, channel, ErrBlockChannelNotMatch)
+// Send ADPU command to Device via framing scheme of the transport i.e. Ledger's HID report scheme
+//
+// If the exchange is aborted after command is written i.e. context is cancelled while waiting for
+// user approval, remaining response frames are drained before the next exchange. If the response
+// does not arrive within the drain timeout, the next exchange fails with `ErrDeviceBusy`,
+// and the response is drained again by the exchange after.
+func (a *protocolImpl) Exchange(ctx context.Context, command []byte) ([]byte, error) {
+	if len(a.interceptors) == 0 {
+		return a.transceive(ctx, command)
 	}
-	if tag != byte(FRAME_HEADER_TAG) {
-		return res, fmt.Errorf("tag does not match, expected %d, got %d: %w", FRAME_HEADER_TAG, tag, ErrBlockTagNotMatch)
+
+	header, data, err := DecodeCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode command for interceptors: %w", err)
 	}
-	if sequence != expectedSequence {
-		return res, fmt.Errorf("sequence does not match, expected %d, got %d: %w", expectedSequence, sequence, ErrBlockSequenceNotMatch)
+	result, err := a.intercept(ctx, Call{Header: header, Data: data}, command)
+	if err != nil {
+		return nil, err
 	}
 
-	// Read length for the first block
-	if len(res.Data) == 0 {
-		res.Length = binary.BigEndian.Uint16(block[5:7])
-		res.Data = append(res.Data, block[7:]...)
-	} else {
-		res.Data = append(res.Data, block[5:]...)
-	}
+	return binary.BigEndian.AppendUint16(append([]byte{}, result.Data...), result.SW), nil
+}
 
-	// Remove padding from res.Data at the last block
-	if len(res.Data) > int(res.Length) {
-		res.Data = res.Data[:res.Length]
+// Exchange command with device, after draining stale frames of aborted exchange, if any
+func (a *protocolImpl) transceive(ctx context.Context, command []byte) (res []byte, err error) {
+	ctx, span := a.tracer.Start(ctx, "adpu.Exchange")
+	start := time.Now()
+	defer func() {
+		a.endSpan(ctx, span, time.Since(start), command, res, err)
+	}()
+
+	a.exchangeLock.Lock()
+	defer a.exchangeLock.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if a.desynchronized {
+		if err := a.drain(ctx, false); err != nil {
+			return nil, err
+		}
 	}
 
-	return res, nil
+	return a.exchange(ctx, span, command)
 }
 
-// Send ADPU command to Device via Ledger's HID report scheme
-// Given command
-func (a *protocolImpl) Exchange(ctx context.Context, command []byte) ([]byte, error) {
+// Record command, SW and duration of the exchange to span, and count user rejections
+func (a *protocolImpl) endSpan(ctx context.Context, span Span, duration time.Duration, command []byte, res []byte, err error) {
+	var sw uint16
+	if len(res) >= 2 {
+		sw = binary.BigEndian.Uint16(res[len(res)-2:])
+		if swSentinels[sw] == ErrUserRefused {
+			a.metrics.Add(ctx, COUNTER_USER_REJECTIONS, 1)
+		}
+	}
+	if span.IsRecording() {
+		attrs := make([]slog.Attr, 0, 7)
+		attrs = append(attrs, slog.Duration(ATTR_DURATION, duration))
+		if len(command) >= 4 {
+			attrs = append(attrs,
+				slog.Int(ATTR_CLA, int(command[0])),
+				slog.Int(ATTR_INS, int(command[1])),
+				slog.Int(ATTR_P1, int(command[2])),
+				slog.Int(ATTR_P2, int(command[3])),
+			)
+		}
+		if index, ok := ChunkFromContext(ctx); ok {
+			attrs = append(attrs, slog.Int(ATTR_CHUNK, index))
+		}
+		if len(res) >= 2 {
+			attrs = append(attrs, slog.Int(ATTR_SW, int(sw)))
+		}
+		span.SetAttributes(attrs...)
+	}
+	span.End(err)
+}
+
+func (a *protocolImpl) Reset(ctx context.Context) error {
 	a.exchangeLock.Lock()
 	defer a.exchangeLock.Unlock()
 
-	a.logger.Debug("ADPU Command", "command", log.HexDisplay(command))
+	return a.drain(ctx, true)
+}
+
+// Read and discard frames until device is idle for drain timeout,
+// after the whole response of aborted exchange, if any, is discarded
+func (a *protocolImpl) drain(ctx context.Context, wait bool) error {
+	if a.pending != nil {
+		if err := a.drainResponse(ctx, wait); err != nil {
+			return err
+		}
+	}
+	a.logger.Debug("Draining stale frames", "timeout", a.drainTimeout)
+	data := make([]byte, a.framer.FrameSize())
+	for count := 0; ; count++ {
+		readCtx, cancel := context.WithTimeout(ctx, a.drainTimeout)
+		n, err := a.Device.Read(readCtx, data)
+		idle := readCtx.Err() != nil
+		cancel()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("unable to drain stale frames, drained %d frames: %w: %w", count, ctxErr, err)
+			}
+			// Stale frames are gone with the previous connection
+			if errors.Is(err, device.ErrDisconnected) {
+				idle = true
+			}
+			if !idle {
+				return fmt.Errorf("unable to drain stale frames, drained %d frames: %w", count, err)
+			}
+			a.logger.Debug("Stale frames are drained", "count", count)
+			a.desynchronized = false
+			return nil
+		}
+		a.logger.Debug("DROP <==", "i", count, "block", a.frameValue(nil, data[:n], true))
+	}
+}
+
+// Reassemble and discard the response of aborted exchange. Idle device does not mean a clean channel here,
+// as device sends the response only after user approves or rejects the command. Without `wait`,
+// it fails with `ErrDeviceBusy` if the response is not complete within drain timeout,
+// keeping frames read so far for the next attempt.
+func (a *protocolImpl) drainResponse(ctx context.Context, wait bool) error {
+	a.logger.Debug("Draining response of aborted exchange", "wait", wait, "sequence", a.pending.sequence)
+	readCtx := ctx
+	if !wait {
+		var cancel context.CancelFunc
+		readCtx, cancel = context.WithTimeout(ctx, a.drainTimeout)
+		defer cancel()
+	}
+	for !a.pending.done() {
+		data := make([]byte, a.framer.FrameSize())
+		n, err := a.Device.Read(readCtx, data)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("unable to drain response of aborted exchange: %w: %w", ctxErr, err)
+			}
+			if readCtx.Err() != nil {
+				return fmt.Errorf("response of aborted exchange is not received within %s: %w", a.drainTimeout, ErrDeviceBusy)
+			}
+			// Response is gone with the previous connection, or consumed by the device,
+			// so the rest is left to idle draining
+			a.logger.Debug("Unable to read response of aborted exchange", "err", err)
+			break
+		}
+		a.logger.Debug("DROP <==", "i", a.pending.sequence, "block", a.frameValue(nil, data[:n], true))
+		res, err := a.framer.Reduce(a.pending.res, a.pending.sequence, data[:n])
+		if err != nil {
+			// Not a frame of the response, so the rest is left to idle draining
+			a.logger.Debug("Unable to reassemble response of aborted exchange", "err", err)
+			break
+		}
+		a.pending.res = res
+		a.pending.sequence++
+	}
+	a.pending = nil
+
+	return nil
+}
+
+func (a *protocolImpl) exchange(ctx context.Context, span Span, command []byte) ([]byte, error) {
+	a.logger.Debug("ADPU Command", "command", a.commandValue(command))
+	start := time.Now()
+	var written, firstRead time.Time
 
 	// #1: Send ADPU command to device, in blocks
-	blocks := a.createDataFrames(command)
+	blocks, err := a.framer.Frames(command)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create frames from command: %w", err)
+	}
+	// Cleared only when the whole response is read
+	a.desynchronized = true
 	for i, block := range blocks {
 		n, err := a.Device.Write(ctx, block)
-		a.logger.Debug("SEND ==>", "i", i, "block", log.HexDisplay(block))
+		a.logger.Debug("SEND ==>", "i", i, "block", a.frameValue(command, block, false))
 		if err != nil {
-			return nil, fmt.Errorf("unable to write a block to HID device via interrupt OUT: %w", err)
+			return nil, fmt.Errorf("unable to write a block to device: %w", err)
 		}
+		a.metrics.Add(ctx, COUNTER_FRAMES_SENT, 1)
+		a.metrics.Add(ctx, COUNTER_BYTES_SENT, int64(n))
 		if n != len(block) {
 			return nil, fmt.Errorf("incomplete block write, need to write %d bytes, only written %d bytes: %w", len(block), n, ErrIncompleteWrite)
 		}
 	}
+	written = time.Now()
 
 	// #2: Receive ADPU response from device, in blocks
-	var res Response
-	var expectedSequence uint16
-	for res.Length == 0 || len(res.Data) < int(res.Length) {
-		data := make([]byte, a.packetSize)
+	// Device sends the response even if reading is aborted, so it is kept to be drained
+	a.pending = &pendingResponse{}
+	for !a.pending.done() {
+		data := make([]byte, a.framer.FrameSize())
 		n, err := a.Device.Read(ctx, data)
 		if err != nil {
-			return nil, fmt.Errorf("unable to read a block from HID device via interrupt IN: res: %v, err: %w", res, err)
+			err = fmt.Errorf("unable to read a block from device, received %d of %d bytes: %w", len(a.pending.res.Data), a.pending.res.Length, err)
+			// Only aborted read keeps the response pending. Otherwise, response may be already consumed
+			// by the device, e.g. discarded as too long, so the rest is left to idle draining.
+			if ctx.Err() == nil {
+				a.pending = nil
+			}
+			return nil, err
 		}
-		if n != int(a.packetSize) {
-			return nil, fmt.Errorf("read data is not equal to expected packet size, expected %d, got %d, err: %w", a.packetSize, n, ErrIncompleteRead)
+		if a.pending.sequence == 0 {
+			firstRead = time.Now()
 		}
-		res, err = a.reduceDataFrames(res, expectedSequence, data)
+		a.metrics.Add(ctx, COUNTER_FRAMES_RECEIVED, 1)
+		a.metrics.Add(ctx, COUNTER_BYTES_RECEIVED, int64(n))
+		a.logger.Debug("RECV <==", "i", a.pending.sequence, "block", a.frameValue(command, data[:n], true))
+		res, err := a.framer.Reduce(a.pending.res, a.pending.sequence, data[:n])
 		if err != nil {
-			return nil, fmt.Errorf("unable to reduce frame blocks, res: %v, err: %w", res, err)
+			// Response is malformed, so the rest is left to idle draining
+			a.pending = nil
+			return nil, fmt.Errorf("unable to reduce frame blocks, received %d of %d bytes: %w", len(res.Data), res.Length, err)
 		}
-		expectedSequence++
+		a.pending.res = res
+		a.pending.sequence++
 	}
 
-	a.logger.Debug("ADPU Response", "res", log.HexDisplay(res.Data))
+	res := a.pending.res
+	a.pending = nil
+	a.desynchronized = false
+	a.logger.Debug("ADPU Response", "res", a.responseValue(command, res.Data))
+	if span.IsRecording() {
+		end := time.Now()
+		span.SetAttributes(
+			slog.Duration(ATTR_WRITE_DURATION, written.Sub(start)),
+			slog.Duration(ATTR_WAIT_DURATION, firstRead.Sub(written)),
+			slog.Duration(ATTR_READ_DURATION, end.Sub(firstRead)),
+		)
+	}
 
 	return res.Data, nil
 }
 
+// Build ADPU command bytes
+//
+// Short: [CLA, INS, P1, P2, Lc, data...]
+//
+// Extended: [CLA, INS, P1, P2, 0x00, Lc1, Lc2, data..., Le1, Le2],
+// where Lc is omitted if there is no data, and Le = 0x0000 means maximum response length
+func encodeCommand(cla, ins, p1, p2 uint8, data []byte, extendedLength bool) ([]byte, error) {
+	if !extendedLength {
+		if len(data) > MAX_SHORT_DATA_LENGTH {
+			return nil, fmt.Errorf("maximum data length of ADPU command exceeded, expected <=%d, got %d, err: %w", MAX_SHORT_DATA_LENGTH, len(data), ErrADPUPayloadTooLong)
+		}
+
+		return append([]byte{cla, ins, p1, p2, uint8(len(data))}, data...), nil
+	}
+
+	if len(data) > MAX_EXTENDED_DATA_LENGTH {
+		return nil, fmt.Errorf("maximum data length of extended ADPU command exceeded, expected <=%d, got %d, err: %w", MAX_EXTENDED_DATA_LENGTH, len(data), ErrADPUPayloadTooLong)
+	}
+
+	command := []byte{cla, ins, p1, p2, 0x00}
+	if len(data) > 0 {
+		command = binary.BigEndian.AppendUint16(command, uint16(len(data)))
+		command = append(command, data...)
+	}
+	command = append(command, 0x00, 0x00)
+
+	return command, nil
+}
+
+// Decode ADPU command in either short or extended format, as built by `Protocol.Send`,
+// or with trailing Le in short format, i.e. [CLA, INS, P1, P2, Le] and [CLA, INS, P1, P2, Lc, data..., Le]
+func DecodeCommand(command []byte) (CommandHeader, []byte, error) {
+	if len(command) < 4 {
+		return CommandHeader{}, nil, fmt.Errorf("command is too short, expected >=4, got %d: %w", len(command), ErrMalformedCommand)
+	}
+	header := CommandHeader{CLA: command[0], INS: command[1], P1: command[2], P2: command[3]}
+	body := command[4:]
+
+	switch {
+	case len(body) == 0:
+		return header, nil, nil
+	case len(body) == 1+int(body[0]):
+		return header, body[1:], nil
+	case len(body) == 1:
+		// Short format without data, [Le]
+		return header, nil, nil
+	case body[0] != 0x00 && len(body) == 2+int(body[0]):
+		// Short format with Le, [Lc, data..., Le]
+		return header, body[1 : 1+int(body[0])], nil
+	case body[0] == 0x00 && len(body) == 3:
+		// Extended format without data, [0x00, Le (2 bytes)]
+		return header, nil, nil
+	case body[0] == 0x00 && len(body) > 3:
+		// Extended format, [0x00, Lc (2 bytes), data..., Le (2 bytes, optional)]
+		length := int(binary.BigEndian.Uint16(body[1:3]))
+		if len(body) == 3+length || len(body) == 5+length {
+			return header, body[3 : 3+length], nil
+		}
+	}
+
+	return CommandHeader{}, nil, fmt.Errorf("data length does not match Lc, command length: %d: %w", len(command), ErrMalformedCommand)
+}
+
 func (a *protocolImpl) Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
-	var command []byte
-	if len(data) > 255 {
-		return nil, 0, fmt.Errorf("maximum data length of ADPU command exceeded, expected <256, got %d, err: %w", len(data), ErrADPUPayloadTooLong)
+	a.logger.Debug("Sending ADPU command", "cla", cla, "ins", ins, "p1", p1, "p2", p2, "extended", a.extendedLength, "data", a.dataValue(ins, data))
+	command, err := encodeCommand(cla, ins, p1, p2, data, a.extendedLength)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to encode ADPU command: %w", err)
+	}
+
+	if len(a.interceptors) > 0 {
+		result, err := a.intercept(ctx, Call{Header: CommandHeader{CLA: cla, INS: ins, P1: p1, P2: p2}, Data: data}, command)
+		if err != nil {
+			return nil, 0, fmt.Errorf("unable to exchange ADPU, command: %s, err: %w", a.commandValue(command), err)
+		}
+		return result.Data, result.SW, nil
 	}
-	a.logger.Debug("Sending ADPU command", "cla", cla, "ins", ins, "p1", p1, "p2", p2, "data", log.HexDisplay(data))
-	command = append([]byte{cla, ins, p1, p2, uint8(len(data))}, data...)
 
-	res, err := a.Exchange(ctx, command)
+	res, err := a.transceive(ctx, command)
 	if err != nil {
-		return nil, 0, fmt.Errorf("unable to exchange ADPU, command: %s, err: %w", log.HexDisplay(command), err)
+		return nil, 0, fmt.Errorf("unable to exchange ADPU, command: %s, err: %w", a.commandValue(command), err)
 	}
 
+	return splitSW(res)
+}
+
+// Split ADPU response into response data and SW
+func splitSW(res []byte) ([]byte, uint16, error) {
+	if len(res) < 2 {
+		return nil, 0, fmt.Errorf("response is too short to contain SW, got %d bytes: %w", len(res), ErrIncompleteRead)
+	}
 	sw := binary.BigEndian.Uint16(res[len(res)-2:])
 
 	return res[:len(res)-2], sw, nil
@@ -199,7 +493,7 @@ func Send[RS Unmarshaler, RQ Marshaler](ctx context.Context, proto Protocol, cla
 	}
 
 	if sw != SW_OK {
-		return fmt.Errorf("sw code: %s: %w", SWMessage[sw], ErrSWNotOK)
+		return NewStatusError(sw, cla, ins, p1, p2)
 	}
 
 	if err := Unmarshal(response, res); err != nil {
@@ -54,6 +54,20 @@ func (mr *MockProtocolMockRecorder) Exchange(ctx, command any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockProtocol)(nil).Exchange), ctx, command)
 }
 
+// Reset mocks base method.
+func (m *MockProtocol) Reset(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockProtocolMockRecorder) Reset(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockProtocol)(nil).Reset), ctx)
+}
+
 // Send mocks base method.
 func (m *MockProtocol) Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
 	m.ctrl.T.Helper()
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/ntchjb/ledger-go/adpu"
 	"github.com/ntchjb/ledger-go/device"
@@ -21,6 +22,7 @@ func TestProtocol_Send(t *testing.T) {
 		name    string
 		device  func(ctrl *gomock.Controller) device.Device
 		channel uint16
+		opts    []adpu.ProtocolOption
 		ctx     context.Context
 		cla     uint8
 		ins     uint8
@@ -337,6 +339,114 @@ func TestProtocol_Send(t *testing.T) {
 			sw:  0,
 			err: adpu.ErrADPUPayloadTooLong,
 		},
+		{
+			name: "Success_ExtendedLength",
+			device: func(ctrl *gomock.Controller) device.Device {
+				mock := device.NewMockDevice(ctrl)
+
+				mock.EXPECT().Write(ctx, []byte{
+					0x12, 0x34, 0x05, 0x00, 0x00, // HID Report header
+					0x00, 0x0C, // total ADPU command length = 12
+					0xe0, 0x06, 0x01, 0x02, 0x00, 0x00, 0x03, // ADPU command header, extended Lc: 3
+					0xA1, 0xA2, 0xA3, // data
+					0x00, 0x00, // extended Le
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				}).Return(64, nil)
+
+				mock.EXPECT().Read(ctx, make([]byte, 64)).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+					copy(data, []byte{
+						0x12, 0x34, 0x05, 0x00, 0x00, // HID Report header
+						0x00, 0x03, // Total ADPU response length
+						0xB1, 0x90, 0x00,
+					})
+					return 64, nil
+				})
+
+				return mock
+			},
+			channel: 0x1234,
+			opts:    []adpu.ProtocolOption{adpu.WithExtendedLength()},
+			ctx:     ctx,
+			cla:     0xe0,
+			ins:     0x06,
+			p1:      0x01,
+			p2:      0x02,
+			data:    []byte{0xA1, 0xA2, 0xA3},
+			res:     []byte{0xB1},
+			sw:      0x9000,
+			err:     nil,
+		},
+		{
+			name: "Error_ExtendedLengthDataTooLong",
+			device: func(ctrl *gomock.Controller) device.Device {
+				mock := device.NewMockDevice(ctrl)
+
+				return mock
+			},
+			channel: 0x1234,
+			opts:    []adpu.ProtocolOption{adpu.WithExtendedLength()},
+			ctx:     ctx,
+			cla:     0xe0,
+			ins:     0x06,
+			p1:      0x01,
+			p2:      0x02,
+			data:    make([]byte, adpu.MAX_EXTENDED_DATA_LENGTH+1),
+			res:     nil,
+			sw:      0,
+			err:     adpu.ErrADPUPayloadTooLong,
+		},
+		{
+			name: "Success_StreamTransport",
+			device: func(ctrl *gomock.Controller) device.Device {
+				mock := device.NewMockDevice(ctrl)
+
+				mock.EXPECT().Write(ctx, []byte{
+					0xe0, 0x06, 0x01, 0x02, 0x03, // ADPU command header
+					0xA1, 0xA2, 0xA3, // data
+				}).Return(8, nil)
+				mock.EXPECT().Read(ctx, make([]byte, adpu.MAX_STREAM_RESPONSE_LENGTH)).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+					return copy(data, []byte{0xB1, 0xB2, 0x90, 0x00}), nil
+				})
+
+				return mock
+			},
+			opts: []adpu.ProtocolOption{adpu.WithStreamTransport()},
+			ctx:  ctx,
+			cla:  0xe0,
+			ins:  0x06,
+			p1:   0x01,
+			p2:   0x02,
+			data: []byte{0xA1, 0xA2, 0xA3},
+			res:  []byte{0xB1, 0xB2},
+			sw:   0x9000,
+			err:  nil,
+		},
+		{
+			name: "Error_StreamTransportIncompleteRead",
+			device: func(ctrl *gomock.Controller) device.Device {
+				mock := device.NewMockDevice(ctrl)
+
+				mock.EXPECT().Write(ctx, []byte{
+					0xe0, 0x06, 0x01, 0x02, 0x00, // ADPU command header
+				}).Return(5, nil)
+				mock.EXPECT().Read(ctx, make([]byte, adpu.MAX_STREAM_RESPONSE_LENGTH)).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+					return copy(data, []byte{0x90}), nil
+				})
+
+				return mock
+			},
+			opts: []adpu.ProtocolOption{adpu.WithFramer(adpu.NewStreamFramer())},
+			ctx:  ctx,
+			cla:  0xe0,
+			ins:  0x06,
+			p1:   0x01,
+			p2:   0x02,
+			res:  nil,
+			sw:   0,
+			err:  adpu.ErrIncompleteRead,
+		},
 		{
 			name: "Error_WriteError",
 			device: func(ctrl *gomock.Controller) device.Device {
@@ -628,7 +738,7 @@ func TestProtocol_Send(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			logger := slog.Default()
 
-			proto := adpu.NewProtocol(test.device(ctrl), test.channel, logger)
+			proto := adpu.NewProtocol(test.device(ctrl), test.channel, logger, test.opts...)
 
 			res, sw, err := proto.Send(test.ctx, test.cla, test.ins, test.p1, test.p2, test.data)
 
@@ -780,3 +890,360 @@ func TestSend(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBLEProtocol(t *testing.T) {
+	ctx := context.Background()
+	errSome := errors.New("some error")
+
+	tests := []struct {
+		name   string
+		device func(ctrl *gomock.Controller) device.BLEDevice
+		res    []byte
+		sw     uint16
+		err    error
+	}{
+		{
+			name: "Success",
+			device: func(ctrl *gomock.Controller) device.BLEDevice {
+				mock := device.NewMockBLEDevice(ctrl)
+
+				mock.EXPECT().MTU(ctx).Return(8, nil)
+				mock.EXPECT().Write(ctx, []byte{0x05, 0x00, 0x00, 0x00, 0x08, 0xe0, 0x06, 0x01}).Return(8, nil)
+				mock.EXPECT().Write(ctx, []byte{0x05, 0x00, 0x01, 0x02, 0x03, 0xA1, 0xA2, 0xA3}).Return(8, nil)
+				mock.EXPECT().Read(ctx, make([]byte, 8)).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+					return copy(data, []byte{0x05, 0x00, 0x00, 0x00, 0x03, 0xB1, 0x90, 0x00}), nil
+				})
+
+				return mock
+			},
+			res: []byte{0xB1},
+			sw:  0x9000,
+			err: nil,
+		},
+		{
+			name: "Error_MTUNegotiation",
+			device: func(ctrl *gomock.Controller) device.BLEDevice {
+				mock := device.NewMockBLEDevice(ctrl)
+
+				mock.EXPECT().MTU(ctx).Return(0, errSome)
+
+				return mock
+			},
+			res: nil,
+			sw:  0,
+			err: errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			proto, err := adpu.NewBLEProtocol(ctx, test.device(ctrl), slog.Default())
+			if err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			res, sw, err := proto.Send(ctx, 0xe0, 0x06, 0x01, 0x02, []byte{0xA1, 0xA2, 0xA3})
+
+			assert.Equal(t, test.res, res)
+			assert.Equal(t, test.sw, sw)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestProtocol_WithFramer(t *testing.T) {
+	ctx := context.Background()
+	errSome := errors.New("some error")
+	command := []byte{0xe0, 0x06, 0x01, 0x02, 0x00}
+
+	tests := []struct {
+		name   string
+		framer func(framer *adpu.MockFramer)
+		device func(mock *device.MockDevice)
+		res    []byte
+		sw     uint16
+		err    error
+	}{
+		{
+			name: "Success",
+			framer: func(framer *adpu.MockFramer) {
+				framer.EXPECT().Frames(command).Return([][]byte{{0xF0, 0xe0, 0x06}, {0xF1, 0x01, 0x02, 0x00}}, nil)
+				framer.EXPECT().FrameSize().Return(4).Times(2)
+				framer.EXPECT().Reduce(adpu.Response{}, uint16(0), []byte{0xF0, 0xB1, 0xB2}).
+					Return(adpu.Response{Length: 4, Data: []byte{0xB1, 0xB2}}, nil)
+				framer.EXPECT().Reduce(adpu.Response{Length: 4, Data: []byte{0xB1, 0xB2}}, uint16(1), []byte{0xF1, 0x90, 0x00}).
+					Return(adpu.Response{Length: 4, Data: []byte{0xB1, 0xB2, 0x90, 0x00}}, nil)
+			},
+			device: func(mock *device.MockDevice) {
+				mock.EXPECT().Write(ctx, []byte{0xF0, 0xe0, 0x06}).Return(3, nil)
+				mock.EXPECT().Write(ctx, []byte{0xF1, 0x01, 0x02, 0x00}).Return(4, nil)
+				mock.EXPECT().Read(ctx, make([]byte, 4)).DoAndReturn(readFrame([]byte{0xF0, 0xB1, 0xB2}))
+				mock.EXPECT().Read(ctx, make([]byte, 4)).DoAndReturn(readFrame([]byte{0xF1, 0x90, 0x00}))
+			},
+			res: []byte{0xB1, 0xB2},
+			sw:  0x9000,
+			err: nil,
+		},
+		{
+			name: "Error_Frames",
+			framer: func(framer *adpu.MockFramer) {
+				framer.EXPECT().Frames(command).Return(nil, errSome)
+			},
+			device: func(mock *device.MockDevice) {},
+			res:    nil,
+			sw:     0,
+			err:    errSome,
+		},
+		{
+			name: "Error_Reduce",
+			framer: func(framer *adpu.MockFramer) {
+				framer.EXPECT().Frames(command).Return([][]byte{command}, nil)
+				framer.EXPECT().FrameSize().Return(4)
+				framer.EXPECT().Reduce(adpu.Response{}, uint16(0), []byte{0x90, 0x00}).Return(adpu.Response{}, errSome)
+			},
+			device: func(mock *device.MockDevice) {
+				mock.EXPECT().Write(ctx, command).Return(5, nil)
+				mock.EXPECT().Read(ctx, make([]byte, 4)).DoAndReturn(readFrame([]byte{0x90, 0x00}))
+			},
+			res: nil,
+			sw:  0,
+			err: errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			framer := adpu.NewMockFramer(ctrl)
+			mock := device.NewMockDevice(ctrl)
+			test.framer(framer)
+			test.device(mock)
+			proto := adpu.NewProtocol(mock, 0, slog.Default(), adpu.WithFramer(framer))
+
+			res, sw, err := proto.Send(ctx, 0xe0, 0x06, 0x01, 0x02, nil)
+
+			assert.Equal(t, test.res, res)
+			assert.Equal(t, test.sw, sw)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// Build a HID frame on channel 0x0101, padded to 64 bytes
+func hidFrame(sequence uint8, payload ...byte) []byte {
+	frame := make([]byte, 64)
+	copy(frame, []byte{0x01, 0x01, 0x05, 0x00, sequence})
+	copy(frame[5:], payload)
+
+	return frame
+}
+
+func readFrame(frame []byte) func(ctx context.Context, data []byte) (int, error) {
+	return func(ctx context.Context, data []byte) (int, error) {
+		return copy(data, frame), nil
+	}
+}
+
+func readUntilDone(ctx context.Context, data []byte) (int, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestProtocol_Exchange_DrainAfterCancellation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	signCommand := hidFrame(0x00, 0x00, 0x05, 0xe0, 0x04, 0x00, 0x00, 0x00)
+	versionCommand := hidFrame(0x00, 0x00, 0x05, 0xe0, 0x06, 0x00, 0x00, 0x00)
+	gomock.InOrder(
+		// Signing is cancelled while waiting for user approval
+		mock.EXPECT().Write(gomock.Any(), signCommand).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		// Response of signing arrives after cancellation, in 2 frames
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, 0x00, 0x43, 0xAA))),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x01, 0xBB))),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		mock.EXPECT().Write(gomock.Any(), versionCommand).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, 0x00, 0x04, 0x01, 0x02, 0x90, 0x00))),
+		// Next exchange does not drain, as the previous one is complete
+		mock.EXPECT().Write(gomock.Any(), versionCommand).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, 0x00, 0x04, 0x01, 0x02, 0x90, 0x00))),
+	)
+	proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithDrainTimeout(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := proto.Send(ctx, 0xe0, 0x04, 0x00, 0x00, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	for range 2 {
+		res, sw, err := proto.Send(context.Background(), 0xe0, 0x06, 0x00, 0x00, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x01, 0x02}, res)
+		assert.Equal(t, adpu.SW_OK, sw)
+	}
+}
+
+func TestProtocol_Exchange_DrainLateResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	signCommand := hidFrame(0x00, 0x00, 0x05, 0xe0, 0x04, 0x00, 0x00, 0x00)
+	versionCommand := hidFrame(0x00, 0x00, 0x05, 0xe0, 0x06, 0x00, 0x00, 0x00)
+	gomock.InOrder(
+		// Signing is cancelled while waiting for user approval
+		mock.EXPECT().Write(gomock.Any(), signCommand).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		// User has not approved yet, so device is idle for longer than drain timeout
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		// First frame of signing response arrives, but not the rest
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, 0x00, 0x43, 0xAA))),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		// The rest of signing response, which is not mistaken for the response of version command
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x01, 0xBB))),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		mock.EXPECT().Write(gomock.Any(), versionCommand).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, 0x00, 0x04, 0x01, 0x02, 0x90, 0x00))),
+	)
+	proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithDrainTimeout(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := proto.Send(ctx, 0xe0, 0x04, 0x00, 0x00, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	for range 2 {
+		_, _, err = proto.Send(context.Background(), 0xe0, 0x06, 0x00, 0x00, nil)
+		assert.ErrorIs(t, err, adpu.ErrDeviceBusy)
+	}
+
+	res, sw, err := proto.Send(context.Background(), 0xe0, 0x06, 0x00, 0x00, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, res)
+	assert.Equal(t, adpu.SW_OK, sw)
+}
+
+func TestProtocol_Exchange_ConsumedResponseIsNotPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	gomock.InOrder(
+		// Device discards the response which is too long for the read buffer
+		mock.EXPECT().Write(gomock.Any(), gomock.Any()).Return(5, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).Return(0, device.ErrResponseBufferTooSmall),
+		// Only idle draining, without waiting for the consumed response
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		mock.EXPECT().Write(gomock.Any(), gomock.Any()).Return(5, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame([]byte{0x01, 0x02, 0x90, 0x00})),
+	)
+	proto := adpu.NewProtocol(mock, 0, slog.Default(), adpu.WithStreamTransport(), adpu.WithDrainTimeout(10*time.Millisecond))
+
+	_, _, err := proto.Send(context.Background(), 0xe0, 0x04, 0x00, 0x00, nil)
+	assert.ErrorIs(t, err, device.ErrResponseBufferTooSmall)
+
+	res, sw, err := proto.Send(context.Background(), 0xe0, 0x06, 0x00, 0x00, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, res)
+	assert.Equal(t, adpu.SW_OK, sw)
+}
+
+func TestProtocol_Reset_WaitsForLateResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	gomock.InOrder(
+		mock.EXPECT().Write(gomock.Any(), gomock.Any()).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+		// Response arrives later than drain timeout
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+			time.Sleep(50 * time.Millisecond)
+			return copy(data, hidFrame(0x00, 0x00, 0x02, 0x69, 0x85)), nil
+		}),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+	)
+	proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithDrainTimeout(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := proto.Send(ctx, 0xe0, 0x04, 0x00, 0x00, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.NoError(t, proto.Reset(context.Background()))
+}
+
+func TestProtocol_Reset(t *testing.T) {
+	errSome := errors.New("some error")
+	tests := []struct {
+		name   string
+		device func(mock *device.MockDevice)
+		ctx    func() (context.Context, context.CancelFunc)
+		err    error
+	}{
+		{
+			name: "Success_Idle",
+			device: func(mock *device.MockDevice) {
+				mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone)
+			},
+			ctx: func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			err: nil,
+		},
+		{
+			name: "Success_StaleFrames",
+			device: func(mock *device.MockDevice) {
+				gomock.InOrder(
+					mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, 0x00, 0x02, 0x69, 0x85))),
+					mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readUntilDone),
+				)
+			},
+			ctx: func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			err: nil,
+		},
+		{
+			name: "Success_Disconnected",
+			device: func(mock *device.MockDevice) {
+				mock.EXPECT().Read(gomock.Any(), gomock.Any()).Return(0, device.ErrDisconnected)
+			},
+			ctx: func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			err: nil,
+		},
+		{
+			name: "Error_ReadError",
+			device: func(mock *device.MockDevice) {
+				mock.EXPECT().Read(gomock.Any(), gomock.Any()).Return(0, errSome)
+			},
+			ctx: func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			err: errSome,
+		},
+		{
+			name: "Error_ContextDone",
+			device: func(mock *device.MockDevice) {
+				// Device keeps sending frames
+				mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+					select {
+					case <-ctx.Done():
+						return 0, ctx.Err()
+					case <-time.After(time.Millisecond):
+						return copy(data, hidFrame(0x00)), nil
+					}
+				}).AnyTimes()
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			err: context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := device.NewMockDevice(ctrl)
+			test.device(mock)
+			proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithDrainTimeout(10*time.Millisecond))
+			ctx, cancel := test.ctx()
+			defer cancel()
+
+			err := proto.Reset(ctx)
+
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
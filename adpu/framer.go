@@ -0,0 +1,228 @@
+package adpu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+)
+
+const (
+	// Size of HID report used by Ledger devices
+	HID_PACKET_SIZE uint16 = 64
+	// Header length of BLE frames, [tag (1 byte), sequence (2 bytes)]
+	BLE_FRAME_HEADER_LENGTH int = 3
+)
+
+// Framer converts ADPU messages to and from frames transferred via a transport
+type Framer interface {
+	// Split ADPU command into frames to be written to device
+	Frames(command []byte) ([][]byte, error)
+	// Size of buffer used for reading a frame from device
+	FrameSize() int
+	// Append a frame read from device to the response.
+	// `sequence` is index of the frame in this response, starting from 0.
+	// The response is complete when `res.Length` is set and `res.Data` reaches that length.
+	Reduce(res Response, sequence uint16, frame []byte) (Response, error)
+}
+
+type hidFramer struct {
+	logger     *slog.Logger
+	channel    uint16
+	packetSize uint16
+}
+
+// Create framer of Ledger's HID report scheme
+//
+// Each frame is [channel (2 bytes), tag (1 byte), sequence (2 bytes), payload...]
+// where payload of all frames combined is [length (2 bytes), ADPU message..., padding...]
+func NewHIDFramer(channel uint16, packetSize uint16, logger *slog.Logger) Framer {
+	return &hidFramer{
+		logger:     logger,
+		channel:    channel,
+		packetSize: packetSize,
+	}
+}
+
+func (f *hidFramer) FrameSize() int {
+	return int(f.packetSize)
+}
+
+func (f *hidFramer) Frames(data []byte) ([][]byte, error) {
+	f.logger.Debug("Creating data frames", "length", len(data))
+	if len(data) > 0xFFFF {
+		return nil, fmt.Errorf("data is too long to be framed, expected <=%d, got %d: %w", 0xFFFF, len(data), ErrADPUPayloadTooLong)
+	}
+	var blocks [][]byte
+	// It's the length of this array: [dataLength..., data...]
+	noPaddingTotalLength := 2 + len(data)
+	blockSizeWithoutHeader := int(f.packetSize - ADPU_BLOCK_HEADER_LENGTH)
+	numBlocks := noPaddingTotalLength / blockSizeWithoutHeader
+	if noPaddingTotalLength%blockSizeWithoutHeader != 0 {
+		numBlocks++
+	}
+	f.logger.Debug("Data frame properties", "numBlocks", numBlocks, "blockSizeWithoutHeader", blockSizeWithoutHeader)
+
+	// Final data should be
+	// [dataLength..., data..., padding...]
+	payload := make([]byte, numBlocks*blockSizeWithoutHeader)
+	binary.BigEndian.PutUint16(payload[:2], uint16(len(data)))
+	copy(payload[2:], data)
+
+	for i := 0; i < numBlocks; i++ {
+		header := make([]byte, ADPU_BLOCK_HEADER_LENGTH)
+		binary.BigEndian.PutUint16(header[:2], f.channel)
+		header[2] = FRAME_HEADER_TAG
+		binary.BigEndian.PutUint16(header[3:5], uint16(i))
+
+		blocks = append(blocks, append(header, payload[i*blockSizeWithoutHeader:(i+1)*blockSizeWithoutHeader]...))
+	}
+
+	f.logger.Debug("Frames", "blockCount", len(blocks))
+
+	return blocks, nil
+}
+
+func (f *hidFramer) Reduce(res Response, expectedSequence uint16, block []byte) (Response, error) {
+	f.logger.Debug("Reducing a frame", "blockLength", len(block), "expectedSequence", expectedSequence, "resLength", res.Length)
+	if len(block) != int(f.packetSize) {
+		return res, fmt.Errorf("read data is not equal to expected packet size, expected %d, got %d, err: %w", f.packetSize, len(block), ErrIncompleteRead)
+	}
+	channel := binary.BigEndian.Uint16(block[:2])
+	tag := block[2]
+	sequence := binary.BigEndian.Uint16(block[3:5])
+	if channel != f.channel {
+		return res, fmt.Errorf("channel does not match, expected %d, got %d: %w", f.channel, channel, ErrBlockChannelNotMatch)
+	}
+	if tag != byte(FRAME_HEADER_TAG) {
+		return res, fmt.Errorf("tag does not match, expected %d, got %d: %w", FRAME_HEADER_TAG, tag, ErrBlockTagNotMatch)
+	}
+	if sequence != expectedSequence {
+		return res, fmt.Errorf("sequence does not match, expected %d, got %d: %w", expectedSequence, sequence, ErrBlockSequenceNotMatch)
+	}
+
+	// Read length for the first block
+	if expectedSequence == 0 {
+		res.Length = int(binary.BigEndian.Uint16(block[5:7]))
+		res.Data = append(res.Data, block[7:]...)
+	} else {
+		res.Data = append(res.Data, block[5:]...)
+	}
+
+	// Remove padding from res.Data at the last block
+	if len(res.Data) > res.Length {
+		res.Data = res.Data[:res.Length]
+	}
+
+	return res, nil
+}
+
+type streamFramer struct{}
+
+// Create framer for stream-based transports that frame messages by themselves
+// i.e. TCP transport of Speculos. ADPU messages are sent and received as a whole.
+func NewStreamFramer() Framer {
+	return &streamFramer{}
+}
+
+func (f *streamFramer) FrameSize() int {
+	return MAX_STREAM_RESPONSE_LENGTH
+}
+
+func (f *streamFramer) Frames(command []byte) ([][]byte, error) {
+	return [][]byte{command}, nil
+}
+
+func (f *streamFramer) Reduce(res Response, sequence uint16, frame []byte) (Response, error) {
+	if sequence != 0 {
+		return res, fmt.Errorf("response is expected in a single frame, got frame %d: %w", sequence, ErrBlockSequenceNotMatch)
+	}
+	if len(frame) < 2 {
+		return res, fmt.Errorf("response is too short to contain SW, got %d bytes: %w", len(frame), ErrIncompleteRead)
+	}
+
+	res.Length = len(frame)
+	res.Data = append(res.Data, frame...)
+
+	return res, nil
+}
+
+type bleFramer struct {
+	logger *slog.Logger
+	mtu    int
+}
+
+// Create framer of Ledger's BLE scheme, given MTU negotiated with the device
+//
+// Each frame is [tag (1 byte), sequence (2 bytes), payload...], with maximum size of MTU,
+// where payload of all frames combined is [length (2 bytes), ADPU message...].
+// Unlike HID, there is no channel field and the last frame is not padded.
+func NewBLEFramer(mtu int, logger *slog.Logger) Framer {
+	return &bleFramer{
+		logger: logger,
+		mtu:    mtu,
+	}
+}
+
+func (f *bleFramer) FrameSize() int {
+	return f.mtu
+}
+
+func (f *bleFramer) Frames(data []byte) ([][]byte, error) {
+	f.logger.Debug("Creating BLE data frames", "length", len(data), "mtu", f.mtu)
+	if len(data) > 0xFFFF {
+		return nil, fmt.Errorf("data is too long to be framed, expected <=%d, got %d: %w", 0xFFFF, len(data), ErrADPUPayloadTooLong)
+	}
+	// First frame needs space for 2-byte length
+	if f.mtu <= BLE_FRAME_HEADER_LENGTH+2 {
+		return nil, fmt.Errorf("MTU is too small for a frame, got %d: %w", f.mtu, ErrADPUPayloadTooLong)
+	}
+
+	payload := binary.BigEndian.AppendUint16(nil, uint16(len(data)))
+	payload = append(payload, data...)
+
+	var blocks [][]byte
+	blockSizeWithoutHeader := f.mtu - BLE_FRAME_HEADER_LENGTH
+	for i := 0; i*blockSizeWithoutHeader < len(payload); i++ {
+		end := min((i+1)*blockSizeWithoutHeader, len(payload))
+		block := make([]byte, BLE_FRAME_HEADER_LENGTH, BLE_FRAME_HEADER_LENGTH+end-i*blockSizeWithoutHeader)
+		block[0] = FRAME_HEADER_TAG
+		binary.BigEndian.PutUint16(block[1:3], uint16(i))
+
+		blocks = append(blocks, append(block, payload[i*blockSizeWithoutHeader:end]...))
+	}
+
+	f.logger.Debug("Frames", "blockCount", len(blocks))
+
+	return blocks, nil
+}
+
+func (f *bleFramer) Reduce(res Response, expectedSequence uint16, block []byte) (Response, error) {
+	f.logger.Debug("Reducing a BLE frame", "blockLength", len(block), "expectedSequence", expectedSequence, "resLength", res.Length)
+	headerLength := BLE_FRAME_HEADER_LENGTH
+	if expectedSequence == 0 {
+		headerLength += 2
+	}
+	if len(block) < headerLength {
+		return res, fmt.Errorf("frame is too short, expected >=%d, got %d: %w", headerLength, len(block), ErrIncompleteRead)
+	}
+	tag := block[0]
+	sequence := binary.BigEndian.Uint16(block[1:3])
+	if tag != FRAME_HEADER_TAG {
+		return res, fmt.Errorf("tag does not match, expected %d, got %d: %w", FRAME_HEADER_TAG, tag, ErrBlockTagNotMatch)
+	}
+	if sequence != expectedSequence {
+		return res, fmt.Errorf("sequence does not match, expected %d, got %d: %w", expectedSequence, sequence, ErrBlockSequenceNotMatch)
+	}
+
+	// Read length for the first block
+	if expectedSequence == 0 {
+		res.Length = int(binary.BigEndian.Uint16(block[3:5]))
+	}
+	res.Data = append(res.Data, block[headerLength:]...)
+
+	if len(res.Data) > res.Length {
+		res.Data = res.Data[:res.Length]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./adpu/framer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./adpu/framer.go -destination=./adpu/framer_mock.go -package=adpu
+//
+
+// Package adpu is a generated GoMock package.
+package adpu
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFramer is a mock of Framer interface.
+type MockFramer struct {
+	ctrl     *gomock.Controller
+	recorder *MockFramerMockRecorder
+}
+
+// MockFramerMockRecorder is the mock recorder for MockFramer.
+type MockFramerMockRecorder struct {
+	mock *MockFramer
+}
+
+// NewMockFramer creates a new mock instance.
+func NewMockFramer(ctrl *gomock.Controller) *MockFramer {
+	mock := &MockFramer{ctrl: ctrl}
+	mock.recorder = &MockFramerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFramer) EXPECT() *MockFramerMockRecorder {
+	return m.recorder
+}
+
+// FrameSize mocks base method.
+func (m *MockFramer) FrameSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FrameSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// FrameSize indicates an expected call of FrameSize.
+func (mr *MockFramerMockRecorder) FrameSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FrameSize", reflect.TypeOf((*MockFramer)(nil).FrameSize))
+}
+
+// Frames mocks base method.
+func (m *MockFramer) Frames(command []byte) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Frames", command)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Frames indicates an expected call of Frames.
+func (mr *MockFramerMockRecorder) Frames(command any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Frames", reflect.TypeOf((*MockFramer)(nil).Frames), command)
+}
+
+// Reduce mocks base method.
+func (m *MockFramer) Reduce(res Response, sequence uint16, frame []byte) (Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reduce", res, sequence, frame)
+	ret0, _ := ret[0].(Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reduce indicates an expected call of Reduce.
+func (mr *MockFramerMockRecorder) Reduce(res, sequence, frame any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reduce", reflect.TypeOf((*MockFramer)(nil).Reduce), res, sequence, frame)
+}
@@ -0,0 +1,219 @@
+package adpu_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHIDFramer_Frames(t *testing.T) {
+	tests := []struct {
+		name       string
+		channel    uint16
+		packetSize uint16
+		data       []byte
+		res        [][]byte
+		err        error
+	}{
+		{
+			name:       "Success_SmallPacketSize",
+			channel:    0x0101,
+			packetSize: 8,
+			data:       []byte{0xA1, 0xA2, 0xA3, 0xA4},
+			res: [][]byte{
+				{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 0x04, 0xA1},
+				{0x01, 0x01, 0x05, 0x00, 0x01, 0xA2, 0xA3, 0xA4},
+			},
+			err: nil,
+		},
+		{
+			name:       "Success_EmptyData",
+			channel:    0x0101,
+			packetSize: 8,
+			data:       []byte{},
+			res: [][]byte{
+				{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00},
+			},
+			err: nil,
+		},
+		{
+			name:       "Error_DataTooLong",
+			channel:    0x0101,
+			packetSize: 64,
+			data:       make([]byte, 0x10000),
+			res:        nil,
+			err:        adpu.ErrADPUPayloadTooLong,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			framer := adpu.NewHIDFramer(test.channel, test.packetSize, slog.Default())
+			res, err := framer.Frames(test.data)
+
+			assert.Equal(t, test.res, res)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestStreamFramer_Reduce(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence uint16
+		frame    []byte
+		res      adpu.Response
+		err      error
+	}{
+		{
+			name:     "Success",
+			sequence: 0,
+			frame:    []byte{0xB1, 0x90, 0x00},
+			res: adpu.Response{
+				Length: 3,
+				Data:   []byte{0xB1, 0x90, 0x00},
+			},
+			err: nil,
+		},
+		{
+			name:     "Error_UnexpectedSequence",
+			sequence: 1,
+			frame:    []byte{0xB1, 0x90, 0x00},
+			res:      adpu.Response{},
+			err:      adpu.ErrBlockSequenceNotMatch,
+		},
+		{
+			name:     "Error_NoSW",
+			sequence: 0,
+			frame:    []byte{0x90},
+			res:      adpu.Response{},
+			err:      adpu.ErrIncompleteRead,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			framer := adpu.NewStreamFramer()
+			res, err := framer.Reduce(adpu.Response{}, test.sequence, test.frame)
+
+			assert.Equal(t, test.res, res)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestBLEFramer_Frames(t *testing.T) {
+	tests := []struct {
+		name string
+		mtu  int
+		data []byte
+		res  [][]byte
+		err  error
+	}{
+		{
+			name: "Success_MultipleFrames",
+			mtu:  8,
+			data: []byte{0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7},
+			res: [][]byte{
+				{0x05, 0x00, 0x00, 0x00, 0x07, 0xA1, 0xA2, 0xA3},
+				{0x05, 0x00, 0x01, 0xA4, 0xA5, 0xA6, 0xA7},
+			},
+			err: nil,
+		},
+		{
+			name: "Success_ExactlyOneFrame",
+			mtu:  8,
+			data: []byte{0xA1, 0xA2, 0xA3},
+			res: [][]byte{
+				{0x05, 0x00, 0x00, 0x00, 0x03, 0xA1, 0xA2, 0xA3},
+			},
+			err: nil,
+		},
+		{
+			name: "Error_MTUTooSmall",
+			mtu:  5,
+			data: []byte{0xA1},
+			res:  nil,
+			err:  adpu.ErrADPUPayloadTooLong,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			framer := adpu.NewBLEFramer(test.mtu, slog.Default())
+			res, err := framer.Frames(test.data)
+
+			assert.Equal(t, test.res, res)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestBLEFramer_Reduce(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames [][]byte
+		res    adpu.Response
+		err    error
+	}{
+		{
+			name: "Success",
+			frames: [][]byte{
+				{0x05, 0x00, 0x00, 0x00, 0x04, 0xB1, 0xB2, 0xB3},
+				{0x05, 0x00, 0x01, 0xB4},
+			},
+			res: adpu.Response{
+				Length: 4,
+				Data:   []byte{0xB1, 0xB2, 0xB3, 0xB4},
+			},
+			err: nil,
+		},
+		{
+			name: "Error_TagNotMatch",
+			frames: [][]byte{
+				{0x08, 0x00, 0x00, 0x00, 0x04, 0xB1, 0xB2, 0xB3},
+			},
+			res: adpu.Response{},
+			err: adpu.ErrBlockTagNotMatch,
+		},
+		{
+			name: "Error_SequenceNotMatch",
+			frames: [][]byte{
+				{0x05, 0x00, 0x00, 0x00, 0x04, 0xB1, 0xB2, 0xB3},
+				{0x05, 0x00, 0x02, 0xB4},
+			},
+			res: adpu.Response{
+				Length: 4,
+				Data:   []byte{0xB1, 0xB2, 0xB3},
+			},
+			err: adpu.ErrBlockSequenceNotMatch,
+		},
+		{
+			name: "Error_FrameTooShort",
+			frames: [][]byte{
+				{0x05, 0x00, 0x00, 0x00},
+			},
+			res: adpu.Response{},
+			err: adpu.ErrIncompleteRead,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			framer := adpu.NewBLEFramer(8, slog.Default())
+			var res adpu.Response
+			var err error
+			for i, frame := range test.frames {
+				res, err = framer.Reduce(res, uint16(i), frame)
+				if err != nil {
+					break
+				}
+			}
+
+			assert.Equal(t, test.res, res)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package adpu
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// Interceptors should wrap this error when rejecting a command, so callers can tell it from device errors
+	ErrCommandVetoed = errors.New("command is vetoed")
+)
+
+// ADPU command seen by interceptors
+type Call struct {
+	Header CommandHeader
+	Data   []byte
+}
+
+// ADPU response seen by interceptors
+type Result struct {
+	Data []byte
+	SW   uint16
+	// Duration of exchanging the command with device, including waiting for the previous exchange to finish
+	Latency time.Duration
+}
+
+// Send the intercepted command to the next interceptor, or to device
+type Handler func(ctx context.Context) (Result, error)
+
+// Interceptor observes every command sent via `Protocol.Exchange` and `Protocol.Send`, and its response.
+// It calls `next` to proceed, or returns an error wrapping `ErrCommandVetoed` without calling `next`
+// to prevent the command from reaching device. Call must not be modified.
+type Interceptor func(ctx context.Context, call Call, next Handler) (Result, error)
+
+// Add interceptors around every exchange. The first interceptor is the outermost one,
+// so it sees the command first and the response last.
+func WithInterceptors(interceptors ...Interceptor) ProtocolOption {
+	return func(p *protocolImpl) {
+		p.interceptors = append(p.interceptors, interceptors...)
+	}
+}
+
+// Exchange encoded command with device through interceptors
+func (a *protocolImpl) intercept(ctx context.Context, call Call, command []byte) (Result, error) {
+	handler := func(ctx context.Context) (Result, error) {
+		start := time.Now()
+		res, err := a.transceive(ctx, command)
+		latency := time.Since(start)
+		if err != nil {
+			return Result{Latency: latency}, err
+		}
+		data, sw, err := splitSW(res)
+		if err != nil {
+			return Result{Latency: latency}, err
+		}
+
+		return Result{Data: data, SW: sw, Latency: latency}, nil
+	}
+
+	for i := len(a.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := a.interceptors[i], handler
+		handler = func(ctx context.Context) (Result, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return handler(ctx)
+}
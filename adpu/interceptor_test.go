@@ -0,0 +1,203 @@
+package adpu_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDecodeCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []byte
+		header  adpu.CommandHeader
+		data    []byte
+		err     error
+	}{
+		{
+			name:    "Success_HeaderOnly",
+			command: []byte{0xe0, 0x06, 0x01, 0x02},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+		},
+		{
+			name:    "Success_Short",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x02, 0xA1, 0xA2},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+			data:    []byte{0xA1, 0xA2},
+		},
+		{
+			name:    "Success_ShortEmptyData",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x00},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+			data:    []byte{},
+		},
+		{
+			name:    "Success_ShortLe",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x10},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+		},
+		{
+			name:    "Success_ShortDataLe",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x02, 0xA1, 0xA2, 0x00},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+			data:    []byte{0xA1, 0xA2},
+		},
+		{
+			name:    "Success_ExtendedWithoutData",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x00, 0x00, 0x00},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+		},
+		{
+			name:    "Success_Extended",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x00, 0x00, 0x02, 0xA1, 0xA2, 0x00, 0x00},
+			header:  adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02},
+			data:    []byte{0xA1, 0xA2},
+		},
+		{
+			name:    "Error_TooShort",
+			command: []byte{0xe0, 0x06, 0x01},
+			err:     adpu.ErrMalformedCommand,
+		},
+		{
+			name:    "Error_LengthNotMatch",
+			command: []byte{0xe0, 0x06, 0x01, 0x02, 0x03, 0xA1},
+			err:     adpu.ErrMalformedCommand,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header, data, err := adpu.DecodeCommand(test.command)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.header, header)
+			assert.Equal(t, test.data, data)
+		})
+	}
+}
+
+func TestProtocol_Interceptors(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	mock.EXPECT().Write(ctx, []byte{0xe0, 0x06, 0x01, 0x02, 0x01, 0xA1}).Return(6, nil).Times(2)
+	mock.EXPECT().Read(ctx, gomock.Any()).DoAndReturn(readFrame([]byte{0xB1, 0x90, 0x00})).Times(2)
+
+	var trace []string
+	var calls []adpu.Call
+	var results []adpu.Result
+	observe := func(name string) adpu.Interceptor {
+		return func(ctx context.Context, call adpu.Call, next adpu.Handler) (adpu.Result, error) {
+			trace = append(trace, name+" before")
+			result, err := next(ctx)
+			trace = append(trace, name+" after")
+			calls = append(calls, call)
+			results = append(results, result)
+			return result, err
+		}
+	}
+	proto := adpu.NewProtocol(mock, 0, slog.Default(),
+		adpu.WithFramer(adpu.NewStreamFramer()),
+		adpu.WithInterceptors(observe("outer")),
+		adpu.WithInterceptors(observe("inner")),
+	)
+
+	res, sw, err := proto.Send(ctx, 0xe0, 0x06, 0x01, 0x02, []byte{0xA1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xB1}, res)
+	assert.Equal(t, adpu.SW_OK, sw)
+
+	raw, err := proto.Exchange(ctx, []byte{0xe0, 0x06, 0x01, 0x02, 0x01, 0xA1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xB1, 0x90, 0x00}, raw)
+
+	assert.Equal(t, []string{"outer before", "inner before", "inner after", "outer after", "outer before", "inner before", "inner after", "outer after"}, trace)
+	for i := range calls {
+		assert.Equal(t, adpu.Call{Header: adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02}, Data: []byte{0xA1}}, calls[i])
+		assert.Equal(t, []byte{0xB1}, results[i].Data)
+		assert.Equal(t, adpu.SW_OK, results[i].SW)
+		assert.Positive(t, results[i].Latency)
+	}
+}
+
+func TestProtocol_Interceptors_ExchangeWithLe(t *testing.T) {
+	ctx := context.Background()
+	command := []byte{0xe0, 0x06, 0x01, 0x02, 0x01, 0xA1, 0x00}
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	mock.EXPECT().Write(ctx, command).Return(len(command), nil)
+	mock.EXPECT().Read(ctx, gomock.Any()).DoAndReturn(readFrame([]byte{0xB1, 0x90, 0x00}))
+
+	var calls []adpu.Call
+	observe := func(ctx context.Context, call adpu.Call, next adpu.Handler) (adpu.Result, error) {
+		calls = append(calls, call)
+		return next(ctx)
+	}
+	proto := adpu.NewProtocol(mock, 0, slog.Default(), adpu.WithFramer(adpu.NewStreamFramer()), adpu.WithInterceptors(observe))
+
+	// Command is sent as-is, including Le
+	raw, err := proto.Exchange(ctx, command)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xB1, 0x90, 0x00}, raw)
+	assert.Equal(t, []adpu.Call{{Header: adpu.CommandHeader{CLA: 0xe0, INS: 0x06, P1: 0x01, P2: 0x02}, Data: []byte{0xA1}}}, calls)
+}
+
+func TestProtocol_Interceptors_Veto(t *testing.T) {
+	ctx := context.Background()
+	// Only GET_APP_CONFIGURATION is allowed
+	allowList := func(ctx context.Context, call adpu.Call, next adpu.Handler) (adpu.Result, error) {
+		if call.Header.INS != 0x06 {
+			return adpu.Result{}, fmt.Errorf("INS 0x%02x is not allowed: %w", call.Header.INS, adpu.ErrCommandVetoed)
+		}
+		return next(ctx)
+	}
+
+	tests := []struct {
+		name    string
+		command []byte
+		err     error
+	}{
+		{
+			name:    "Error_Vetoed",
+			command: []byte{0xe0, 0x04, 0x00, 0x00, 0x01, 0xA1},
+			err:     adpu.ErrCommandVetoed,
+		},
+		{
+			name:    "Error_MalformedCommand",
+			command: []byte{0xe0, 0x06, 0x00, 0x00, 0x05, 0xA1},
+			err:     adpu.ErrMalformedCommand,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			// Device is not touched
+			mock := device.NewMockDevice(ctrl)
+			proto := adpu.NewProtocol(mock, 0, slog.Default(), adpu.WithFramer(adpu.NewStreamFramer()), adpu.WithInterceptors(allowList))
+
+			_, err := proto.Exchange(ctx, test.command)
+
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+
+	t.Run("Error_VetoedSend", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mock := device.NewMockDevice(ctrl)
+		proto := adpu.NewProtocol(mock, 0, slog.Default(), adpu.WithInterceptors(allowList))
+
+		res, sw, err := proto.Send(ctx, 0xe0, 0x04, 0x00, 0x00, []byte{0xA1})
+
+		assert.Nil(t, res)
+		assert.Equal(t, uint16(0), sw)
+		assert.ErrorIs(t, err, adpu.ErrCommandVetoed)
+	})
+}
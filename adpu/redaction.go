@@ -0,0 +1,147 @@
+package adpu
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ntchjb/ledger-go/log"
+)
+
+// What to hide from debug logs for commands of an INS
+type RedactionRule struct {
+	// Mask command data. CLA, INS, P1 and P2 are still logged
+	MaskCommand bool
+	// Mask response data. SW is still logged
+	MaskResponse bool
+}
+
+// Redaction policy of debug logs written by protocol, which includes commands, responses, frames, and error messages.
+// When a policy is set, frames of masked commands or responses are logged by length only,
+// and frames drained after an aborted exchange are never logged, as their command is unknown.
+type RedactionPolicy struct {
+	// Rules by INS of command
+	Rules map[uint8]RedactionRule
+	// Maximum number of bytes of a payload written to logs, longer payload is truncated.
+	// Zero means no truncation
+	MaxPayloadLength int
+}
+
+// Redact debug logs and error messages of protocol by given policy.
+// By default, everything is logged in hex.
+func WithRedaction(policy RedactionPolicy) ProtocolOption {
+	return func(p *protocolImpl) {
+		p.redaction = &policy
+	}
+}
+
+// Bytes written to logs, where data may be masked or truncated, and prefix and suffix are always visible
+type redactedBytes struct {
+	prefix    []byte
+	data      []byte
+	suffix    []byte
+	masked    bool
+	maxLength int
+}
+
+func (r redactedBytes) String() string {
+	var sb strings.Builder
+	sb.WriteString(hex.EncodeToString(r.prefix))
+	switch {
+	case r.masked:
+		fmt.Fprintf(&sb, "<redacted %d bytes>", len(r.data))
+	case r.maxLength > 0 && len(r.data) > r.maxLength:
+		sb.WriteString(hex.EncodeToString(r.data[:r.maxLength]))
+		fmt.Fprintf(&sb, "<truncated %d bytes>", len(r.data)-r.maxLength)
+	default:
+		sb.WriteString(hex.EncodeToString(r.data))
+	}
+	sb.WriteString(hex.EncodeToString(r.suffix))
+
+	return sb.String()
+}
+
+func (r redactedBytes) LogValue() slog.Value {
+	return slog.StringValue(r.String())
+}
+
+func (p *RedactionPolicy) rule(command []byte) RedactionRule {
+	if len(command) < 2 {
+		return RedactionRule{}
+	}
+
+	return p.Rules[command[1]]
+}
+
+func (p *RedactionPolicy) bytes(data []byte, masked bool) redactedBytes {
+	return redactedBytes{data: data, masked: masked, maxLength: p.MaxPayloadLength}
+}
+
+// Command data of the INS as written to logs by the policy, for payloads logged outside of protocol i.e. by apps
+func (p *RedactionPolicy) CommandData(ins uint8, data []byte) slog.LogValuer {
+	return p.bytes(data, p.Rules[ins].MaskCommand)
+}
+
+// Whole command, where the first 4 bytes are CLA, INS, P1, and P2
+func (p *RedactionPolicy) command(command []byte) redactedBytes {
+	if len(command) < 4 {
+		return p.bytes(command, false)
+	}
+	res := p.bytes(command[4:], p.rule(command).MaskCommand)
+	res.prefix = command[:4]
+
+	return res
+}
+
+// Whole response of the command, where the last 2 bytes are SW
+func (p *RedactionPolicy) response(command []byte, response []byte) redactedBytes {
+	if len(response) < 2 {
+		return p.bytes(response, p.rule(command).MaskResponse)
+	}
+	res := p.bytes(response[:len(response)-2], p.rule(command).MaskResponse)
+	res.suffix = response[len(response)-2:]
+
+	return res
+}
+
+// Command, as logged or included in error messages
+func (a *protocolImpl) commandValue(command []byte) fmt.Stringer {
+	if a.redaction == nil {
+		return log.HexDisplay(command)
+	}
+
+	return a.redaction.command(command)
+}
+
+// Command data, without header, as logged by `Send`
+func (a *protocolImpl) dataValue(ins uint8, data []byte) any {
+	if a.redaction == nil {
+		return log.HexDisplay(data)
+	}
+
+	return a.redaction.bytes(data, a.redaction.Rules[ins].MaskCommand)
+}
+
+func (a *protocolImpl) responseValue(command []byte, response []byte) any {
+	if a.redaction == nil {
+		return log.HexDisplay(response)
+	}
+
+	return a.redaction.response(command, response)
+}
+
+// Frame of the command, or of its response. Command is nil for frames drained after an aborted exchange,
+// which are always masked
+func (a *protocolImpl) frameValue(command []byte, frame []byte, isResponse bool) any {
+	if a.redaction == nil {
+		return log.HexDisplay(frame)
+	}
+	rule := a.redaction.rule(command)
+	masked := command == nil || rule.MaskCommand
+	if isResponse {
+		masked = command == nil || rule.MaskResponse
+	}
+
+	return a.redaction.bytes(frame, masked)
+}
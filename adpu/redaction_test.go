@@ -0,0 +1,122 @@
+package adpu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProtocol_WithRedaction(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5e}, 32)
+	commandData := bytes.Repeat([]byte{0xaa}, 70)
+	secretHex := strings.Repeat("5e", 8)
+	commandDataHex := strings.Repeat("aa", 8)
+
+	tests := []struct {
+		name        string
+		opts        []adpu.ProtocolOption
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "NoRedaction",
+			contains: []string{secretHex, commandDataHex},
+		},
+		{
+			name: "MaskResponse",
+			opts: []adpu.ProtocolOption{adpu.WithRedaction(adpu.RedactionPolicy{
+				Rules: map[uint8]adpu.RedactionRule{0x18: {MaskResponse: true}},
+			})},
+			contains:    []string{"<redacted 32 bytes>9000", commandDataHex},
+			notContains: []string{secretHex},
+		},
+		{
+			name: "MaskCommand",
+			opts: []adpu.ProtocolOption{adpu.WithRedaction(adpu.RedactionPolicy{
+				Rules: map[uint8]adpu.RedactionRule{0x18: {MaskCommand: true}},
+			})},
+			contains:    []string{"e0180001<redacted 71 bytes>", "data=\"<redacted 70 bytes>\"", secretHex},
+			notContains: []string{commandDataHex},
+		},
+		{
+			name: "OtherINS",
+			opts: []adpu.ProtocolOption{adpu.WithRedaction(adpu.RedactionPolicy{
+				Rules: map[uint8]adpu.RedactionRule{0x04: {MaskCommand: true, MaskResponse: true}},
+			})},
+			contains: []string{secretHex, commandDataHex},
+		},
+		{
+			name: "Truncate",
+			opts: []adpu.ProtocolOption{adpu.WithRedaction(adpu.RedactionPolicy{
+				MaxPayloadLength: 4,
+			})},
+			contains:    []string{"5e5e5e5e<truncated 28 bytes>9000", "aaaaaaaa<truncated 66 bytes>"},
+			notContains: []string{secretHex, commandDataHex},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			ctrl := gomock.NewController(t)
+			mock := device.NewMockDevice(ctrl)
+			mock.EXPECT().Write(gomock.Any(), gomock.Any()).Return(64, nil).Times(2)
+			mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, append(append([]byte{0x00, 0x22}, secret...), 0x90, 0x00)...)))
+			proto := adpu.NewProtocol(mock, 0x0101, logger, test.opts...)
+
+			res, sw, err := proto.Send(context.Background(), 0xe0, 0x18, 0x00, 0x01, commandData)
+
+			assert.NoError(t, err)
+			assert.Equal(t, adpu.SW_OK, sw)
+			assert.Equal(t, secret, res)
+			for _, s := range test.contains {
+				assert.Contains(t, logs.String(), s)
+			}
+			for _, s := range test.notContains {
+				assert.NotContains(t, logs.String(), s)
+			}
+		})
+	}
+}
+
+func TestProtocol_WithRedaction_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	mock.EXPECT().Write(gomock.Any(), gomock.Any()).Return(0, errors.New("device is gone"))
+	proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithRedaction(adpu.RedactionPolicy{
+		Rules: map[uint8]adpu.RedactionRule{0x18: {MaskCommand: true}},
+	}))
+
+	_, _, err := proto.Send(context.Background(), 0xe0, 0x18, 0x00, 0x01, []byte{0xaa, 0xbb})
+
+	assert.ErrorContains(t, err, "command: e0180001<redacted 3 bytes>")
+	assert.NotContains(t, err.Error(), "aabb")
+}
+
+func TestProtocol_WithRedaction_PartialResponseError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := device.NewMockDevice(ctrl)
+	gomock.InOrder(
+		mock.EXPECT().Write(gomock.Any(), gomock.Any()).Return(64, nil),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).DoAndReturn(readFrame(hidFrame(0x00, append([]byte{0x00, 0x43}, bytes.Repeat([]byte{0x5e}, 57)...)...))),
+		mock.EXPECT().Read(gomock.Any(), gomock.Any()).Return(0, errors.New("device is gone")),
+	)
+	proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithRedaction(adpu.RedactionPolicy{
+		Rules: map[uint8]adpu.RedactionRule{0x18: {MaskResponse: true}},
+	}))
+
+	_, _, err := proto.Send(context.Background(), 0xe0, 0x18, 0x00, 0x01, nil)
+
+	assert.ErrorContains(t, err, "received 57 of 67 bytes")
+	assert.NotContains(t, err.Error(), "5e")
+	assert.NotContains(t, err.Error(), "94")
+}
@@ -0,0 +1,122 @@
+package adpu
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// User rejected the request on device
+	ErrUserRefused = errors.New("user refused on device")
+	// Device is locked by PIN
+	ErrLockedDevice = errors.New("device is locked")
+	// The app which supports the command is not opened i.e. device is at dashboard, or another app is opened
+	ErrAppNotOpen = errors.New("app is not open")
+	// Device is not set up yet
+	ErrDeviceNotOnboarded = errors.New("device is not onboarded")
+	// Wrong PIN, with remaining attempts in the status word
+	ErrPINRemainingAttempts = errors.New("wrong PIN")
+	// Wrong Le, with the correct Le in the status word
+	ErrWrongLe = errors.New("wrong Le")
+)
+
+// Sentinel errors matched by status words, for errors.Is
+var swSentinels = map[uint16]error{
+	SW_USER_REFUSED_ON_DEVICE:          ErrUserRefused,
+	SW_CONDITIONS_OF_USE_NOT_SATISFIED: ErrUserRefused,
+	SW_LOCKED_DEVICE:                   ErrLockedDevice,
+	SW_CLA_NOT_SUPPORTED:               ErrAppNotOpen,
+	SW_CLA_NOT_SUPPORTED_2:             ErrAppNotOpen,
+	SW_INS_NOT_SUPPORTED:               ErrAppNotOpen,
+	SW_UNKNOWN_APDU:                    ErrAppNotOpen,
+	SW_DEVICE_NOT_ONBOARDED:            ErrDeviceNotOnboarded,
+	SW_DEVICE_NOT_ONBOARDED_2:          ErrDeviceNotOnboarded,
+}
+
+// Header of ADPU command
+type CommandHeader struct {
+	CLA uint8
+	INS uint8
+	P1  uint8
+	P2  uint8
+}
+
+func (h CommandHeader) String() string {
+	return fmt.Sprintf("CLA: 0x%02x, INS: 0x%02x, P1: 0x%02x, P2: 0x%02x", h.CLA, h.INS, h.P1, h.P2)
+}
+
+// StatusError is returned when device responds with status word other than SW_OK
+//
+// It matches `ErrSWNotOK`, and sentinel errors of the status word i.e. `ErrUserRefused`, by errors.Is
+type StatusError struct {
+	// Status word returned by device
+	SW uint16
+	// Command that the device responded to
+	Command CommandHeader
+}
+
+func NewStatusError(sw uint16, cla, ins, p1, p2 uint8) *StatusError {
+	return &StatusError{
+		SW: sw,
+		Command: CommandHeader{
+			CLA: cla,
+			INS: ins,
+			P1:  p1,
+			P2:  p2,
+		},
+	}
+}
+
+// Remaining PIN attempts, for status word 0x63Cx
+func (e *StatusError) RemainingPINAttempts() (int, bool) {
+	if e.SW&0xFFF0 != SW_PIN_REMAINING_ATTEMPTS {
+		return 0, false
+	}
+
+	return int(e.SW & 0x000F), true
+}
+
+// Correct Le that should be used, for status word 0x6Cxx
+func (e *StatusError) ExpectedLe() (int, bool) {
+	if e.SW&0xFF00 != SW_WRONG_LE {
+		return 0, false
+	}
+
+	return int(e.SW & 0x00FF), true
+}
+
+// Name of the status word, including value of range status words i.e. 0x63Cx
+func (e *StatusError) Message() string {
+	if attempts, ok := e.RemainingPINAttempts(); ok {
+		return fmt.Sprintf("%s (%d)", SWMessage[SW_PIN_REMAINING_ATTEMPTS], attempts)
+	}
+	if le, ok := e.ExpectedLe(); ok {
+		return fmt.Sprintf("%s (%d)", SWMessage[SW_WRONG_LE], le)
+	}
+	if message, ok := SWMessage[e.SW]; ok {
+		return message
+	}
+
+	return "SW_UNKNOWN"
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("SW 0x%04x %s, command: %s", e.SW, e.Message(), e.Command)
+}
+
+func (e *StatusError) Is(target error) bool {
+	if target == ErrSWNotOK {
+		return true
+	}
+	if _, ok := e.RemainingPINAttempts(); ok {
+		return target == ErrPINRemainingAttempts
+	}
+	if _, ok := e.ExpectedLe(); ok {
+		return target == ErrWrongLe
+	}
+	if sentinel, ok := swSentinels[e.SW]; ok {
+		return target == sentinel
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package adpu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name     string
+		sw       uint16
+		message  string
+		is       []error
+		isNot    []error
+		attempts int
+		le       int
+	}{
+		{
+			name:    "UserRefused",
+			sw:      0x5501,
+			message: "SW 0x5501 SW_USER_REFUSED_ON_DEVICE, command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:      []error{adpu.ErrSWNotOK, adpu.ErrUserRefused},
+			isNot:   []error{adpu.ErrLockedDevice, adpu.ErrAppNotOpen},
+		},
+		{
+			name:    "ConditionsNotSatisfied_UserRefused",
+			sw:      0x6985,
+			message: "SW 0x6985 SW_CONDITIONS_OF_USE_NOT_SATISFIED, command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:      []error{adpu.ErrSWNotOK, adpu.ErrUserRefused},
+		},
+		{
+			name:    "LockedDevice",
+			sw:      0x5515,
+			message: "SW 0x5515 SW_LOCKED_DEVICE, command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:      []error{adpu.ErrSWNotOK, adpu.ErrLockedDevice},
+			isNot:   []error{adpu.ErrUserRefused},
+		},
+		{
+			name:    "AppNotOpen",
+			sw:      0x6e01,
+			message: "SW 0x6e01 SW_CLA_NOT_SUPPORTED_2, command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:      []error{adpu.ErrSWNotOK, adpu.ErrAppNotOpen},
+		},
+		{
+			name:     "PINRemainingAttempts",
+			sw:       0x63c2,
+			message:  "SW 0x63c2 SW_PIN_REMAINING_ATTEMPTS (2), command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:       []error{adpu.ErrSWNotOK, adpu.ErrPINRemainingAttempts},
+			isNot:    []error{adpu.ErrWrongLe},
+			attempts: 2,
+		},
+		{
+			name:    "WrongLe",
+			sw:      0x6c20,
+			message: "SW 0x6c20 SW_WRONG_LE (32), command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:      []error{adpu.ErrSWNotOK, adpu.ErrWrongLe},
+			isNot:   []error{adpu.ErrPINRemainingAttempts},
+			le:      32,
+		},
+		{
+			name:    "UnknownStatusWord",
+			sw:      0x1234,
+			message: "SW 0x1234 SW_UNKNOWN, command: CLA: 0xe0, INS: 0x04, P1: 0x00, P2: 0x00",
+			is:      []error{adpu.ErrSWNotOK},
+			isNot:   []error{adpu.ErrUserRefused, adpu.ErrLockedDevice, adpu.ErrAppNotOpen},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := fmt.Errorf("wrapped: %w", adpu.NewStatusError(test.sw, 0xe0, 0x04, 0x00, 0x00))
+
+			var statusErr *adpu.StatusError
+			assert.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, test.sw, statusErr.SW)
+			assert.Equal(t, test.message, statusErr.Error())
+			for _, target := range test.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, target := range test.isNot {
+				assert.NotErrorIs(t, err, target)
+			}
+
+			attempts, ok := statusErr.RemainingPINAttempts()
+			assert.Equal(t, test.attempts, attempts)
+			assert.Equal(t, test.attempts > 0, ok)
+			le, ok := statusErr.ExpectedLe()
+			assert.Equal(t, test.le, le)
+			assert.Equal(t, test.le > 0, ok)
+		})
+	}
+}
+
+func TestSend_StatusError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	proto := adpu.NewMockProtocol(ctrl)
+	proto.EXPECT().Send(ctx, uint8(0xe0), uint8(0x04), uint8(0x80), uint8(0x00), gomock.Any()).Return(nil, adpu.SW_USER_REFUSED_ON_DEVICE, nil)
+
+	err := adpu.Send(ctx, proto, 0xe0, 0x04, 0x80, 0x00, &adpu.EmptyData{}, &adpu.EmptyData{})
+
+	var statusErr *adpu.StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, adpu.SW_USER_REFUSED_ON_DEVICE, statusErr.SW)
+	assert.Equal(t, adpu.CommandHeader{CLA: 0xe0, INS: 0x04, P1: 0x80, P2: 0x00}, statusErr.Command)
+	assert.ErrorIs(t, err, adpu.ErrUserRefused)
+}
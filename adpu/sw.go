@@ -53,6 +53,9 @@ const (
 	SW_INVALID_CHUNK_LENGTH                uint16 = 0x6734
 	SW_INVALID_BACKUP_HEADER               uint16 = 0x684a
 	SW_TRUSTCHAIN_WRONG_SEED               uint16 = 0xb007
+	SW_CLA_NOT_SUPPORTED_2                 uint16 = 0x6e01
+	// Wrong Le, where the lowest byte is the correct Le
+	SW_WRONG_LE uint16 = 0x6c00
 )
 
 var (
@@ -109,5 +112,7 @@ var (
 		0x6734: "SW_INVALID_CHUNK_LENGTH",
 		0x684a: "SW_INVALID_BACKUP_HEADER",
 		0xb007: "SW_TRUSTCHAIN_WRONG_SEED",
+		0x6e01: "SW_CLA_NOT_SUPPORTED_2",
+		0x6c00: "SW_WRONG_LE",
 	}
 )
@@ -0,0 +1,129 @@
+package adpu
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Attribute keys of ADPU exchange spans
+const (
+	ATTR_CLA   = "adpu.cla"
+	ATTR_INS   = "adpu.ins"
+	ATTR_P1    = "adpu.p1"
+	ATTR_P2    = "adpu.p2"
+	ATTR_CHUNK = "adpu.chunk"
+	ATTR_SW    = "adpu.sw"
+	// Duration of the whole exchange, including waiting for the previous exchange to finish
+	ATTR_DURATION = "adpu.duration"
+	// Duration of writing command frames
+	ATTR_WRITE_DURATION = "adpu.write_duration"
+	// Duration from the last command frame to the first response frame, which includes waiting for user approval
+	ATTR_WAIT_DURATION = "adpu.wait_duration"
+	// Duration of reading the remaining response frames
+	ATTR_READ_DURATION = "adpu.read_duration"
+)
+
+// Tracer starts spans of operations, i.e. an adapter of OpenTelemetry tracer
+type Tracer interface {
+	// Start a span as a child of the span in ctx, if any, and return context carrying the new span
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	// Whether attributes are recorded. Attributes are not built if it's false
+	IsRecording() bool
+	SetAttributes(attrs ...slog.Attr)
+	// End the span, marking it as failed if err is not nil
+	End(err error)
+}
+
+type Counter uint8
+
+const (
+	COUNTER_FRAMES_SENT Counter = iota
+	COUNTER_FRAMES_RECEIVED
+	COUNTER_BYTES_SENT
+	COUNTER_BYTES_RECEIVED
+	// Commands refused by user on device
+	COUNTER_USER_REJECTIONS
+)
+
+func (c Counter) String() string {
+	switch c {
+	case COUNTER_FRAMES_SENT:
+		return "adpu.frames.sent"
+	case COUNTER_FRAMES_RECEIVED:
+		return "adpu.frames.received"
+	case COUNTER_BYTES_SENT:
+		return "adpu.bytes.sent"
+	case COUNTER_BYTES_RECEIVED:
+		return "adpu.bytes.received"
+	case COUNTER_USER_REJECTIONS:
+		return "adpu.user_rejections"
+	default:
+		return "unknown"
+	}
+}
+
+// Metrics collects counters of ADPU traffic
+type Metrics interface {
+	Add(ctx context.Context, counter Counter, value int64)
+}
+
+type noopTracer struct{}
+
+type noopSpan struct{}
+
+type noopMetrics struct{}
+
+// Tracer that records nothing, used by default
+func NewNoopTracer() Tracer {
+	return noopTracer{}
+}
+
+// Metrics that records nothing, used by default
+func NewNoopMetrics() Metrics {
+	return noopMetrics{}
+}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopSpan) IsRecording() bool {
+	return false
+}
+
+func (noopSpan) SetAttributes(attrs ...slog.Attr) {}
+
+func (noopSpan) End(err error) {}
+
+func (noopMetrics) Add(ctx context.Context, counter Counter, value int64) {}
+
+// Trace ADPU exchanges with given tracer, a span per exchange
+func WithTracer(tracer Tracer) ProtocolOption {
+	return func(p *protocolImpl) {
+		p.tracer = tracer
+	}
+}
+
+// Count frames, bytes and user rejections of ADPU exchanges with given metrics
+func WithMetrics(metrics Metrics) ProtocolOption {
+	return func(p *protocolImpl) {
+		p.metrics = metrics
+	}
+}
+
+type chunkKey struct{}
+
+// Return context carrying index of the chunk, of a command split into multiple ADPUs, being sent.
+// The index is recorded in span of the exchange.
+func ContextWithChunk(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, chunkKey{}, index)
+}
+
+// Get index of the chunk set by `ContextWithChunk`
+func ChunkFromContext(ctx context.Context) (int, bool) {
+	index, ok := ctx.Value(chunkKey{}).(int)
+	return index, ok
+}
@@ -0,0 +1,156 @@
+package adpu_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]slog.Value
+	err   error
+	ended bool
+}
+
+func (s *fakeSpan) IsRecording() bool {
+	return true
+}
+
+func (s *fakeSpan) SetAttributes(attrs ...slog.Attr) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *fakeSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, adpu.Span) {
+	span := &fakeSpan{name: name, attrs: map[string]slog.Value{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type fakeMetrics struct {
+	lock     sync.Mutex
+	counters map[adpu.Counter]int64
+}
+
+func (m *fakeMetrics) Add(ctx context.Context, counter adpu.Counter, value int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.counters[counter] += value
+}
+
+func TestProtocol_Telemetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		response []byte
+		sw       uint16
+		counters map[adpu.Counter]int64
+		attrs    map[string]int64
+	}{
+		{
+			name:     "Success",
+			ctx:      adpu.ContextWithChunk(context.Background(), 2),
+			response: hidFrame(0x00, 0x00, 0x03, 0xB1, 0x90, 0x00),
+			sw:       adpu.SW_OK,
+			counters: map[adpu.Counter]int64{
+				adpu.COUNTER_FRAMES_SENT:     2,
+				adpu.COUNTER_BYTES_SENT:      128,
+				adpu.COUNTER_FRAMES_RECEIVED: 1,
+				adpu.COUNTER_BYTES_RECEIVED:  64,
+			},
+			attrs: map[string]int64{
+				adpu.ATTR_CLA:   0xe0,
+				adpu.ATTR_INS:   0x04,
+				adpu.ATTR_P1:    0x80,
+				adpu.ATTR_P2:    0x00,
+				adpu.ATTR_CHUNK: 2,
+				adpu.ATTR_SW:    0x9000,
+			},
+		},
+		{
+			name:     "UserRejected",
+			ctx:      context.Background(),
+			response: hidFrame(0x00, 0x00, 0x02, 0x69, 0x85),
+			sw:       adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED,
+			counters: map[adpu.Counter]int64{
+				adpu.COUNTER_FRAMES_SENT:     2,
+				adpu.COUNTER_BYTES_SENT:      128,
+				adpu.COUNTER_FRAMES_RECEIVED: 1,
+				adpu.COUNTER_BYTES_RECEIVED:  64,
+				adpu.COUNTER_USER_REJECTIONS: 1,
+			},
+			attrs: map[string]int64{
+				adpu.ATTR_CLA: 0xe0,
+				adpu.ATTR_INS: 0x04,
+				adpu.ATTR_P1:  0x80,
+				adpu.ATTR_P2:  0x00,
+				adpu.ATTR_SW:  0x6985,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := device.NewMockDevice(ctrl)
+			mock.EXPECT().Write(test.ctx, gomock.Any()).Return(64, nil).Times(2)
+			mock.EXPECT().Read(test.ctx, gomock.Any()).DoAndReturn(readFrame(test.response))
+			tracer := &fakeTracer{}
+			metrics := &fakeMetrics{counters: map[adpu.Counter]int64{}}
+			proto := adpu.NewProtocol(mock, 0x0101, slog.Default(), adpu.WithTracer(tracer), adpu.WithMetrics(metrics))
+
+			_, sw, err := proto.Send(test.ctx, 0xe0, 0x04, 0x80, 0x00, make([]byte, 64))
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.sw, sw)
+			assert.Equal(t, test.counters, metrics.counters)
+			assert.Len(t, tracer.spans, 1)
+			span := tracer.spans[0]
+			assert.Equal(t, "adpu.Exchange", span.name)
+			assert.True(t, span.ended)
+			assert.NoError(t, span.err)
+			for key, value := range test.attrs {
+				assert.Equal(t, value, span.attrs[key].Int64(), key)
+			}
+			if _, ok := test.attrs[adpu.ATTR_CHUNK]; !ok {
+				assert.NotContains(t, span.attrs, adpu.ATTR_CHUNK)
+			}
+			for _, key := range []string{adpu.ATTR_DURATION, adpu.ATTR_WRITE_DURATION, adpu.ATTR_WAIT_DURATION, adpu.ATTR_READ_DURATION} {
+				assert.Equal(t, slog.KindDuration, span.attrs[key].Kind(), key)
+			}
+		})
+	}
+}
+
+func TestProtocol_Telemetry_Error(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctrl := gomock.NewController(t)
+	tracer := &fakeTracer{}
+	proto := adpu.NewProtocol(device.NewMockDevice(ctrl), 0x0101, slog.Default(), adpu.WithTracer(tracer))
+
+	_, err := proto.Exchange(ctx, []byte{0xe0, 0x06, 0x00, 0x00, 0x00})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, tracer.spans, 1)
+	assert.ErrorIs(t, tracer.spans[0].err, context.Canceled)
+	assert.Equal(t, int64(0x06), tracer.spans[0].attrs[adpu.ATTR_INS].Int64())
+	assert.NotContains(t, tracer.spans[0].attrs, adpu.ATTR_SW)
+}
@@ -0,0 +1,190 @@
+package adpu
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ntchjb/ledger-go/log"
+)
+
+var (
+	ErrTranscriptDiverged    = errors.New("command diverged from transcript")
+	ErrTranscriptExhausted   = errors.New("no more exchanges in transcript")
+	ErrTranscriptIncomplete  = errors.New("transcript is not fully replayed")
+	ErrRecordedExchangeError = errors.New("recorded exchange failed")
+)
+
+// A command/response pair of an ADPU exchange
+//
+// Command is a whole ADPU command, and Response is a whole ADPU response including SW.
+// For `Protocol.Send`, command is encoded in short format, or extended format if data is longer than 255 bytes.
+type TranscriptEntry struct {
+	Command  log.HexDisplay `json:"command"`
+	Response log.HexDisplay `json:"response,omitempty"`
+	// Error message, if the exchange failed
+	Error string `json:"error,omitempty"`
+}
+
+// Read transcript written by recording protocol, formatted as JSON lines
+func ReadTranscript(r io.Reader) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	decoder := json.NewDecoder(r)
+	for {
+		var entry TranscriptEntry
+		if err := decoder.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to decode transcript entry %d: %w", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func encodeTranscriptCommand(cla, ins, p1, p2 uint8, data []byte) ([]byte, error) {
+	return encodeCommand(cla, ins, p1, p2, data, len(data) > MAX_SHORT_DATA_LENGTH)
+}
+
+type recordingProtocol struct {
+	proto   Protocol
+	encoder *json.Encoder
+
+	lock sync.Mutex
+}
+
+// Wrap a protocol to record every exchange to `w` as a transcript,
+// in JSON lines format, which can be replayed by `NewReplayProtocol`
+func NewRecordingProtocol(proto Protocol, w io.Writer) Protocol {
+	return &recordingProtocol{
+		proto:   proto,
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (r *recordingProtocol) record(command []byte, response []byte, exchangeErr error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry := TranscriptEntry{
+		Command:  command,
+		Response: response,
+	}
+	if exchangeErr != nil {
+		entry.Error = exchangeErr.Error()
+	}
+	if err := r.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("unable to write transcript entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *recordingProtocol) Exchange(ctx context.Context, command []byte) ([]byte, error) {
+	res, exchangeErr := r.proto.Exchange(ctx, command)
+	if err := r.record(command, res, exchangeErr); err != nil {
+		return nil, err
+	}
+
+	return res, exchangeErr
+}
+
+func (r *recordingProtocol) Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
+	command, err := encodeTranscriptCommand(cla, ins, p1, p2, data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to encode ADPU command: %w", err)
+	}
+
+	res, sw, sendErr := r.proto.Send(ctx, cla, ins, p1, p2, data)
+	var response []byte
+	if sendErr == nil {
+		response = binary.BigEndian.AppendUint16(append([]byte{}, res...), sw)
+	}
+	if err := r.record(command, response, sendErr); err != nil {
+		return nil, 0, err
+	}
+
+	return res, sw, sendErr
+}
+
+// Reset is not recorded, as it does not exchange any command
+func (r *recordingProtocol) Reset(ctx context.Context) error {
+	return r.proto.Reset(ctx)
+}
+
+// ReplayProtocol serves recorded responses, in order, without a device
+type ReplayProtocol interface {
+	Protocol
+	// Check that every exchange in the transcript was replayed
+	Finish() error
+}
+
+type replayProtocol struct {
+	entries []TranscriptEntry
+	next    int
+
+	lock sync.Mutex
+}
+
+// Create a protocol that serves responses from transcript.
+// Any command that does not match the transcript, in order, fails with `ErrTranscriptDiverged`.
+func NewReplayProtocol(entries []TranscriptEntry) ReplayProtocol {
+	return &replayProtocol{
+		entries: entries,
+	}
+}
+
+func (r *replayProtocol) Exchange(ctx context.Context, command []byte) ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.next >= len(r.entries) {
+		return nil, fmt.Errorf("command: %s, transcript length: %d: %w", log.HexDisplay(command), len(r.entries), ErrTranscriptExhausted)
+	}
+	entry := r.entries[r.next]
+	if string(entry.Command) != string(command) {
+		return nil, fmt.Errorf("exchange %d, expected command %s, got %s: %w", r.next, entry.Command, log.HexDisplay(command), ErrTranscriptDiverged)
+	}
+	r.next++
+
+	if entry.Error != "" {
+		return nil, fmt.Errorf("exchange %d: %s: %w", r.next-1, entry.Error, ErrRecordedExchangeError)
+	}
+
+	return append([]byte{}, entry.Response...), nil
+}
+
+func (r *replayProtocol) Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
+	command, err := encodeTranscriptCommand(cla, ins, p1, p2, data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to encode ADPU command: %w", err)
+	}
+
+	res, err := r.Exchange(ctx, command)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to replay ADPU, command: %s, err: %w", log.HexDisplay(command), err)
+	}
+
+	return splitSW(res)
+}
+
+// There is no stale frame in transcript, so reset does nothing
+func (r *replayProtocol) Reset(ctx context.Context) error {
+	return nil
+}
+
+func (r *replayProtocol) Finish() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.next < len(r.entries) {
+		return fmt.Errorf("replayed %d of %d exchanges: %w", r.next, len(r.entries), ErrTranscriptIncomplete)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package adpu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecordingProtocol(t *testing.T) {
+	ctx := context.Background()
+	errSome := errors.New("some error")
+	ctrl := gomock.NewController(t)
+	mock := adpu.NewMockProtocol(ctrl)
+	mock.EXPECT().Send(ctx, uint8(0xe0), uint8(0x06), uint8(0x00), uint8(0x00), []byte{}).Return([]byte{0x01, 0x01, 0x0B, 0x02}, uint16(0x9000), nil)
+	mock.EXPECT().Exchange(ctx, []byte{0xe0, 0x02, 0x00, 0x00, 0x01, 0xA1}).Return([]byte{0x69, 0x85}, nil)
+	mock.EXPECT().Send(ctx, uint8(0xe0), uint8(0x04), uint8(0x00), uint8(0x00), []byte{0xA1, 0xA2}).Return(nil, uint16(0), errSome)
+
+	var buf bytes.Buffer
+	proto := adpu.NewRecordingProtocol(mock, &buf)
+
+	res, sw, err := proto.Send(ctx, 0xe0, 0x06, 0x00, 0x00, []byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x01, 0x0B, 0x02}, res)
+	assert.Equal(t, uint16(0x9000), sw)
+
+	res, err = proto.Exchange(ctx, []byte{0xe0, 0x02, 0x00, 0x00, 0x01, 0xA1})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x69, 0x85}, res)
+
+	_, _, err = proto.Send(ctx, 0xe0, 0x04, 0x00, 0x00, []byte{0xA1, 0xA2})
+	assert.ErrorIs(t, err, errSome)
+
+	assert.Equal(t, strings.Join([]string{
+		`{"command":"e006000000","response":"01010b029000"}`,
+		`{"command":"e002000001a1","response":"6985"}`,
+		`{"command":"e004000002a1a2","error":"some error"}`,
+		``,
+	}, "\n"), buf.String())
+}
+
+func TestReplayProtocol(t *testing.T) {
+	ctx := context.Background()
+	transcript := strings.Join([]string{
+		`{"command":"e006000000","response":"01010b029000"}`,
+		`{"command":"e002000001a1","response":"6985"}`,
+		`{"command":"e004000002a1a2","error":"some error"}`,
+	}, "\n")
+
+	tests := []struct {
+		name string
+		run  func(proto adpu.ReplayProtocol) error
+		err  error
+	}{
+		{
+			name: "Success",
+			run: func(proto adpu.ReplayProtocol) error {
+				res, sw, err := proto.Send(ctx, 0xe0, 0x06, 0x00, 0x00, nil)
+				assert.Equal(t, []byte{0x01, 0x01, 0x0B, 0x02}, res)
+				assert.Equal(t, uint16(0x9000), sw)
+				if err != nil {
+					return err
+				}
+				res, err = proto.Exchange(ctx, []byte{0xe0, 0x02, 0x00, 0x00, 0x01, 0xA1})
+				assert.Equal(t, []byte{0x69, 0x85}, res)
+				if err != nil {
+					return err
+				}
+				if _, _, err := proto.Send(ctx, 0xe0, 0x04, 0x00, 0x00, []byte{0xA1, 0xA2}); !errors.Is(err, adpu.ErrRecordedExchangeError) {
+					return err
+				}
+
+				return proto.Finish()
+			},
+			err: nil,
+		},
+		{
+			name: "Error_Diverged",
+			run: func(proto adpu.ReplayProtocol) error {
+				_, _, err := proto.Send(ctx, 0xe0, 0x06, 0x00, 0x01, nil)
+				return err
+			},
+			err: adpu.ErrTranscriptDiverged,
+		},
+		{
+			name: "Error_Incomplete",
+			run: func(proto adpu.ReplayProtocol) error {
+				if _, _, err := proto.Send(ctx, 0xe0, 0x06, 0x00, 0x00, nil); err != nil {
+					return err
+				}
+				return proto.Finish()
+			},
+			err: adpu.ErrTranscriptIncomplete,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := adpu.ReadTranscript(strings.NewReader(transcript))
+			assert.NoError(t, err)
+
+			err = test.run(adpu.NewReplayProtocol(entries))
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestReplayProtocol_Exhausted(t *testing.T) {
+	ctx := context.Background()
+	proto := adpu.NewReplayProtocol([]adpu.TranscriptEntry{
+		{
+			Command:  []byte{0xe0, 0x06, 0x00, 0x00, 0x00},
+			Response: []byte{0x90, 0x00},
+		},
+	})
+
+	_, err := proto.Exchange(ctx, []byte{0xe0, 0x06, 0x00, 0x00, 0x00})
+	assert.NoError(t, err)
+	_, err = proto.Exchange(ctx, []byte{0xe0, 0x06, 0x00, 0x00, 0x00})
+	assert.ErrorIs(t, err, adpu.ErrTranscriptExhausted)
+	assert.NoError(t, proto.Finish())
+}
@@ -0,0 +1,32 @@
+package dashboard
+
+const (
+	ADPU_CLA_OS        uint8 = 0xB0
+	ADPU_CLA_DASHBOARD uint8 = 0xE0
+
+	ADPU_INS_GET_APP_AND_VERSION uint8 = 0x01
+	ADPU_INS_GET_VERSION         uint8 = 0x01
+	ADPU_INS_QUIT_APP            uint8 = 0xA7
+	ADPU_INS_OPEN_APP            uint8 = 0xD8
+
+	// Format of GET_APP_AND_VERSION response
+	APP_AND_VERSION_FORMAT uint8 = 0x01
+
+	// Name of the running app when device is at dashboard
+	DASHBOARD_APP_NAME = "BOLOS"
+	// Name of the running app when device is at dashboard, on legacy firmwares
+	DASHBOARD_APP_NAME_LEGACY = "OLOS\x00"
+
+	// Flags of GET_VERSION response, in the first byte
+	DEVICE_FLAG_RECOVERY_MODE   uint8 = 0x01
+	DEVICE_FLAG_ONBOARDED       uint8 = 0x04
+	DEVICE_FLAG_MANAGER_ALLOWED uint8 = 0x08
+	DEVICE_FLAG_PIN_VALIDATED   uint8 = 0x80
+
+	// Target ID of a device running OS, otherwise the device is in bootloader mode
+	TARGET_ID_OS_MASK  uint32 = 0xF0000000
+	TARGET_ID_OS_VALUE uint32 = 0x30000000
+
+	// Requested app is not installed on device
+	SW_APP_NOT_INSTALLED uint16 = 0x6807
+)
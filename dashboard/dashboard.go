@@ -0,0 +1,239 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ntchjb/ledger-go/adpu"
+)
+
+const (
+	DEFAULT_POLL_INTERVAL       = 500 * time.Millisecond
+	DEFAULT_REENUMERATE_TIMEOUT = 10 * time.Second
+)
+
+var (
+	ErrAppNotInstalled = errors.New("app is not installed")
+)
+
+// Open a new ADPU protocol to the device
+//
+// Device re-enumerates after an app is opened or closed, so previous connection becomes unusable.
+// Connector is called again to connect to the re-enumerated device, and it is responsible for
+// releasing the previous connection, if any
+type Connector func(ctx context.Context) (adpu.Protocol, error)
+
+// Dashboard is safe for concurrent use. Its connection is shared, so opening or quitting an app
+// also affects the other callers.
+type Dashboard interface {
+	// Get name and version of the running app. Name is "BOLOS" if device is at dashboard
+	GetAppAndVersion(ctx context.Context) (AppAndVersion, error)
+	// Get device information i.e. model, firmware version and onboarding state. Device must be at dashboard
+	GetVersion(ctx context.Context) (DeviceInfo, error)
+	// Open an installed app by name i.e. "Ethereum". Device must be at dashboard
+	OpenApp(ctx context.Context, name string) error
+	// Quit the running app and go back to dashboard
+	QuitApp(ctx context.Context) error
+	// Make sure the app is running, by quitting the running app and opening the requested one if needed,
+	// then wait until the device re-enumerates
+	EnsureApp(ctx context.Context, name string) error
+	// Get current connection to device, which can be used to talk to the opened app
+	Protocol(ctx context.Context) (adpu.Protocol, error)
+}
+
+type DashboardOption func(d *dashboardImpl)
+
+// Interval between attempts to reconnect to device while waiting for it to re-enumerate
+func WithPollInterval(interval time.Duration) DashboardOption {
+	return func(d *dashboardImpl) {
+		d.pollInterval = interval
+	}
+}
+
+// Maximum duration to wait for device to re-enumerate after quitting or opening an app
+func WithReenumerateTimeout(timeout time.Duration) DashboardOption {
+	return func(d *dashboardImpl) {
+		d.reenumerateTimeout = timeout
+	}
+}
+
+type dashboardImpl struct {
+	connect Connector
+	logger  *slog.Logger
+
+	// Current connection, which is nil after device re-enumerates
+	proto     adpu.Protocol
+	protoLock sync.Mutex
+
+	pollInterval       time.Duration
+	reenumerateTimeout time.Duration
+}
+
+func NewDashboard(connect Connector, logger *slog.Logger, opts ...DashboardOption) Dashboard {
+	d := &dashboardImpl{
+		connect:            connect,
+		logger:             logger,
+		pollInterval:       DEFAULT_POLL_INTERVAL,
+		reenumerateTimeout: DEFAULT_REENUMERATE_TIMEOUT,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+func (d *dashboardImpl) Protocol(ctx context.Context) (adpu.Protocol, error) {
+	// Held while connecting, so that concurrent callers share one connection
+	d.protoLock.Lock()
+	defer d.protoLock.Unlock()
+
+	if d.proto != nil {
+		return d.proto, nil
+	}
+	proto, err := d.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to device: %w", err)
+	}
+	d.proto = proto
+
+	return proto, nil
+}
+
+// Drop current connection, so that the next call connects to device again
+func (d *dashboardImpl) disconnect() {
+	d.protoLock.Lock()
+	defer d.protoLock.Unlock()
+
+	d.proto = nil
+}
+
+func (d *dashboardImpl) GetAppAndVersion(ctx context.Context) (AppAndVersion, error) {
+	var res AppAndVersion
+	proto, err := d.Protocol(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	if err := adpu.Send(ctx, proto, ADPU_CLA_OS, ADPU_INS_GET_APP_AND_VERSION, 0x00, 0x00, &adpu.EmptyData{}, &res); err != nil {
+		return res, fmt.Errorf("unable to send get app and version to device: %w", err)
+	}
+
+	return res, nil
+}
+
+func (d *dashboardImpl) GetVersion(ctx context.Context) (DeviceInfo, error) {
+	var res DeviceInfo
+	proto, err := d.Protocol(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	if err := adpu.Send(ctx, proto, ADPU_CLA_DASHBOARD, ADPU_INS_GET_VERSION, 0x00, 0x00, &adpu.EmptyData{}, &res); err != nil {
+		return res, fmt.Errorf("unable to send get version to device: %w", err)
+	}
+	d.logger.Debug("Device info", "model", res.Model, "targetID", res.TargetID, "seVersion", res.SEVersion, "mcuVersion", res.MCUVersion)
+
+	return res, nil
+}
+
+func (d *dashboardImpl) OpenApp(ctx context.Context, name string) error {
+	req := AppName(name)
+	proto, err := d.Protocol(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("Open app", "name", name)
+	if err := adpu.Send(ctx, proto, ADPU_CLA_DASHBOARD, ADPU_INS_OPEN_APP, 0x00, 0x00, &req, &adpu.EmptyData{}); err != nil {
+		var statusErr *adpu.StatusError
+		if errors.As(err, &statusErr) && statusErr.SW == SW_APP_NOT_INSTALLED {
+			return fmt.Errorf("%w: %s: %w", ErrAppNotInstalled, name, err)
+		}
+		return fmt.Errorf("unable to send open app to device: %w", err)
+	}
+	// Device re-enumerates, so current connection is no longer usable
+	d.disconnect()
+
+	return nil
+}
+
+func (d *dashboardImpl) QuitApp(ctx context.Context) error {
+	proto, err := d.Protocol(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("Quit app")
+	if err := adpu.Send(ctx, proto, ADPU_CLA_OS, ADPU_INS_QUIT_APP, 0x00, 0x00, &adpu.EmptyData{}, &adpu.EmptyData{}); err != nil {
+		return fmt.Errorf("unable to send quit app to device: %w", err)
+	}
+	// Device re-enumerates, so current connection is no longer usable
+	d.disconnect()
+
+	return nil
+}
+
+func (d *dashboardImpl) EnsureApp(ctx context.Context, name string) error {
+	current, err := d.GetAppAndVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get running app: %w", err)
+	}
+	if current.Name == name {
+		return nil
+	}
+
+	if !current.IsDashboard() {
+		d.logger.Debug("Quit running app", "current", current.Name, "requested", name)
+		if err := d.QuitApp(ctx); err != nil {
+			return err
+		}
+		if err := d.waitForApp(ctx, func(app AppAndVersion) bool { return app.IsDashboard() }); err != nil {
+			return fmt.Errorf("unable to go back to dashboard: %w", err)
+		}
+	}
+
+	if err := d.OpenApp(ctx, name); err != nil {
+		return err
+	}
+	if err := d.waitForApp(ctx, func(app AppAndVersion) bool { return app.Name == name }); err != nil {
+		return fmt.Errorf("unable to wait for app %s to be opened: %w", name, err)
+	}
+
+	return nil
+}
+
+// Reconnect to device until the running app matches, or timeout
+func (d *dashboardImpl) waitForApp(ctx context.Context, match func(app AppAndVersion) bool) error {
+	ctx, cancel := context.WithTimeout(ctx, d.reenumerateTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// Stale connection may block, so each attempt is limited to a poll interval
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, d.pollInterval)
+		app, err := d.GetAppAndVersion(attemptCtx)
+		cancelAttempt()
+		if err != nil {
+			d.logger.Debug("Device is not ready, retrying", "err", err)
+			d.disconnect()
+			continue
+		}
+		if match(app) {
+			return nil
+		}
+		d.logger.Debug("Waiting for app", "current", app.Name)
+	}
+}
@@ -0,0 +1,254 @@
+package dashboard_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/dashboard"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func expectGetAppAndVersion(proto *adpu.MockProtocol, name string) *gomock.Call {
+	return proto.EXPECT().Send(gomock.Any(), dashboard.ADPU_CLA_OS, dashboard.ADPU_INS_GET_APP_AND_VERSION, uint8(0x00), uint8(0x00), gomock.Any()).
+		Return(appAndVersionData(name, "1.0.0"), adpu.SW_OK, nil)
+}
+
+func expectGetVersion(proto *adpu.MockProtocol) *gomock.Call {
+	return proto.EXPECT().Send(gomock.Any(), dashboard.ADPU_CLA_DASHBOARD, dashboard.ADPU_INS_GET_VERSION, uint8(0x00), uint8(0x00), gomock.Any()).
+		Return(deviceInfoData(0x33100004, []byte("1.1.0"), []byte{0xa4, 0x00, 0x00, 0x00}, []byte("5.24")), adpu.SW_OK, nil)
+}
+
+func expectQuitApp(proto *adpu.MockProtocol, sw uint16) *gomock.Call {
+	return proto.EXPECT().Send(gomock.Any(), dashboard.ADPU_CLA_OS, dashboard.ADPU_INS_QUIT_APP, uint8(0x00), uint8(0x00), gomock.Any()).
+		Return(nil, sw, nil)
+}
+
+func expectOpenApp(proto *adpu.MockProtocol, name string, sw uint16) *gomock.Call {
+	return proto.EXPECT().Send(gomock.Any(), dashboard.ADPU_CLA_DASHBOARD, dashboard.ADPU_INS_OPEN_APP, uint8(0x00), uint8(0x00), []byte(name)).
+		Return(nil, sw, nil)
+}
+
+// Connector that returns given connections in order, where nil connection means device is not enumerated yet
+func sequenceConnector(protos ...adpu.Protocol) (dashboard.Connector, *int) {
+	count := 0
+	return func(ctx context.Context) (adpu.Protocol, error) {
+		if count >= len(protos) {
+			return nil, errors.New("no more device")
+		}
+		proto := protos[count]
+		count++
+		if proto == nil {
+			return nil, errors.New("device not found")
+		}
+		return proto, nil
+	}, &count
+}
+
+func newTestDashboard(connect dashboard.Connector) dashboard.Dashboard {
+	return dashboard.NewDashboard(connect, slog.Default(), dashboard.WithPollInterval(time.Millisecond), dashboard.WithReenumerateTimeout(time.Second))
+}
+
+func TestDashboard_GetAppAndVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	proto := adpu.NewMockProtocol(ctrl)
+	expectGetAppAndVersion(proto, "Ethereum")
+	connect, count := sequenceConnector(proto)
+
+	res, err := newTestDashboard(connect).GetAppAndVersion(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, dashboard.AppAndVersion{Name: "Ethereum", Version: "1.0.0"}, res)
+	assert.Equal(t, 1, *count)
+}
+
+func TestDashboard_GetVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	proto := adpu.NewMockProtocol(ctrl)
+	expectGetVersion(proto)
+	connect, _ := sequenceConnector(proto)
+
+	res, err := newTestDashboard(connect).GetVersion(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, device.MODEL_NANO_S_PLUS, res.Model)
+	assert.Equal(t, "1.1.0", res.SEVersion)
+	assert.Equal(t, "5.24", res.MCUVersion)
+	assert.True(t, res.Onboarded)
+}
+
+func TestDashboard_OpenApp(t *testing.T) {
+	tests := []struct {
+		name  string
+		sw    uint16
+		errIs []error
+	}{
+		{
+			name: "Success",
+			sw:   adpu.SW_OK,
+		},
+		{
+			name:  "Error_AppNotInstalled",
+			sw:    dashboard.SW_APP_NOT_INSTALLED,
+			errIs: []error{dashboard.ErrAppNotInstalled, adpu.ErrSWNotOK},
+		},
+		{
+			name:  "Error_UserRefused",
+			sw:    adpu.SW_USER_REFUSED_ON_DEVICE,
+			errIs: []error{adpu.ErrUserRefused},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			proto := adpu.NewMockProtocol(ctrl)
+			expectOpenApp(proto, "Ethereum", test.sw)
+			connect, _ := sequenceConnector(proto)
+
+			err := newTestDashboard(connect).OpenApp(context.Background(), "Ethereum")
+
+			if test.errIs == nil {
+				assert.NoError(t, err)
+				return
+			}
+			for _, target := range test.errIs {
+				assert.ErrorIs(t, err, target)
+			}
+		})
+	}
+}
+
+func TestDashboard_EnsureApp(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success_AlreadyOpen", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		proto := adpu.NewMockProtocol(ctrl)
+		expectGetAppAndVersion(proto, "Ethereum")
+		connect, count := sequenceConnector(proto)
+		d := newTestDashboard(connect)
+
+		assert.NoError(t, d.EnsureApp(ctx, "Ethereum"))
+		current, err := d.Protocol(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, proto, current)
+		assert.Equal(t, 1, *count)
+	})
+
+	t.Run("Success_FromDashboard", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dashboardProto := adpu.NewMockProtocol(ctrl)
+		appProto := adpu.NewMockProtocol(ctrl)
+		gomock.InOrder(
+			expectGetAppAndVersion(dashboardProto, "BOLOS"),
+			expectOpenApp(dashboardProto, "Ethereum", adpu.SW_OK),
+			expectGetAppAndVersion(appProto, "Ethereum"),
+		)
+		connect, count := sequenceConnector(dashboardProto, nil, appProto)
+		d := newTestDashboard(connect)
+
+		assert.NoError(t, d.EnsureApp(ctx, "Ethereum"))
+		current, err := d.Protocol(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, appProto, current)
+		assert.Equal(t, 3, *count)
+	})
+
+	t.Run("Success_FromAnotherApp", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		bitcoinProto := adpu.NewMockProtocol(ctrl)
+		dashboardProto := adpu.NewMockProtocol(ctrl)
+		appProto := adpu.NewMockProtocol(ctrl)
+		gomock.InOrder(
+			expectGetAppAndVersion(bitcoinProto, "Bitcoin"),
+			expectQuitApp(bitcoinProto, adpu.SW_OK),
+			// App is still closing
+			expectGetAppAndVersion(dashboardProto, "Bitcoin"),
+			expectGetAppAndVersion(dashboardProto, "BOLOS"),
+			expectOpenApp(dashboardProto, "Ethereum", adpu.SW_OK),
+			expectGetAppAndVersion(appProto, "Ethereum"),
+		)
+		connect, count := sequenceConnector(bitcoinProto, nil, dashboardProto, nil, nil, appProto)
+		d := newTestDashboard(connect)
+
+		assert.NoError(t, d.EnsureApp(ctx, "Ethereum"))
+		current, err := d.Protocol(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, appProto, current)
+		assert.Equal(t, 6, *count)
+	})
+
+	t.Run("Error_AppNotInstalled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		proto := adpu.NewMockProtocol(ctrl)
+		gomock.InOrder(
+			expectGetAppAndVersion(proto, "BOLOS"),
+			expectOpenApp(proto, "Ethereum", dashboard.SW_APP_NOT_INSTALLED),
+		)
+		connect, _ := sequenceConnector(proto)
+
+		err := newTestDashboard(connect).EnsureApp(ctx, "Ethereum")
+		assert.ErrorIs(t, err, dashboard.ErrAppNotInstalled)
+	})
+
+	t.Run("Error_ReenumerateTimeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		proto := adpu.NewMockProtocol(ctrl)
+		gomock.InOrder(
+			expectGetAppAndVersion(proto, "BOLOS"),
+			expectOpenApp(proto, "Ethereum", adpu.SW_OK),
+		)
+		connect, _ := sequenceConnector(proto)
+		d := dashboard.NewDashboard(connect, slog.Default(), dashboard.WithPollInterval(time.Millisecond), dashboard.WithReenumerateTimeout(20*time.Millisecond))
+
+		err := d.EnsureApp(ctx, "Ethereum")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// Run with -race, so that unsynchronized access to the shared connection is detected
+func TestDashboard_GetVersion_ConcurrentWithEnsureApp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dashboardProto := adpu.NewMockProtocol(ctrl)
+	appProto := adpu.NewMockProtocol(ctrl)
+	expectGetVersion(dashboardProto).AnyTimes()
+	expectGetVersion(appProto).AnyTimes()
+	expectGetAppAndVersion(dashboardProto, "BOLOS").AnyTimes()
+	expectOpenApp(dashboardProto, "Ethereum", adpu.SW_OK)
+	expectGetAppAndVersion(appProto, "Ethereum").AnyTimes()
+	// Device re-enumerates after the app is opened
+	connects := 0
+	connect := func(ctx context.Context) (adpu.Protocol, error) {
+		connects++
+		if connects == 1 {
+			return dashboardProto, nil
+		}
+		return appProto, nil
+	}
+	d := newTestDashboard(connect)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := d.GetVersion(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, device.MODEL_NANO_S_PLUS, res.Model)
+		}()
+	}
+	assert.NoError(t, d.EnsureApp(ctx, "Ethereum"))
+	wg.Wait()
+
+	proto, err := d.Protocol(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, appProto, proto)
+}
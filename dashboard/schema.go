@@ -0,0 +1,157 @@
+package dashboard
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ntchjb/ledger-go/device"
+)
+
+// Reader of length-value fields, where length is 1 byte
+type lvReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *lvReader) done() bool {
+	return r.offset >= len(r.data)
+}
+
+func (r *lvReader) next(field string) ([]byte, error) {
+	if r.done() {
+		return nil, fmt.Errorf("data too short, missing length of %s", field)
+	}
+	length := int(r.data[r.offset])
+	r.offset++
+	if r.offset+length > len(r.data) {
+		return nil, fmt.Errorf("data too short, expected %s length %d, got %d", field, length, len(r.data)-r.offset)
+	}
+	value := r.data[r.offset : r.offset+length]
+	r.offset += length
+
+	return value, nil
+}
+
+// Running app and its version, returned by GET_APP_AND_VERSION
+type AppAndVersion struct {
+	Name    string
+	Version string
+	Flags   []byte
+}
+
+// [format (1 byte), name length (1 byte), name, version length (1 byte), version, flags length (1 byte), flags]
+// Flags are optional, as some apps do not return them
+func (a *AppAndVersion) UnmarshalADPU(data []byte) error {
+	if len(data) < 1 {
+		return fmt.Errorf("data too short, expected at least %d, got %d", 1, len(data))
+	}
+	if data[0] != APP_AND_VERSION_FORMAT {
+		return fmt.Errorf("unsupported format: 0x%02x", data[0])
+	}
+	r := lvReader{data: data, offset: 1}
+
+	name, err := r.next("name")
+	if err != nil {
+		return err
+	}
+	version, err := r.next("version")
+	if err != nil {
+		return err
+	}
+	a.Name = string(name)
+	a.Version = string(version)
+	a.Flags = nil
+	if !r.done() {
+		flags, err := r.next("flags")
+		if err != nil {
+			return err
+		}
+		a.Flags = make([]byte, len(flags))
+		copy(a.Flags, flags)
+	}
+
+	return nil
+}
+
+// Whether device is at dashboard i.e. no app is running
+func (a *AppAndVersion) IsDashboard() bool {
+	return a.Name == DASHBOARD_APP_NAME || a.Name == DASHBOARD_APP_NAME_LEGACY
+}
+
+// Name of app to be opened, in ASCII
+type AppName string
+
+func (n *AppName) MarshalADPU() ([]byte, error) {
+	if len(*n) == 0 || len(*n) > 0xFF {
+		return nil, fmt.Errorf("invalid app name length: %d", len(*n))
+	}
+
+	return []byte(*n), nil
+}
+
+// Device information, returned by GET_VERSION at dashboard
+type DeviceInfo struct {
+	TargetID uint32
+	// Model identified by target ID
+	Model device.Model
+	// Version of OS running on secure element i.e. "2.2.3"
+	SEVersion string
+	Flags     []byte
+	// Version of MCU firmware. Empty if device is in bootloader mode
+	MCUVersion string
+
+	Bootloader     bool
+	Onboarded      bool
+	PINValidated   bool
+	ManagerAllowed bool
+	RecoveryMode   bool
+}
+
+// [target ID (4 bytes), SE version length (1 byte), SE version, flags length (1 byte), flags, MCU version length (1 byte), MCU version, ...]
+// Trailing fields i.e. MCU bootloader version, hardware version, language are ignored.
+// In bootloader mode, only target ID and bootloader version are parsed
+func (d *DeviceInfo) UnmarshalADPU(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("data too short, expected at least %d, got %d", 4, len(data))
+	}
+	*d = DeviceInfo{
+		TargetID: binary.BigEndian.Uint32(data[:4]),
+	}
+	d.Model = device.ModelFromTargetID(d.TargetID)
+	d.Bootloader = d.TargetID&TARGET_ID_OS_MASK != TARGET_ID_OS_VALUE
+	r := lvReader{data: data, offset: 4}
+
+	seVersion, err := r.next("SE version")
+	if err != nil {
+		return err
+	}
+	d.SEVersion = string(seVersion)
+	if d.Bootloader {
+		return nil
+	}
+
+	flags, err := r.next("flags")
+	if err != nil {
+		return err
+	}
+	d.Flags = make([]byte, len(flags))
+	copy(d.Flags, flags)
+	if len(flags) > 0 {
+		d.RecoveryMode = flags[0]&DEVICE_FLAG_RECOVERY_MODE != 0
+		d.Onboarded = flags[0]&DEVICE_FLAG_ONBOARDED != 0
+		d.ManagerAllowed = flags[0]&DEVICE_FLAG_MANAGER_ALLOWED != 0
+		d.PINValidated = flags[0]&DEVICE_FLAG_PIN_VALIDATED != 0
+	}
+
+	mcuVersion, err := r.next("MCU version")
+	if err != nil {
+		return err
+	}
+	// MCU version may be null-terminated
+	if len(mcuVersion) > 0 && mcuVersion[len(mcuVersion)-1] == 0x00 {
+		mcuVersion = mcuVersion[:len(mcuVersion)-1]
+	}
+	d.MCUVersion = string(mcuVersion)
+
+	return nil
+}
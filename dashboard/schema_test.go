@@ -0,0 +1,187 @@
+package dashboard_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/dashboard"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+)
+
+func appAndVersionData(name, version string, flags ...byte) []byte {
+	data := []byte{dashboard.APP_AND_VERSION_FORMAT, byte(len(name))}
+	data = append(data, name...)
+	data = append(data, byte(len(version)))
+	data = append(data, version...)
+	if flags != nil {
+		data = append(data, byte(len(flags)))
+		data = append(data, flags...)
+	}
+
+	return data
+}
+
+func TestAppAndVersion_UnmarshalADPU(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		res         dashboard.AppAndVersion
+		isDashboard bool
+		err         bool
+	}{
+		{
+			name:        "Success_Dashboard",
+			data:        appAndVersionData("BOLOS", "2.2.3", 0x00),
+			res:         dashboard.AppAndVersion{Name: "BOLOS", Version: "2.2.3", Flags: []byte{0x00}},
+			isDashboard: true,
+		},
+		{
+			name:        "Success_LegacyDashboard",
+			data:        appAndVersionData("OLOS\x00", "1.6.0"),
+			res:         dashboard.AppAndVersion{Name: "OLOS\x00", Version: "1.6.0"},
+			isDashboard: true,
+		},
+		{
+			name: "Success_App",
+			data: appAndVersionData("Ethereum", "1.13.0", 0x02),
+			res:  dashboard.AppAndVersion{Name: "Ethereum", Version: "1.13.0", Flags: []byte{0x02}},
+		},
+		{
+			name: "Error_Empty",
+			data: []byte{},
+			err:  true,
+		},
+		{
+			name: "Error_UnsupportedFormat",
+			data: []byte{0x02, 0x00, 0x00},
+			err:  true,
+		},
+		{
+			name: "Error_NameTooShort",
+			data: []byte{0x01, 0x05, 'B', 'O'},
+			err:  true,
+		},
+		{
+			name: "Error_MissingVersion",
+			data: []byte{0x01, 0x01, 'B'},
+			err:  true,
+		},
+		{
+			name: "Error_FlagsTooShort",
+			data: append(appAndVersionData("Ethereum", "1.13.0"), 0x02, 0x01),
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var res dashboard.AppAndVersion
+			err := res.UnmarshalADPU(test.data)
+
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.res, res)
+			assert.Equal(t, test.isDashboard, res.IsDashboard())
+		})
+	}
+}
+
+func deviceInfoData(targetID uint32, fields ...[]byte) []byte {
+	data := binary.BigEndian.AppendUint32(nil, targetID)
+	for _, field := range fields {
+		data = append(data, byte(len(field)))
+		data = append(data, field...)
+	}
+
+	return data
+}
+
+func TestDeviceInfo_UnmarshalADPU(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		res  dashboard.DeviceInfo
+		err  bool
+	}{
+		{
+			name: "Success_NanoX",
+			data: deviceInfoData(0x33000004, []byte("2.2.3"), []byte{0xa6, 0x00, 0x00, 0x00}, []byte("2.30\x00"), []byte("3.0"), []byte{0x00}),
+			res: dashboard.DeviceInfo{
+				TargetID:     0x33000004,
+				Model:        device.MODEL_NANO_X,
+				SEVersion:    "2.2.3",
+				Flags:        []byte{0xa6, 0x00, 0x00, 0x00},
+				MCUVersion:   "2.30",
+				Onboarded:    true,
+				PINValidated: true,
+			},
+		},
+		{
+			name: "Success_NanoS_NotOnboarded",
+			data: deviceInfoData(0x31100004, []byte("2.1.0"), []byte{0x08, 0x00, 0x00, 0x00}, []byte("1.12")),
+			res: dashboard.DeviceInfo{
+				TargetID:       0x31100004,
+				Model:          device.MODEL_NANO_S,
+				SEVersion:      "2.1.0",
+				Flags:          []byte{0x08, 0x00, 0x00, 0x00},
+				MCUVersion:     "1.12",
+				ManagerAllowed: true,
+			},
+		},
+		{
+			name: "Success_RecoveryMode",
+			data: deviceInfoData(0x33300004, []byte("1.1.1"), []byte{0x01}, []byte("1.0")),
+			res: dashboard.DeviceInfo{
+				TargetID:     0x33300004,
+				Model:        device.MODEL_FLEX,
+				SEVersion:    "1.1.1",
+				Flags:        []byte{0x01},
+				MCUVersion:   "1.0",
+				RecoveryMode: true,
+			},
+		},
+		{
+			name: "Success_Bootloader",
+			data: deviceInfoData(0x01000001, []byte("0.11")),
+			res: dashboard.DeviceInfo{
+				TargetID:   0x01000001,
+				Model:      device.MODEL_UNKNOWN,
+				SEVersion:  "0.11",
+				Bootloader: true,
+			},
+		},
+		{
+			name: "Error_TargetIDTooShort",
+			data: []byte{0x33, 0x00},
+			err:  true,
+		},
+		{
+			name: "Error_MissingFlags",
+			data: deviceInfoData(0x33000004, []byte("2.2.3")),
+			err:  true,
+		},
+		{
+			name: "Error_MCUVersionTooShort",
+			data: append(deviceInfoData(0x33000004, []byte("2.2.3"), []byte{0x00}), 0x04, '2'),
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var res dashboard.DeviceInfo
+			err := res.UnmarshalADPU(test.data)
+
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.res, res)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// Tag of MTU query command and its response
+	BLE_MTU_QUERY_TAG uint8 = 0x08
+	// Frame size used before MTU is negotiated
+	BLE_DEFAULT_MTU int = 20
+)
+
+var (
+	ErrInvalidMTUResponse = errors.New("invalid MTU response")
+)
+
+// GATTPipe is an abstraction of write and notify characteristics of Ledger BLE service
+type GATTPipe interface {
+	// Write value to the write characteristic
+	Write(ctx context.Context, value []byte) error
+	// Wait for the next value notified by the notify characteristic
+	Notification(ctx context.Context) ([]byte, error)
+}
+
+// BLEDevice is a Ledger device connected via Bluetooth LE
+//
+// Each Read returns one notification, and each Write writes one value,
+// so ADPU messages need to be split into frames that fit MTU by `adpu.NewBLEFramer`
+type BLEDevice interface {
+	Device
+	// Negotiate MTU with the device, for the first call.
+	// It returns frame size that the device accepts.
+	MTU(ctx context.Context) (int, error)
+}
+
+type bleDevice struct {
+	pipe GATTPipe
+
+	mtu     int
+	mtuLock sync.Mutex
+}
+
+func NewBLEDevice(pipe GATTPipe) BLEDevice {
+	return &bleDevice{
+		pipe: pipe,
+	}
+}
+
+func (d *bleDevice) MTU(ctx context.Context) (int, error) {
+	d.mtuLock.Lock()
+	defer d.mtuLock.Unlock()
+
+	if d.mtu > 0 {
+		return d.mtu, nil
+	}
+
+	// Query MTU by [tag (1 byte), 0x00000000]
+	// Response is [tag (1 byte), 0x00000000, mtu (1 byte)]
+	if err := d.pipe.Write(ctx, []byte{BLE_MTU_QUERY_TAG, 0x00, 0x00, 0x00, 0x00}); err != nil {
+		return 0, fmt.Errorf("unable to write MTU query: %w", err)
+	}
+	for {
+		value, err := d.pipe.Notification(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("unable to read MTU response: %w", err)
+		}
+		// Skip stale notifications not related to MTU query
+		if len(value) == 0 || value[0] != BLE_MTU_QUERY_TAG {
+			continue
+		}
+		if len(value) < 6 {
+			return 0, fmt.Errorf("MTU response is too short, expected 6, got %d: %w", len(value), ErrInvalidMTUResponse)
+		}
+		d.mtu = int(value[5])
+		break
+	}
+
+	if d.mtu == 0 {
+		d.mtu = BLE_DEFAULT_MTU
+	}
+
+	return d.mtu, nil
+}
+
+// Read a notification into `data`
+func (d *bleDevice) Read(ctx context.Context, data []byte) (n int, err error) {
+	value, err := d.pipe.Notification(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read notification: %w", err)
+	}
+	if len(value) > len(data) {
+		return 0, fmt.Errorf("notification length is %d, but buffer size is %d: %w", len(value), len(data), ErrResponseBufferTooSmall)
+	}
+
+	return copy(data, value), nil
+}
+
+// Write a frame as a value of write characteristic
+func (d *bleDevice) Write(ctx context.Context, data []byte) (n int, err error) {
+	if err := d.pipe.Write(ctx, data); err != nil {
+		return 0, fmt.Errorf("unable to write value: %w", err)
+	}
+
+	return len(data), nil
+}
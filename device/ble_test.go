@@ -0,0 +1,202 @@
+package device_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// In-memory stand-in of GATT write/notify characteristics
+type memoryGATTPipe struct {
+	written       chan []byte
+	notifications chan []byte
+}
+
+func newMemoryGATTPipe() *memoryGATTPipe {
+	return &memoryGATTPipe{
+		written:       make(chan []byte, 16),
+		notifications: make(chan []byte, 16),
+	}
+}
+
+func (p *memoryGATTPipe) Write(ctx context.Context, value []byte) error {
+	select {
+	case p.written <- append([]byte{}, value...):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *memoryGATTPipe) Notification(ctx context.Context) ([]byte, error) {
+	select {
+	case value := <-p.notifications:
+		return value, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestBLEDevice_MTU(t *testing.T) {
+	tests := []struct {
+		name          string
+		notifications [][]byte
+		mtu           int
+		err           error
+	}{
+		{
+			name: "Success",
+			notifications: [][]byte{
+				{0x08, 0x00, 0x00, 0x00, 0x00, 0x99},
+			},
+			mtu: 0x99,
+			err: nil,
+		},
+		{
+			name: "Success_SkipStaleNotification",
+			notifications: [][]byte{
+				{0x05, 0x00, 0x00, 0x00, 0x02, 0x90, 0x00},
+				{0x08, 0x00, 0x00, 0x00, 0x00, 0x80},
+			},
+			mtu: 0x80,
+			err: nil,
+		},
+		{
+			name: "Success_DefaultMTU",
+			notifications: [][]byte{
+				{0x08, 0x00, 0x00, 0x00, 0x00, 0x00},
+			},
+			mtu: device.BLE_DEFAULT_MTU,
+			err: nil,
+		},
+		{
+			name: "Error_InvalidMTUResponse",
+			notifications: [][]byte{
+				{0x08, 0x00, 0x00, 0x00},
+			},
+			mtu: 0,
+			err: device.ErrInvalidMTUResponse,
+		},
+		{
+			name:          "Error_NoResponse",
+			notifications: nil,
+			mtu:           0,
+			err:           context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			pipe := newMemoryGATTPipe()
+			for _, notification := range test.notifications {
+				pipe.notifications <- notification
+			}
+			dev := device.NewBLEDevice(pipe)
+
+			mtu, err := dev.MTU(ctx)
+
+			assert.Equal(t, test.mtu, mtu)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, []byte{0x08, 0x00, 0x00, 0x00, 0x00}, <-pipe.written)
+		})
+	}
+}
+
+func TestBLEDevice_MTU_Cached(t *testing.T) {
+	ctx := context.Background()
+	pipe := newMemoryGATTPipe()
+	pipe.notifications <- []byte{0x08, 0x00, 0x00, 0x00, 0x00, 0x99}
+	dev := device.NewBLEDevice(pipe)
+
+	mtu, err := dev.MTU(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0x99, mtu)
+
+	// MTU is queried only once
+	mtu, err = dev.MTU(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0x99, mtu)
+	assert.Len(t, pipe.written, 1)
+}
+
+func TestBLEDevice_ReadWrite(t *testing.T) {
+	ctx := context.Background()
+	pipe := newMemoryGATTPipe()
+	dev := device.NewBLEDevice(pipe)
+
+	n, err := dev.Write(ctx, []byte{0x05, 0x00, 0x00, 0x00, 0x01, 0xA1})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, []byte{0x05, 0x00, 0x00, 0x00, 0x01, 0xA1}, <-pipe.written)
+
+	pipe.notifications <- []byte{0x05, 0x00, 0x00, 0x00, 0x02, 0x90, 0x00}
+	buf := make([]byte, 20)
+	n, err = dev.Read(ctx, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x05, 0x00, 0x00, 0x00, 0x02, 0x90, 0x00}, buf[:n])
+
+	pipe.notifications <- []byte{0x05, 0x00, 0x00, 0x00, 0x02, 0x90, 0x00}
+	_, err = dev.Read(ctx, make([]byte, 4))
+	assert.ErrorIs(t, err, device.ErrResponseBufferTooSmall)
+}
+
+func TestBLEDevice_PipeError(t *testing.T) {
+	ctx := context.Background()
+	errSome := errors.New("some error")
+
+	tests := []struct {
+		name string
+		pipe func(pipe *device.MockGATTPipe)
+		call func(dev device.BLEDevice) error
+	}{
+		{
+			name: "Error_MTUQueryWrite",
+			pipe: func(pipe *device.MockGATTPipe) {
+				pipe.EXPECT().Write(ctx, []byte{0x08, 0x00, 0x00, 0x00, 0x00}).Return(errSome)
+			},
+			call: func(dev device.BLEDevice) error {
+				_, err := dev.MTU(ctx)
+				return err
+			},
+		},
+		{
+			name: "Error_Write",
+			pipe: func(pipe *device.MockGATTPipe) {
+				pipe.EXPECT().Write(ctx, []byte{0x05, 0x00, 0x00, 0x00, 0x00}).Return(errSome)
+			},
+			call: func(dev device.BLEDevice) error {
+				_, err := dev.Write(ctx, []byte{0x05, 0x00, 0x00, 0x00, 0x00})
+				return err
+			},
+		},
+		{
+			name: "Error_Read",
+			pipe: func(pipe *device.MockGATTPipe) {
+				pipe.EXPECT().Notification(ctx).Return(nil, errSome)
+			},
+			call: func(dev device.BLEDevice) error {
+				_, err := dev.Read(ctx, make([]byte, 20))
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			pipe := device.NewMockGATTPipe(ctrl)
+			test.pipe(pipe)
+
+			err := test.call(device.NewBLEDevice(pipe))
+
+			assert.ErrorIs(t, err, errSome)
+		})
+	}
+}
@@ -11,6 +11,14 @@ type Device interface {
 	Write(ctx context.Context, data []byte) (n int, err error)
 }
 
+// Ledger device connected via USB HID, which should be closed after use
+type USBDevice interface {
+	Device
+	// Get serial number from USB string descriptor
+	SerialNumber() (string, error)
+	Close() error
+}
+
 type ledgerDevice struct {
 	device hid.Device
 }
@@ -29,3 +37,11 @@ func (d *ledgerDevice) Write(ctx context.Context, data []byte) (n int, err error
 	data = append([]byte{0x00}, data...)
 	return d.device.WriteOutput(ctx, data)
 }
+
+func (d *ledgerDevice) SerialNumber() (string, error) {
+	return d.device.GetSerialNumber()
+}
+
+func (d *ledgerDevice) Close() error {
+	return d.device.Close()
+}
@@ -0,0 +1,180 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/gousb"
+	"github.com/ntchjb/gohid/hid"
+	"github.com/ntchjb/gohid/usb"
+)
+
+const (
+	LEDGER_VENDOR_ID uint16 = 0x2C97
+
+	// Bitmask of USB interfaces, in the lower byte of product ID
+	USB_INTERFACE_HID     uint8 = 0x01
+	USB_INTERFACE_HID_KBD uint8 = 0x02
+	USB_INTERFACE_U2F     uint8 = 0x04
+	USB_INTERFACE_CCID    uint8 = 0x08
+	USB_INTERFACE_WEBUSB  uint8 = 0x10
+
+	// Number of the USB HID interface which carries ADPU. Other HID interface is used by U2F
+	ADPU_HID_INTERFACE_NUMBER = 0
+)
+
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+	ErrDeviceMismatch = errors.New("opened device does not match the discovered one")
+
+	// Model by the upper byte of product ID
+	productIDModels = map[uint8]Model{
+		0x00: MODEL_BLUE,
+		0x10: MODEL_NANO_S,
+		0x40: MODEL_NANO_X,
+		0x50: MODEL_NANO_S_PLUS,
+		0x60: MODEL_STAX,
+		0x70: MODEL_FLEX,
+	}
+
+	// Model by product ID used by legacy firmwares and bootloader, which has no interface bitmask
+	legacyProductIDModels = map[uint16]Model{
+		0x0000: MODEL_BLUE,
+		0x0001: MODEL_NANO_S,
+		0x0004: MODEL_NANO_X,
+		0x0005: MODEL_NANO_S_PLUS,
+		0x0006: MODEL_STAX,
+		0x0007: MODEL_FLEX,
+	}
+)
+
+// Get device model and bitmask of USB interfaces from USB product ID i.e. 0x5011 for Nano S Plus with HID and WebUSB
+func ModelFromProductID(productID uint16) (Model, uint8) {
+	if model, ok := legacyProductIDModels[productID]; ok {
+		return model, USB_INTERFACE_HID
+	}
+
+	return productIDModels[uint8(productID>>8)], uint8(productID)
+}
+
+// Ledger device connected via USB, found by `Discover`
+type USBDeviceInfo struct {
+	VendorID  uint16
+	ProductID uint16
+	Model     Model
+	// Bitmask of USB interfaces enabled on device i.e. USB_INTERFACE_HID | USB_INTERFACE_WEBUSB
+	Interfaces uint8
+
+	Bus     int
+	Address int
+
+	// Target of USB HID interface which carries ADPU
+	ConfigNumber     int
+	InterfaceNumber  int
+	AltSettingNumber int
+}
+
+func (i USBDeviceInfo) String() string {
+	return fmt.Sprintf("%s [%04x:%04x] bus %d, address %d", i.Model, i.VendorID, i.ProductID, i.Bus, i.Address)
+}
+
+// List Ledger devices connected via USB, which have ADPU HID interface
+func Discover(usbCtx usb.Context) ([]USBDeviceInfo, error) {
+	var infos []USBDeviceInfo
+	if err := usbCtx.IterateDevices(func(desc *gousb.DeviceDesc) {
+		if uint16(desc.Vendor) != LEDGER_VENDOR_ID {
+			return
+		}
+		model, interfaces := ModelFromProductID(uint16(desc.Product))
+		for _, config := range desc.Configs {
+			for _, intf := range config.Interfaces {
+				if intf.Number != ADPU_HID_INTERFACE_NUMBER {
+					continue
+				}
+				for _, setting := range intf.AltSettings {
+					if setting.Class != gousb.ClassHID {
+						continue
+					}
+					infos = append(infos, USBDeviceInfo{
+						VendorID:         uint16(desc.Vendor),
+						ProductID:        uint16(desc.Product),
+						Model:            model,
+						Interfaces:       interfaces,
+						Bus:              desc.Bus,
+						Address:          desc.Address,
+						ConfigNumber:     config.Number,
+						InterfaceNumber:  intf.Number,
+						AltSettingNumber: setting.Alternate,
+					})
+					return
+				}
+			}
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("unable to iterate USB devices: %w", err)
+	}
+
+	return infos, nil
+}
+
+// Open a discovered Ledger device, claiming its ADPU HID interface
+//
+// USB device is opened by bus and address if `usbCtx` is `USBAddressContext` i.e. `NewUSBContext`.
+// Otherwise, it is opened by vendor ID and product ID, so if multiple devices of the same model are connected,
+// the opened one may not be the requested one. In that case, `ErrDeviceMismatch` is returned.
+func OpenUSBDevice(usbCtx usb.Context, info USBDeviceInfo, logger *slog.Logger) (USBDevice, error) {
+	var usbDevice usb.Device
+	var err error
+	if addrCtx, ok := usbCtx.(USBAddressContext); ok {
+		usbDevice, err = addrCtx.OpenDeviceAt(info.Bus, info.Address)
+	} else {
+		usbDevice, err = usbCtx.OpenDevice(gousb.ID(info.VendorID), gousb.ID(info.ProductID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open USB device %s: %w", info, err)
+	}
+	if usbDevice == nil {
+		return nil, fmt.Errorf("unable to open USB device %s: %w", info, ErrDeviceNotFound)
+	}
+	if desc := usbDevice.Descriptor(); desc.Bus != info.Bus || desc.Address != info.Address {
+		return nil, errors.Join(
+			fmt.Errorf("expected device at bus %d, address %d, got bus %d, address %d: %w", info.Bus, info.Address, desc.Bus, desc.Address, ErrDeviceMismatch),
+			usbDevice.Close(),
+		)
+	}
+
+	hidDevice, err := hid.NewDevice(usbDevice, hid.DeviceConfig{
+		StreamLaneCount: hid.DEFAULT_ENDPOINT_STREAM_COUNT,
+	}, logger)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to create HID device: %w", err), usbDevice.Close())
+	}
+	if err := hidDevice.SetAutoDetach(true); err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to set auto detach: %w", err), usbDevice.Close())
+	}
+	if err := hidDevice.SetTarget(info.ConfigNumber, info.InterfaceNumber, info.AltSettingNumber); err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to set target of HID device: %w", err), usbDevice.Close())
+	}
+
+	return &ledgerDevice{
+		device: hidDevice,
+	}, nil
+}
+
+// Open the first Ledger device connected via USB
+func OpenFirstUSBDevice(usbCtx usb.Context, logger *slog.Logger) (USBDevice, USBDeviceInfo, error) {
+	infos, err := Discover(usbCtx)
+	if err != nil {
+		return nil, USBDeviceInfo{}, err
+	}
+	if len(infos) == 0 {
+		return nil, USBDeviceInfo{}, fmt.Errorf("no Ledger device is connected: %w", ErrDeviceNotFound)
+	}
+	device, err := OpenUSBDevice(usbCtx, infos[0], logger)
+	if err != nil {
+		return nil, infos[0], err
+	}
+
+	return device, infos[0], nil
+}
@@ -0,0 +1,275 @@
+package device_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/ntchjb/gohid/hid"
+	"github.com/ntchjb/gohid/usb"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func hidInterface(number int, epNumber int) gousb.InterfaceDesc {
+	return gousb.InterfaceDesc{
+		Number: number,
+		AltSettings: []gousb.InterfaceSetting{
+			{
+				Number:    number,
+				Alternate: 0,
+				Class:     gousb.ClassHID,
+				Endpoints: map[gousb.EndpointAddress]gousb.EndpointDesc{
+					gousb.EndpointAddress(0x80 | epNumber): {
+						Address:       gousb.EndpointAddress(0x80 | epNumber),
+						Number:        epNumber,
+						Direction:     gousb.EndpointDirectionIn,
+						MaxPacketSize: 64,
+						TransferType:  gousb.TransferTypeInterrupt,
+						PollInterval:  time.Millisecond,
+					},
+					gousb.EndpointAddress(epNumber): {
+						Address:       gousb.EndpointAddress(epNumber),
+						Number:        epNumber,
+						Direction:     gousb.EndpointDirectionOut,
+						MaxPacketSize: 64,
+						TransferType:  gousb.TransferTypeInterrupt,
+						PollInterval:  time.Millisecond,
+					},
+				},
+			},
+		},
+	}
+}
+
+func deviceDesc(vendorID, productID gousb.ID, bus, address int, interfaces ...gousb.InterfaceDesc) *gousb.DeviceDesc {
+	return &gousb.DeviceDesc{
+		Bus:     bus,
+		Address: address,
+		Vendor:  vendorID,
+		Product: productID,
+		Configs: map[int]gousb.ConfigDesc{
+			1: {
+				Number:     1,
+				Interfaces: interfaces,
+			},
+		},
+	}
+}
+
+var (
+	nanoSPlusDesc = deviceDesc(0x2C97, 0x5011, 1, 10, hidInterface(0, 3), hidInterface(1, 4))
+	nanoXDesc     = deviceDesc(0x2C97, 0x4015, 2, 5, hidInterface(0, 1))
+	// Ledger device which exposes only U2F interface
+	u2fOnlyDesc = deviceDesc(0x2C97, 0x1014, 1, 12, hidInterface(1, 4))
+	mouseDesc   = deviceDesc(0x046D, 0xC077, 1, 11, hidInterface(0, 1))
+
+	nanoSPlusInfo = device.USBDeviceInfo{
+		VendorID:   0x2C97,
+		ProductID:  0x5011,
+		Model:      device.MODEL_NANO_S_PLUS,
+		Interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB,
+		Bus:        1,
+		Address:    10,
+		// Interface #0 carries ADPU
+		ConfigNumber:     1,
+		InterfaceNumber:  0,
+		AltSettingNumber: 0,
+	}
+)
+
+// Expect Nano S Plus to be opened and closed, via interface #0 with endpoint #3
+func expectOpenNanoSPlus(ctrl *gomock.Controller, usbCtx *usb.MockContext) *usb.MockDevice {
+	usbDevice := expectNanoSPlusTarget(ctrl, nanoSPlusDesc)
+	usbCtx.EXPECT().OpenDevice(gousb.ID(0x2C97), gousb.ID(0x5011)).Return(usbDevice, nil)
+
+	return usbDevice
+}
+
+// Expect HID target of opened Nano S Plus to be set and closed
+func expectNanoSPlusTarget(ctrl *gomock.Controller, desc *gousb.DeviceDesc) *usb.MockDevice {
+	usbDevice := usb.NewMockDevice(ctrl)
+	config := usb.NewMockConfig(ctrl)
+	intf := usb.NewMockInterface(ctrl)
+	epIn := usb.NewMockInEndpoint(ctrl)
+	epOut := usb.NewMockOutEndpoint(ctrl)
+	reader := usb.NewMockStreamReader(ctrl)
+	writer := usb.NewMockStreamWriter(ctrl)
+
+	usbDevice.EXPECT().Descriptor().Return(desc).AnyTimes()
+	usbDevice.EXPECT().SetAutoDetach(true).Return(nil)
+	usbDevice.EXPECT().Config(1).Return(config, nil)
+	config.EXPECT().Interface(0, 0).Return(intf, nil)
+	intf.EXPECT().InEndpoint(3).Return(epIn, nil)
+	intf.EXPECT().OutEndpoint(3).Return(epOut, nil)
+	epIn.EXPECT().NewStream(hid.DEFAULT_ENDPOINT_STREAM_COUNT).Return(reader, nil)
+	epOut.EXPECT().NewStream(hid.DEFAULT_ENDPOINT_STREAM_COUNT).Return(writer, nil)
+	// Close
+	reader.EXPECT().Close().Return(nil)
+	writer.EXPECT().Close().Return(nil)
+	intf.EXPECT().Close().Return(nil)
+	config.EXPECT().Close().Return(nil)
+	usbDevice.EXPECT().Close().Return(nil)
+
+	return usbDevice
+}
+
+func TestModelFromProductID(t *testing.T) {
+	tests := []struct {
+		name       string
+		productID  uint16
+		model      device.Model
+		interfaces uint8
+	}{
+		{name: "NanoS", productID: 0x1011, model: device.MODEL_NANO_S, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB},
+		{name: "NanoX_WebUSB", productID: 0x4015, model: device.MODEL_NANO_X, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB | device.USB_INTERFACE_U2F},
+		{name: "NanoX_Keyboard", productID: 0x4013, model: device.MODEL_NANO_X, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_HID_KBD | device.USB_INTERFACE_WEBUSB},
+		{name: "NanoSPlus", productID: 0x5011, model: device.MODEL_NANO_S_PLUS, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB},
+		{name: "Stax", productID: 0x6011, model: device.MODEL_STAX, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB},
+		{name: "Flex", productID: 0x7011, model: device.MODEL_FLEX, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB},
+		{name: "Legacy_NanoX", productID: 0x0004, model: device.MODEL_NANO_X, interfaces: device.USB_INTERFACE_HID},
+		{name: "Unknown", productID: 0xA011, model: device.MODEL_UNKNOWN, interfaces: device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model, interfaces := device.ModelFromProductID(test.productID)
+
+			assert.Equal(t, test.model, model)
+			assert.Equal(t, test.interfaces, interfaces)
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	usbCtx := usb.NewMockContext(ctrl)
+	usbCtx.EXPECT().IterateDevices(gomock.Any()).DoAndReturn(func(reader func(desc *gousb.DeviceDesc)) error {
+		for _, desc := range []*gousb.DeviceDesc{mouseDesc, nanoSPlusDesc, u2fOnlyDesc, nanoXDesc} {
+			reader(desc)
+		}
+		return nil
+	})
+
+	infos, err := device.Discover(usbCtx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []device.USBDeviceInfo{
+		nanoSPlusInfo,
+		{
+			VendorID:         0x2C97,
+			ProductID:        0x4015,
+			Model:            device.MODEL_NANO_X,
+			Interfaces:       device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB | device.USB_INTERFACE_U2F,
+			Bus:              2,
+			Address:          5,
+			ConfigNumber:     1,
+			InterfaceNumber:  0,
+			AltSettingNumber: 0,
+		},
+	}, infos)
+	assert.Equal(t, "Nano S Plus [2c97:5011] bus 1, address 10", infos[0].String())
+}
+
+func TestDiscover_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	usbCtx := usb.NewMockContext(ctrl)
+	usbCtx.EXPECT().IterateDevices(gomock.Any()).Return(errors.New("libusb error"))
+
+	_, err := device.Discover(usbCtx)
+	assert.Error(t, err)
+}
+
+func TestOpenUSBDevice(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		usbCtx := usb.NewMockContext(ctrl)
+		expectOpenNanoSPlus(ctrl, usbCtx)
+
+		dev, err := device.OpenUSBDevice(usbCtx, nanoSPlusInfo, slog.Default())
+		assert.NoError(t, err)
+		assert.NoError(t, dev.Close())
+	})
+
+	t.Run("Success_SameModelByAddress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		usbCtx := device.NewMockUSBAddressContext(ctrl)
+		otherDesc := deviceDesc(0x2C97, 0x5011, 1, 20, hidInterface(0, 3), hidInterface(1, 4))
+		usbCtx.EXPECT().IterateDevices(gomock.Any()).DoAndReturn(func(reader func(desc *gousb.DeviceDesc)) error {
+			reader(nanoSPlusDesc)
+			reader(otherDesc)
+			return nil
+		})
+		usbCtx.EXPECT().OpenDeviceAt(1, 20).Return(expectNanoSPlusTarget(ctrl, otherDesc), nil)
+
+		infos, err := device.Discover(usbCtx)
+		assert.NoError(t, err)
+		assert.Len(t, infos, 2)
+
+		dev, err := device.OpenUSBDevice(usbCtx, infos[1], slog.Default())
+		assert.NoError(t, err)
+		assert.NoError(t, dev.Close())
+	})
+
+	t.Run("Error_NotFoundByAddress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		usbCtx := device.NewMockUSBAddressContext(ctrl)
+		usbCtx.EXPECT().OpenDeviceAt(1, 10).Return(nil, nil)
+
+		_, err := device.OpenUSBDevice(usbCtx, nanoSPlusInfo, slog.Default())
+		assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+	})
+
+	t.Run("Error_Mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		usbCtx := usb.NewMockContext(ctrl)
+		usbDevice := usb.NewMockDevice(ctrl)
+		otherDesc := deviceDesc(0x2C97, 0x5011, 1, 20, hidInterface(0, 3))
+
+		usbCtx.EXPECT().OpenDevice(gousb.ID(0x2C97), gousb.ID(0x5011)).Return(usbDevice, nil)
+		usbDevice.EXPECT().Descriptor().Return(otherDesc)
+		usbDevice.EXPECT().Close().Return(nil)
+
+		_, err := device.OpenUSBDevice(usbCtx, nanoSPlusInfo, slog.Default())
+		assert.ErrorIs(t, err, device.ErrDeviceMismatch)
+	})
+
+	t.Run("Error_NotFound", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		usbCtx := usb.NewMockContext(ctrl)
+		usbCtx.EXPECT().OpenDevice(gousb.ID(0x2C97), gousb.ID(0x5011)).Return(nil, nil)
+
+		_, err := device.OpenUSBDevice(usbCtx, nanoSPlusInfo, slog.Default())
+		assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+	})
+
+	t.Run("Error_SetAutoDetach", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		usbCtx := usb.NewMockContext(ctrl)
+		usbDevice := usb.NewMockDevice(ctrl)
+		detachErr := errors.New("not supported")
+
+		usbCtx.EXPECT().OpenDevice(gousb.ID(0x2C97), gousb.ID(0x5011)).Return(usbDevice, nil)
+		usbDevice.EXPECT().Descriptor().Return(nanoSPlusDesc).AnyTimes()
+		usbDevice.EXPECT().SetAutoDetach(true).Return(detachErr)
+		usbDevice.EXPECT().Close().Return(nil)
+
+		_, err := device.OpenUSBDevice(usbCtx, nanoSPlusInfo, slog.Default())
+		assert.ErrorIs(t, err, detachErr)
+	})
+}
+
+func TestOpenFirstUSBDevice_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	usbCtx := usb.NewMockContext(ctrl)
+	usbCtx.EXPECT().IterateDevices(gomock.Any()).DoAndReturn(func(reader func(desc *gousb.DeviceDesc)) error {
+		reader(mouseDesc)
+		return nil
+	})
+
+	_, _, err := device.OpenFirstUSBDevice(usbCtx, slog.Default())
+	assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+}
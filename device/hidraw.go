@@ -0,0 +1,214 @@
+package device
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Directory of hidraw devices in sysfs
+	HIDRAW_SYSFS_PATH = "/sys/class/hidraw"
+	// Directory of hidraw device nodes
+	HIDRAW_DEV_PATH = "/dev"
+
+	// Bus type of USB HID devices, in HID_ID of uevent
+	HID_BUS_USB uint16 = 0x0003
+)
+
+// Ledger device found in sysfs by `DiscoverHidraw`
+type HidrawDeviceInfo struct {
+	// Path of device node i.e. /dev/hidraw0
+	Path      string
+	VendorID  uint16
+	ProductID uint16
+	Model     Model
+	// Bitmask of USB interfaces enabled on device i.e. USB_INTERFACE_HID | USB_INTERFACE_WEBUSB
+	Interfaces      uint8
+	InterfaceNumber int
+	ProductName     string
+	SerialNumber    string
+}
+
+func (i HidrawDeviceInfo) String() string {
+	return fmt.Sprintf("%s [%04x:%04x] %s", i.Model, i.VendorID, i.ProductID, i.Path)
+}
+
+// Parse uevent of a HID device i.e.
+//
+//	HID_ID=0003:00002C97:00005011
+//	HID_NAME=Ledger Nano S Plus
+//	HID_PHYS=usb-0000:00:14.0-1/input0
+//	HID_UNIQ=0001
+func parseHIDUevent(r io.Reader) (busType uint16, info HidrawDeviceInfo, err error) {
+	info.InterfaceNumber = -1
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "HID_ID":
+			ids := strings.Split(value, ":")
+			if len(ids) != 3 {
+				return 0, info, fmt.Errorf("invalid HID_ID: %s", value)
+			}
+			var parsed [3]uint64
+			for i, id := range ids {
+				if parsed[i], err = strconv.ParseUint(id, 16, 32); err != nil {
+					return 0, info, fmt.Errorf("invalid HID_ID: %s: %w", value, err)
+				}
+			}
+			busType, info.VendorID, info.ProductID = uint16(parsed[0]), uint16(parsed[1]), uint16(parsed[2])
+		case "HID_NAME":
+			info.ProductName = value
+		case "HID_UNIQ":
+			info.SerialNumber = value
+		case "HID_PHYS":
+			// USB interface number is at the suffix i.e. "/input0"
+			if _, input, ok := strings.Cut(value, "/input"); ok {
+				if number, err := strconv.Atoi(input); err == nil {
+					info.InterfaceNumber = number
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, info, fmt.Errorf("unable to read uevent: %w", err)
+	}
+
+	return busType, info, nil
+}
+
+// List Ledger devices connected via USB, which have ADPU HID interface, by reading hidraw devices in sysfs
+//
+// `sysfsPath` and `devPath` are usually `HIDRAW_SYSFS_PATH` and `HIDRAW_DEV_PATH`
+func DiscoverHidraw(sysfsPath, devPath string) ([]HidrawDeviceInfo, error) {
+	entries, err := os.ReadDir(sysfsPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list hidraw devices: %w", err)
+	}
+
+	var infos []HidrawDeviceInfo
+	for _, entry := range entries {
+		uevent, err := os.Open(filepath.Join(sysfsPath, entry.Name(), "device", "uevent"))
+		if err != nil {
+			continue
+		}
+		busType, info, err := parseHIDUevent(uevent)
+		uevent.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse uevent of %s: %w", entry.Name(), err)
+		}
+		if busType != HID_BUS_USB || info.VendorID != LEDGER_VENDOR_ID || info.InterfaceNumber != ADPU_HID_INTERFACE_NUMBER {
+			continue
+		}
+		info.Path = filepath.Join(devPath, entry.Name())
+		info.Model, info.Interfaces = ModelFromProductID(info.ProductID)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Path < infos[j].Path
+	})
+
+	return infos, nil
+}
+
+// File of hidraw device node, or a pipe in tests
+type HidrawFile interface {
+	io.ReadWriteCloser
+	SetDeadline(t time.Time) error
+}
+
+type hidrawDevice struct {
+	file   HidrawFile
+	serial string
+}
+
+// Create a device that reads and writes HID reports via hidraw device node
+func NewHidrawDevice(file HidrawFile, serialNumber string) USBDevice {
+	return &hidrawDevice{
+		file:   file,
+		serial: serialNumber,
+	}
+}
+
+// Open a discovered Ledger device via its hidraw device node
+func OpenHidrawDevice(info HidrawDeviceInfo) (USBDevice, error) {
+	file, err := os.OpenFile(info.Path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open hidraw device %s: %w", info, err)
+	}
+
+	return NewHidrawDevice(file, info.SerialNumber), nil
+}
+
+// Abort blocking I/O when the context is done
+func (d *hidrawDevice) watch(ctx context.Context) (stop func() bool, err error) {
+	// Clear deadline set by previously cancelled context
+	if err := d.file.SetDeadline(time.Time{}); err != nil {
+		return nil, fmt.Errorf("unable to reset hidraw deadline: %w", err)
+	}
+
+	return context.AfterFunc(ctx, func() {
+		// Unblock pending read/write immediately
+		_ = d.file.SetDeadline(time.Unix(1, 0))
+	}), nil
+}
+
+func (d *hidrawDevice) contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+
+	return err
+}
+
+func (d *hidrawDevice) Read(ctx context.Context, data []byte) (int, error) {
+	stop, err := d.watch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer stop()
+
+	n, err := d.file.Read(data)
+	if err != nil {
+		return n, d.contextError(ctx, fmt.Errorf("unable to read HID report: %w", err))
+	}
+
+	return n, nil
+}
+
+func (d *hidrawDevice) Write(ctx context.Context, data []byte) (int, error) {
+	stop, err := d.watch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer stop()
+
+	// prepend report ID as 0, as Ledger doesn't use Report ID
+	n, err := d.file.Write(append([]byte{0x00}, data...))
+	// Report ID is not a part of data written
+	n = max(n-1, 0)
+	if err != nil {
+		return n, d.contextError(ctx, fmt.Errorf("unable to write HID report: %w", err))
+	}
+
+	return n, nil
+}
+
+func (d *hidrawDevice) SerialNumber() (string, error) {
+	return d.serial, nil
+}
+
+func (d *hidrawDevice) Close() error {
+	return d.file.Close()
+}
@@ -0,0 +1,168 @@
+package device_test
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeUevent(t *testing.T, sysfsPath, name, uevent string) {
+	dir := filepath.Join(sysfsPath, name, "device")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "uevent"), []byte(uevent), 0o644))
+}
+
+func TestDiscoverHidraw(t *testing.T) {
+	sysfsPath := t.TempDir()
+	// ADPU interface of Nano S Plus
+	writeUevent(t, sysfsPath, "hidraw3", "DRIVER=hid-generic\nHID_ID=0003:00002C97:00005011\nHID_NAME=Ledger Nano S Plus\nHID_PHYS=usb-0000:00:14.0-1/input0\nHID_UNIQ=0001\n")
+	// U2F interface of the same device
+	writeUevent(t, sysfsPath, "hidraw4", "DRIVER=hid-generic\nHID_ID=0003:00002C97:00005011\nHID_NAME=Ledger Nano S Plus\nHID_PHYS=usb-0000:00:14.0-1/input1\nHID_UNIQ=0001\n")
+	// Legacy Nano S firmware
+	writeUevent(t, sysfsPath, "hidraw1", "HID_ID=0003:00002C97:00000001\nHID_NAME=Ledger Nano S\nHID_PHYS=usb-0000:00:14.0-2/input0\nHID_UNIQ=\n")
+	// Nano X via Bluetooth
+	writeUevent(t, sysfsPath, "hidraw5", "HID_ID=0005:00002C97:00004011\nHID_NAME=Nano X\nHID_PHYS=00:11:22:33:44:55\n")
+	// Keyboard
+	writeUevent(t, sysfsPath, "hidraw0", "HID_ID=0003:0000046D:0000C31C\nHID_NAME=Logitech USB Keyboard\nHID_PHYS=usb-0000:00:14.0-3/input0\n")
+	// Entry without uevent
+	assert.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "hidraw6"), 0o755))
+
+	infos, err := device.DiscoverHidraw(sysfsPath, "/dev")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []device.HidrawDeviceInfo{
+		{
+			Path:            "/dev/hidraw1",
+			VendorID:        device.LEDGER_VENDOR_ID,
+			ProductID:       0x0001,
+			Model:           device.MODEL_NANO_S,
+			Interfaces:      device.USB_INTERFACE_HID,
+			InterfaceNumber: 0,
+			ProductName:     "Ledger Nano S",
+		},
+		{
+			Path:            "/dev/hidraw3",
+			VendorID:        device.LEDGER_VENDOR_ID,
+			ProductID:       0x5011,
+			Model:           device.MODEL_NANO_S_PLUS,
+			Interfaces:      device.USB_INTERFACE_HID | device.USB_INTERFACE_WEBUSB,
+			InterfaceNumber: 0,
+			ProductName:     "Ledger Nano S Plus",
+			SerialNumber:    "0001",
+		},
+	}, infos)
+}
+
+func TestDiscoverHidraw_Error(t *testing.T) {
+	sysfsPath := t.TempDir()
+
+	_, err := device.DiscoverHidraw(filepath.Join(sysfsPath, "missing"), "/dev")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	writeUevent(t, sysfsPath, "hidraw0", "HID_ID=0003:2C97\n")
+	_, err = device.DiscoverHidraw(sysfsPath, "/dev")
+	assert.EqualError(t, err, "unable to parse uevent of hidraw0: invalid HID_ID: 0003:2C97")
+}
+
+func TestHidrawDevice_ReadWrite(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	ctx := context.Background()
+	dev := device.NewHidrawDevice(client, "0001")
+	defer dev.Close()
+
+	command := []byte{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0xe0, 0x01, 0x00, 0x00, 0x00}
+	received := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 64)
+		n, err := server.Read(buf)
+		if err != nil {
+			return
+		}
+		received <- buf[:n]
+		_, _ = server.Write([]byte{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 0x02, 0x90, 0x00})
+	}()
+
+	n, err := dev.Write(ctx, command)
+	assert.NoError(t, err)
+	assert.Equal(t, len(command), n)
+	assert.Equal(t, append([]byte{0x00}, command...), <-received)
+
+	buf := make([]byte, 64)
+	n, err = dev.Read(ctx, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 0x02, 0x90, 0x00}, buf[:n])
+
+	serial, err := dev.SerialNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "0001", serial)
+}
+
+func TestHidrawDevice_Exchange(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	dev := device.NewHidrawDevice(client, "0001")
+	defer dev.Close()
+	proto := adpu.NewProtocol(dev, 0x0101, slog.Default())
+
+	go func() {
+		// Report ID followed by a frame of GET_CONFIGURATION command
+		buf := make([]byte, 65)
+		if _, err := server.Read(buf); err != nil {
+			return
+		}
+		response := make([]byte, 64)
+		copy(response, []byte{0x01, 0x01, 0x05, 0x00, 0x00, 0x00, 0x06, 0x01, 0x01, 0x0b, 0x02, 0x90, 0x00})
+		_, _ = server.Write(response)
+	}()
+
+	res, sw, err := proto.Send(context.Background(), 0xe0, 0x06, 0x00, 0x00, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, adpu.SW_OK, sw)
+	assert.Equal(t, []byte{0x01, 0x01, 0x0b, 0x02}, res)
+}
+
+func TestHidrawDevice_ContextCancelled(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	dev := device.NewHidrawDevice(client, "")
+	defer dev.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := dev.Read(ctx, make([]byte, 64))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Device is usable after cancellation
+	go func() {
+		_, _ = server.Write([]byte{0x01, 0x01})
+	}()
+	buf := make([]byte, 64)
+	n, err := dev.Read(context.Background(), buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x01}, buf[:n])
+}
+
+func TestHidrawDevice_Closed(t *testing.T) {
+	client, server := net.Pipe()
+	dev := device.NewHidrawDevice(client, "")
+	assert.NoError(t, server.Close())
+
+	_, err := dev.Read(context.Background(), make([]byte, 64))
+	assert.Error(t, err)
+	assert.NoError(t, dev.Close())
+}
+
+func TestOpenHidrawDevice_NotFound(t *testing.T) {
+	_, err := device.OpenHidrawDevice(device.HidrawDeviceInfo{Path: filepath.Join(t.TempDir(), "hidraw0")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./device/ble.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./device/ble.go -destination=./device/mock_ble.go -package=device
+//
+
+// Package device is a generated GoMock package.
+package device
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGATTPipe is a mock of GATTPipe interface.
+type MockGATTPipe struct {
+	ctrl     *gomock.Controller
+	recorder *MockGATTPipeMockRecorder
+}
+
+// MockGATTPipeMockRecorder is the mock recorder for MockGATTPipe.
+type MockGATTPipeMockRecorder struct {
+	mock *MockGATTPipe
+}
+
+// NewMockGATTPipe creates a new mock instance.
+func NewMockGATTPipe(ctrl *gomock.Controller) *MockGATTPipe {
+	mock := &MockGATTPipe{ctrl: ctrl}
+	mock.recorder = &MockGATTPipeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGATTPipe) EXPECT() *MockGATTPipeMockRecorder {
+	return m.recorder
+}
+
+// Notification mocks base method.
+func (m *MockGATTPipe) Notification(ctx context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notification", ctx)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Notification indicates an expected call of Notification.
+func (mr *MockGATTPipeMockRecorder) Notification(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notification", reflect.TypeOf((*MockGATTPipe)(nil).Notification), ctx)
+}
+
+// Write mocks base method.
+func (m *MockGATTPipe) Write(ctx context.Context, value []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", ctx, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockGATTPipeMockRecorder) Write(ctx, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockGATTPipe)(nil).Write), ctx, value)
+}
+
+// MockBLEDevice is a mock of BLEDevice interface.
+type MockBLEDevice struct {
+	ctrl     *gomock.Controller
+	recorder *MockBLEDeviceMockRecorder
+}
+
+// MockBLEDeviceMockRecorder is the mock recorder for MockBLEDevice.
+type MockBLEDeviceMockRecorder struct {
+	mock *MockBLEDevice
+}
+
+// NewMockBLEDevice creates a new mock instance.
+func NewMockBLEDevice(ctrl *gomock.Controller) *MockBLEDevice {
+	mock := &MockBLEDevice{ctrl: ctrl}
+	mock.recorder = &MockBLEDeviceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBLEDevice) EXPECT() *MockBLEDeviceMockRecorder {
+	return m.recorder
+}
+
+// MTU mocks base method.
+func (m *MockBLEDevice) MTU(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MTU", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MTU indicates an expected call of MTU.
+func (mr *MockBLEDeviceMockRecorder) MTU(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MTU", reflect.TypeOf((*MockBLEDevice)(nil).MTU), ctx)
+}
+
+// Read mocks base method.
+func (m *MockBLEDevice) Read(ctx context.Context, data []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockBLEDeviceMockRecorder) Read(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockBLEDevice)(nil).Read), ctx, data)
+}
+
+// Write mocks base method.
+func (m *MockBLEDevice) Write(ctx context.Context, data []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockBLEDeviceMockRecorder) Write(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockBLEDevice)(nil).Write), ctx, data)
+}
@@ -68,3 +68,85 @@ func (mr *MockDeviceMockRecorder) Write(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockDevice)(nil).Write), ctx, data)
 }
+
+// MockUSBDevice is a mock of USBDevice interface.
+type MockUSBDevice struct {
+	ctrl     *gomock.Controller
+	recorder *MockUSBDeviceMockRecorder
+}
+
+// MockUSBDeviceMockRecorder is the mock recorder for MockUSBDevice.
+type MockUSBDeviceMockRecorder struct {
+	mock *MockUSBDevice
+}
+
+// NewMockUSBDevice creates a new mock instance.
+func NewMockUSBDevice(ctrl *gomock.Controller) *MockUSBDevice {
+	mock := &MockUSBDevice{ctrl: ctrl}
+	mock.recorder = &MockUSBDeviceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUSBDevice) EXPECT() *MockUSBDeviceMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockUSBDevice) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockUSBDeviceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockUSBDevice)(nil).Close))
+}
+
+// Read mocks base method.
+func (m *MockUSBDevice) Read(ctx context.Context, data []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockUSBDeviceMockRecorder) Read(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUSBDevice)(nil).Read), ctx, data)
+}
+
+// SerialNumber mocks base method.
+func (m *MockUSBDevice) SerialNumber() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SerialNumber")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SerialNumber indicates an expected call of SerialNumber.
+func (mr *MockUSBDeviceMockRecorder) SerialNumber() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SerialNumber", reflect.TypeOf((*MockUSBDevice)(nil).SerialNumber))
+}
+
+// Write mocks base method.
+func (m *MockUSBDevice) Write(ctx context.Context, data []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockUSBDeviceMockRecorder) Write(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUSBDevice)(nil).Write), ctx, data)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./device/usb.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./device/usb.go -destination=./device/mock_usb.go -package=device
+//
+
+// Package device is a generated GoMock package.
+package device
+
+import (
+	reflect "reflect"
+
+	gousb "github.com/google/gousb"
+	usb "github.com/ntchjb/gohid/usb"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUSBAddressContext is a mock of USBAddressContext interface.
+type MockUSBAddressContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockUSBAddressContextMockRecorder
+}
+
+// MockUSBAddressContextMockRecorder is the mock recorder for MockUSBAddressContext.
+type MockUSBAddressContextMockRecorder struct {
+	mock *MockUSBAddressContext
+}
+
+// NewMockUSBAddressContext creates a new mock instance.
+func NewMockUSBAddressContext(ctrl *gomock.Controller) *MockUSBAddressContext {
+	mock := &MockUSBAddressContext{ctrl: ctrl}
+	mock.recorder = &MockUSBAddressContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUSBAddressContext) EXPECT() *MockUSBAddressContextMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockUSBAddressContext) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockUSBAddressContextMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockUSBAddressContext)(nil).Close))
+}
+
+// IterateDevices mocks base method.
+func (m *MockUSBAddressContext) IterateDevices(reader func(*gousb.DeviceDesc)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateDevices", reader)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateDevices indicates an expected call of IterateDevices.
+func (mr *MockUSBAddressContextMockRecorder) IterateDevices(reader any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateDevices", reflect.TypeOf((*MockUSBAddressContext)(nil).IterateDevices), reader)
+}
+
+// OpenDevice mocks base method.
+func (m *MockUSBAddressContext) OpenDevice(vid, pid gousb.ID) (usb.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDevice", vid, pid)
+	ret0, _ := ret[0].(usb.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDevice indicates an expected call of OpenDevice.
+func (mr *MockUSBAddressContextMockRecorder) OpenDevice(vid, pid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDevice", reflect.TypeOf((*MockUSBAddressContext)(nil).OpenDevice), vid, pid)
+}
+
+// OpenDeviceAt mocks base method.
+func (m *MockUSBAddressContext) OpenDeviceAt(bus, address int) (usb.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDeviceAt", bus, address)
+	ret0, _ := ret[0].(usb.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDeviceAt indicates an expected call of OpenDeviceAt.
+func (mr *MockUSBAddressContextMockRecorder) OpenDeviceAt(bus, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDeviceAt", reflect.TypeOf((*MockUSBAddressContext)(nil).OpenDeviceAt), bus, address)
+}
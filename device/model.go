@@ -0,0 +1,52 @@
+package device
+
+// Model of Ledger device
+type Model uint8
+
+const (
+	MODEL_UNKNOWN Model = iota
+	MODEL_BLUE
+	MODEL_NANO_S
+	MODEL_NANO_S_PLUS
+	MODEL_NANO_X
+	MODEL_STAX
+	MODEL_FLEX
+)
+
+// Mask of target ID, which identifies the device model
+const TARGET_ID_MODEL_MASK uint32 = 0xFFFF0000
+
+var (
+	ModelName = map[Model]string{
+		MODEL_UNKNOWN:     "Unknown",
+		MODEL_BLUE:        "Blue",
+		MODEL_NANO_S:      "Nano S",
+		MODEL_NANO_S_PLUS: "Nano S Plus",
+		MODEL_NANO_X:      "Nano X",
+		MODEL_STAX:        "Stax",
+		MODEL_FLEX:        "Flex",
+	}
+
+	targetIDModels = map[uint32]Model{
+		0x31000000: MODEL_BLUE,
+		0x31010000: MODEL_BLUE,
+		0x31100000: MODEL_NANO_S,
+		0x33000000: MODEL_NANO_X,
+		0x33100000: MODEL_NANO_S_PLUS,
+		0x33200000: MODEL_STAX,
+		0x33300000: MODEL_FLEX,
+	}
+)
+
+func (m Model) String() string {
+	if name, ok := ModelName[m]; ok {
+		return name
+	}
+
+	return ModelName[MODEL_UNKNOWN]
+}
+
+// Get device model from target ID returned by GET_VERSION i.e. 0x33000004 for Nano X
+func ModelFromTargetID(targetID uint32) Model {
+	return targetIDModels[targetID&TARGET_ID_MODEL_MASK]
+}
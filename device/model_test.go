@@ -0,0 +1,34 @@
+package device_test
+
+import (
+	"testing"
+
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelFromTargetID(t *testing.T) {
+	tests := []struct {
+		name     string
+		targetID uint32
+		model    device.Model
+		str      string
+	}{
+		{name: "Blue", targetID: 0x31010004, model: device.MODEL_BLUE, str: "Blue"},
+		{name: "NanoS", targetID: 0x31100004, model: device.MODEL_NANO_S, str: "Nano S"},
+		{name: "NanoSPlus", targetID: 0x33100004, model: device.MODEL_NANO_S_PLUS, str: "Nano S Plus"},
+		{name: "NanoX", targetID: 0x33000004, model: device.MODEL_NANO_X, str: "Nano X"},
+		{name: "Stax", targetID: 0x33200004, model: device.MODEL_STAX, str: "Stax"},
+		{name: "Flex", targetID: 0x33300004, model: device.MODEL_FLEX, str: "Flex"},
+		{name: "Unknown", targetID: 0x01000001, model: device.MODEL_UNKNOWN, str: "Unknown"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model := device.ModelFromTargetID(test.targetID)
+
+			assert.Equal(t, test.model, model)
+			assert.Equal(t, test.str, model.String())
+		})
+	}
+}
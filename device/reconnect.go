@@ -0,0 +1,299 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ntchjb/gohid/usb"
+)
+
+const (
+	DEFAULT_RECONNECT_INTERVAL = time.Second
+)
+
+var (
+	// Device is disconnected during I/O. The command may be partially sent, so it should not be retried blindly
+	ErrDisconnected = errors.New("device is disconnected")
+	ErrClosed       = errors.New("device is closed")
+)
+
+// Open a connection to device
+type Opener func(ctx context.Context) (USBDevice, error)
+
+// Criteria of device to be opened by `NewUSBOpener`. Zero value matches any Ledger device
+type USBDeviceFilter struct {
+	Model        Model
+	SerialNumber string
+}
+
+// Create an opener that discovers Ledger devices via USB, and opens the first device matching the filter
+func NewUSBOpener(usbCtx usb.Context, filter USBDeviceFilter, logger *slog.Logger) Opener {
+	return func(ctx context.Context) (USBDevice, error) {
+		infos, err := Discover(usbCtx)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if filter.Model != MODEL_UNKNOWN && info.Model != filter.Model {
+				continue
+			}
+			device, err := OpenUSBDevice(usbCtx, info, logger)
+			if err != nil {
+				logger.Debug("Unable to open discovered device", "device", info, "err", err)
+				continue
+			}
+			if filter.SerialNumber == "" {
+				return device, nil
+			}
+			serial, err := device.SerialNumber()
+			if err == nil && serial == filter.SerialNumber {
+				return device, nil
+			}
+			if err := device.Close(); err != nil {
+				logger.Error("unable to close unmatched device", "device", info, "err", err)
+			}
+		}
+
+		return nil, fmt.Errorf("no device matches model %s, serial number %q: %w", filter.Model, filter.SerialNumber, ErrDeviceNotFound)
+	}
+}
+
+type EventType uint8
+
+const (
+	EVENT_CONNECTED EventType = iota
+	EVENT_DISCONNECTED
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EVENT_CONNECTED:
+		return "connected"
+	case EVENT_DISCONNECTED:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// Connection state change of a reconnecting device
+type Event struct {
+	Type EventType
+	// Error that causes disconnection, if any
+	Err error
+}
+
+type ReconnectOption func(d *reconnectingDevice)
+
+// Send connect/disconnect events to the channel. Events are dropped if the channel is full
+func WithEvents(events chan<- Event) ReconnectOption {
+	return func(d *reconnectingDevice) {
+		d.events = events
+	}
+}
+
+// Interval between attempts to open device while it is disconnected
+func WithReconnectInterval(interval time.Duration) ReconnectOption {
+	return func(d *reconnectingDevice) {
+		d.reconnectInterval = interval
+	}
+}
+
+type reconnectingDevice struct {
+	open   Opener
+	logger *slog.Logger
+
+	events            chan<- Event
+	reconnectInterval time.Duration
+
+	mu     sync.Mutex
+	device USBDevice
+	closed bool
+	// Whether frames of a command are being written, so the next write is not the first frame
+	writing bool
+}
+
+// Create a device that reconnects when the underlying device is unplugged, or re-enumerated i.e. after switching app
+//
+// Device is opened lazily, and reopened by `open` until the context is done, when a read or write fails.
+// A write is retried on the new connection only if it is the first frame of a command and no byte was sent,
+// otherwise `ErrDisconnected` is returned as the device may have received a partial command.
+func NewReconnectingDevice(open Opener, logger *slog.Logger, opts ...ReconnectOption) USBDevice {
+	d := &reconnectingDevice{
+		open:              open,
+		logger:            logger,
+		reconnectInterval: DEFAULT_RECONNECT_INTERVAL,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+func (d *reconnectingDevice) emit(event Event) {
+	if d.events == nil {
+		return
+	}
+	select {
+	case d.events <- event:
+	default:
+		d.logger.Debug("Event channel is full, drop event", "type", event.Type)
+	}
+}
+
+// Get current device, or open a new one until the context is done
+func (d *reconnectingDevice) connected(ctx context.Context) (USBDevice, error) {
+	for {
+		d.mu.Lock()
+		device, closed := d.device, d.closed
+		d.mu.Unlock()
+		if closed {
+			return nil, ErrClosed
+		}
+		if device != nil {
+			return device, nil
+		}
+
+		device, err := d.open(ctx)
+		if err == nil {
+			d.mu.Lock()
+			if d.closed {
+				d.mu.Unlock()
+				return nil, errors.Join(ErrClosed, device.Close())
+			}
+			d.device = device
+			d.writing = false
+			d.mu.Unlock()
+
+			d.logger.Debug("Device is connected")
+			d.emit(Event{Type: EVENT_CONNECTED})
+			return device, nil
+		}
+		d.logger.Debug("Unable to open device, retrying", "err", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("unable to reconnect: %w: %w", ctx.Err(), err)
+		case <-time.After(d.reconnectInterval):
+		}
+	}
+}
+
+// Drop the device if it is still the current one
+func (d *reconnectingDevice) disconnect(device USBDevice, cause error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.device != device {
+		return
+	}
+	d.device = nil
+	d.writing = false
+	if err := device.Close(); err != nil {
+		d.logger.Debug("Unable to close disconnected device", "err", err)
+	}
+	d.logger.Debug("Device is disconnected", "err", cause)
+	d.emit(Event{Type: EVENT_DISCONNECTED, Err: cause})
+}
+
+func (d *reconnectingDevice) Write(ctx context.Context, data []byte) (int, error) {
+	device, err := d.connected(ctx)
+	if err != nil {
+		return 0, err
+	}
+	d.mu.Lock()
+	firstFrame := !d.writing
+	d.mu.Unlock()
+
+	n, err := device.Write(ctx, data)
+	if err != nil {
+		// Cancellation does not mean device is gone
+		if ctx.Err() != nil {
+			return n, err
+		}
+		d.disconnect(device, err)
+		if n > 0 || !firstFrame {
+			return n, fmt.Errorf("%w: %w", ErrDisconnected, err)
+		}
+
+		// Nothing of this command has been sent, so it is safe to send it to the new connection
+		d.logger.Debug("Retry writing the first frame after reconnecting", "err", err)
+		device, err = d.connected(ctx)
+		if err != nil {
+			return 0, err
+		}
+		n, err = device.Write(ctx, data)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.disconnect(device, err)
+			}
+			return n, fmt.Errorf("%w: %w", ErrDisconnected, err)
+		}
+	}
+
+	d.mu.Lock()
+	if d.device == device {
+		d.writing = true
+	}
+	d.mu.Unlock()
+
+	return n, nil
+}
+
+func (d *reconnectingDevice) Read(ctx context.Context, data []byte) (int, error) {
+	d.mu.Lock()
+	device := d.device
+	closed := d.closed
+	d.writing = false
+	d.mu.Unlock()
+
+	if closed {
+		return 0, ErrClosed
+	}
+	// Response of the command is lost with the previous connection
+	if device == nil {
+		return 0, ErrDisconnected
+	}
+
+	n, err := device.Read(ctx, data)
+	if err != nil {
+		if ctx.Err() != nil {
+			return n, err
+		}
+		d.disconnect(device, err)
+		return n, fmt.Errorf("%w: %w", ErrDisconnected, err)
+	}
+
+	return n, nil
+}
+
+func (d *reconnectingDevice) SerialNumber() (string, error) {
+	d.mu.Lock()
+	device := d.device
+	d.mu.Unlock()
+
+	if device == nil {
+		return "", ErrDisconnected
+	}
+
+	return device.SerialNumber()
+}
+
+func (d *reconnectingDevice) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	if d.device == nil {
+		return nil
+	}
+	device := d.device
+	d.device = nil
+
+	return device.Close()
+}
@@ -0,0 +1,253 @@
+package device_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/ntchjb/gohid/usb"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var errUnplugged = errors.New("LIBUSB_ERROR_NO_DEVICE")
+
+// Opener that returns given devices in order, where nil device means device is not plugged in
+func sequenceOpener(devices ...device.USBDevice) device.Opener {
+	return func(ctx context.Context) (device.USBDevice, error) {
+		if len(devices) == 0 {
+			return nil, device.ErrDeviceNotFound
+		}
+		dev := devices[0]
+		devices = devices[1:]
+		if dev == nil {
+			return nil, device.ErrDeviceNotFound
+		}
+		return dev, nil
+	}
+}
+
+func drainEvents(events chan device.Event) []device.EventType {
+	var types []device.EventType
+	for {
+		select {
+		case event := <-events:
+			types = append(types, event.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func newTestReconnectingDevice(events chan device.Event, devices ...device.USBDevice) device.USBDevice {
+	return device.NewReconnectingDevice(sequenceOpener(devices...), slog.Default(), device.WithEvents(events), device.WithReconnectInterval(time.Millisecond))
+}
+
+func TestReconnectingDevice(t *testing.T) {
+	ctx := context.Background()
+	frame := []byte{0x01, 0x01, 0x05}
+
+	t.Run("Success_LazyConnect", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		events := make(chan device.Event, 10)
+		dev := device.NewMockUSBDevice(ctrl)
+		dev.EXPECT().Write(ctx, frame).Return(len(frame), nil)
+		dev.EXPECT().Read(ctx, gomock.Any()).Return(64, nil)
+		dev.EXPECT().SerialNumber().Return("0001", nil)
+		dev.EXPECT().Close().Return(nil)
+		d := newTestReconnectingDevice(events, nil, dev)
+
+		n, err := d.Write(ctx, frame)
+		assert.NoError(t, err)
+		assert.Equal(t, len(frame), n)
+		n, err = d.Read(ctx, make([]byte, 64))
+		assert.NoError(t, err)
+		assert.Equal(t, 64, n)
+		serial, err := d.SerialNumber()
+		assert.NoError(t, err)
+		assert.Equal(t, "0001", serial)
+		assert.NoError(t, d.Close())
+
+		assert.Equal(t, []device.EventType{device.EVENT_CONNECTED}, drainEvents(events))
+		_, err = d.Write(ctx, frame)
+		assert.ErrorIs(t, err, device.ErrClosed)
+		_, err = d.Read(ctx, make([]byte, 64))
+		assert.ErrorIs(t, err, device.ErrClosed)
+	})
+
+	t.Run("Success_RetryFirstFrame", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		events := make(chan device.Event, 10)
+		unplugged := device.NewMockUSBDevice(ctrl)
+		replugged := device.NewMockUSBDevice(ctrl)
+		gomock.InOrder(
+			unplugged.EXPECT().Write(ctx, frame).Return(0, errUnplugged),
+			unplugged.EXPECT().Close().Return(nil),
+			replugged.EXPECT().Write(ctx, frame).Return(len(frame), nil),
+			replugged.EXPECT().Read(ctx, gomock.Any()).Return(64, nil),
+		)
+		d := newTestReconnectingDevice(events, unplugged, nil, nil, replugged)
+
+		n, err := d.Write(ctx, frame)
+		assert.NoError(t, err)
+		assert.Equal(t, len(frame), n)
+		_, err = d.Read(ctx, make([]byte, 64))
+		assert.NoError(t, err)
+
+		assert.Equal(t, []device.EventType{device.EVENT_CONNECTED, device.EVENT_DISCONNECTED, device.EVENT_CONNECTED}, drainEvents(events))
+	})
+
+	t.Run("Error_PartiallySentFrame", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		events := make(chan device.Event, 10)
+		unplugged := device.NewMockUSBDevice(ctrl)
+		gomock.InOrder(
+			unplugged.EXPECT().Write(ctx, frame).Return(1, errUnplugged),
+			unplugged.EXPECT().Close().Return(nil),
+		)
+		d := newTestReconnectingDevice(events, unplugged)
+
+		_, err := d.Write(ctx, frame)
+		assert.ErrorIs(t, err, device.ErrDisconnected)
+		assert.ErrorIs(t, err, errUnplugged)
+		assert.Equal(t, []device.EventType{device.EVENT_CONNECTED, device.EVENT_DISCONNECTED}, drainEvents(events))
+	})
+
+	t.Run("Error_SecondFrame", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		events := make(chan device.Event, 10)
+		unplugged := device.NewMockUSBDevice(ctrl)
+		replugged := device.NewMockUSBDevice(ctrl)
+		gomock.InOrder(
+			unplugged.EXPECT().Write(ctx, frame).Return(len(frame), nil),
+			unplugged.EXPECT().Write(ctx, frame).Return(0, errUnplugged),
+			unplugged.EXPECT().Close().Return(nil),
+			// Next command is sent to the new connection
+			replugged.EXPECT().Write(ctx, frame).Return(len(frame), nil),
+		)
+		d := newTestReconnectingDevice(events, unplugged, replugged)
+
+		_, err := d.Write(ctx, frame)
+		assert.NoError(t, err)
+		_, err = d.Write(ctx, frame)
+		assert.ErrorIs(t, err, device.ErrDisconnected)
+		_, err = d.Write(ctx, frame)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []device.EventType{device.EVENT_CONNECTED, device.EVENT_DISCONNECTED, device.EVENT_CONNECTED}, drainEvents(events))
+	})
+
+	t.Run("Error_Read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		events := make(chan device.Event, 10)
+		unplugged := device.NewMockUSBDevice(ctrl)
+		replugged := device.NewMockUSBDevice(ctrl)
+		gomock.InOrder(
+			unplugged.EXPECT().Write(ctx, frame).Return(len(frame), nil),
+			unplugged.EXPECT().Read(ctx, gomock.Any()).Return(0, errUnplugged),
+			unplugged.EXPECT().Close().Return(nil),
+			replugged.EXPECT().Write(ctx, frame).Return(len(frame), nil),
+		)
+		d := newTestReconnectingDevice(events, unplugged, replugged)
+
+		_, err := d.Write(ctx, frame)
+		assert.NoError(t, err)
+		_, err = d.Read(ctx, make([]byte, 64))
+		assert.ErrorIs(t, err, device.ErrDisconnected)
+		// Response is lost, so read does not reconnect
+		_, err = d.Read(ctx, make([]byte, 64))
+		assert.ErrorIs(t, err, device.ErrDisconnected)
+		_, err = d.Write(ctx, frame)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []device.EventType{device.EVENT_CONNECTED, device.EVENT_DISCONNECTED, device.EVENT_CONNECTED}, drainEvents(events))
+	})
+
+	t.Run("Error_ContextCancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		events := make(chan device.Event, 10)
+		dev := device.NewMockUSBDevice(ctrl)
+		cancelCtx, cancel := context.WithCancel(ctx)
+		dev.EXPECT().Write(cancelCtx, frame).DoAndReturn(func(ctx context.Context, data []byte) (int, error) {
+			cancel()
+			return 0, context.Canceled
+		})
+		d := newTestReconnectingDevice(events, dev)
+
+		_, err := d.Write(cancelCtx, frame)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, device.ErrDisconnected)
+		// Device is kept
+		assert.Equal(t, []device.EventType{device.EVENT_CONNECTED}, drainEvents(events))
+	})
+
+	t.Run("Error_ReconnectTimeout", func(t *testing.T) {
+		events := make(chan device.Event, 10)
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		d := newTestReconnectingDevice(events)
+
+		_, err := d.Write(timeoutCtx, frame)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+		assert.Empty(t, drainEvents(events))
+	})
+}
+
+func TestNewUSBOpener_SerialNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		serial string
+		err    error
+	}{
+		{
+			name:   "Success",
+			serial: "0001",
+		},
+		{
+			name:   "Error_SerialNumberMismatch",
+			serial: "0002",
+			err:    device.ErrDeviceNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			usbCtx := usb.NewMockContext(ctrl)
+			usbCtx.EXPECT().IterateDevices(gomock.Any()).DoAndReturn(func(reader func(desc *gousb.DeviceDesc)) error {
+				reader(nanoSPlusDesc)
+				return nil
+			})
+			usbDevice := expectOpenNanoSPlus(ctrl, usbCtx)
+			usbDevice.EXPECT().SerialNumber().Return("0001", nil)
+			open := device.NewUSBOpener(usbCtx, device.USBDeviceFilter{Model: device.MODEL_NANO_S_PLUS, SerialNumber: test.serial}, slog.Default())
+
+			dev, err := open(context.Background())
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, dev.Close())
+		})
+	}
+}
+
+func TestNewUSBOpener_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	usbCtx := usb.NewMockContext(ctrl)
+	usbCtx.EXPECT().IterateDevices(gomock.Any()).DoAndReturn(func(reader func(desc *gousb.DeviceDesc)) error {
+		reader(nanoSPlusDesc)
+		return nil
+	})
+	open := device.NewUSBOpener(usbCtx, device.USBDeviceFilter{Model: device.MODEL_NANO_X}, slog.Default())
+
+	_, err := open(context.Background())
+	assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+}
@@ -0,0 +1,149 @@
+package device
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	// Default address of Speculos ADPU socket
+	SPECULOS_DEFAULT_ADDRESS = "127.0.0.1:9999"
+	// Length of the length prefix of ADPU messages sent via TCP
+	TCP_LENGTH_PREFIX_SIZE = 4
+	// Length of status word appended after response data
+	TCP_SW_SIZE = 2
+)
+
+var (
+	ErrResponseBufferTooSmall = errors.New("response buffer is too small")
+)
+
+type tcpDevice struct {
+	conn net.Conn
+
+	// Response being read, which is kept if reading is aborted by context,
+	// so that the next read resumes it instead of treating its body as a length prefix
+	prefix     [TCP_LENGTH_PREFIX_SIZE]byte
+	prefixRead int
+	// Response data and SW read so far, or nil if the response is discarded
+	res []byte
+	// Number of bytes left of a response which is longer than read buffer and being discarded
+	discard int64
+}
+
+// Create a device that talks to Speculos, or Ledger emulators, via its ADPU TCP socket
+//
+// Each command is sent as [length (4 bytes)..., ADPU command...]
+// and each response is received as [length (4 bytes)..., data..., SW (2 bytes)],
+// where length of the response does not include SW.
+//
+// There is no HID framing on this transport, so it should be used with a protocol
+// created with `adpu.WithStreamTransport()` option.
+func NewTCPDevice(conn net.Conn) Device {
+	return &tcpDevice{
+		conn: conn,
+	}
+}
+
+// Abort blocking I/O when the context is done
+func (d *tcpDevice) watch(ctx context.Context) (stop func() bool, err error) {
+	// Clear deadline set by previously cancelled context
+	if err := d.conn.SetDeadline(time.Time{}); err != nil {
+		return nil, fmt.Errorf("unable to reset connection deadline: %w", err)
+	}
+
+	return context.AfterFunc(ctx, func() {
+		// Unblock pending read/write immediately
+		_ = d.conn.SetDeadline(time.Unix(1, 0))
+	}), nil
+}
+
+func (d *tcpDevice) contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+
+	return err
+}
+
+// Read a whole ADPU response, including SW, into `data`
+//
+// If reading is aborted in the middle of a response, the next read resumes it.
+// Response longer than `data` is read and discarded, then `ErrResponseBufferTooSmall` is returned,
+// so that the stream stays in sync with the next response.
+func (d *tcpDevice) Read(ctx context.Context, data []byte) (n int, err error) {
+	stop, err := d.watch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer stop()
+
+	resumed := d.prefixRead == TCP_LENGTH_PREFIX_SIZE
+	if !resumed {
+		read, err := io.ReadFull(d.conn, d.prefix[d.prefixRead:])
+		d.prefixRead += read
+		if err != nil {
+			return 0, d.contextError(ctx, fmt.Errorf("unable to read response length: %w", err))
+		}
+	}
+	length := int(binary.BigEndian.Uint32(d.prefix[:])) + TCP_SW_SIZE
+	if !resumed {
+		if length > len(data) {
+			d.discard = int64(length)
+		} else {
+			d.res = make([]byte, 0, length)
+		}
+	}
+
+	if d.res == nil {
+		discarded, err := io.CopyN(io.Discard, d.conn, d.discard)
+		d.discard -= discarded
+		if err != nil {
+			return 0, d.contextError(ctx, fmt.Errorf("unable to discard response of %d bytes: %w", length, err))
+		}
+		d.prefixRead = 0
+
+		return 0, fmt.Errorf("response length is %d, but buffer size is %d: %w", length, len(data), ErrResponseBufferTooSmall)
+	}
+
+	read, err := io.ReadFull(d.conn, d.res[len(d.res):cap(d.res)])
+	d.res = d.res[:len(d.res)+read]
+	if err != nil {
+		return 0, d.contextError(ctx, fmt.Errorf("unable to read response data: %w", err))
+	}
+	res := d.res
+	d.res, d.prefixRead = nil, 0
+	// Buffer of resumed read may be smaller than the one the response was started with
+	if length > len(data) {
+		return 0, fmt.Errorf("response length is %d, but buffer size is %d: %w", length, len(data), ErrResponseBufferTooSmall)
+	}
+
+	return copy(data, res), nil
+}
+
+// Write a whole ADPU command, prefixed by its length
+func (d *tcpDevice) Write(ctx context.Context, data []byte) (n int, err error) {
+	stop, err := d.watch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer stop()
+
+	buf := make([]byte, TCP_LENGTH_PREFIX_SIZE, TCP_LENGTH_PREFIX_SIZE+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+
+	n, err = d.conn.Write(buf)
+	// Report number of command bytes written, excluding the length prefix
+	n = max(0, n-TCP_LENGTH_PREFIX_SIZE)
+	if err != nil {
+		return n, d.contextError(ctx, fmt.Errorf("unable to write command: %w", err))
+	}
+
+	return n, nil
+}
@@ -0,0 +1,142 @@
+package device_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPDevice_Exchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  []byte
+		response []byte
+		bufSize  int
+		timeout  time.Duration
+		res      []byte
+		err      error
+	}{
+		{
+			name:    "Success",
+			command: []byte{0xe0, 0x06, 0x00, 0x00, 0x00},
+			response: []byte{
+				0x00, 0x00, 0x00, 0x04, // response length, excluding SW
+				0x01, 0x01, 0x0B, 0x02,
+				0x90, 0x00,
+			},
+			bufSize: 16,
+			timeout: time.Second,
+			res:     []byte{0x01, 0x01, 0x0B, 0x02, 0x90, 0x00},
+			err:     nil,
+		},
+		{
+			name:    "Error_ResponseBufferTooSmall",
+			command: []byte{0xe0, 0x06, 0x00, 0x00, 0x00},
+			response: []byte{
+				0x00, 0x00, 0x00, 0x04, // response length, excluding SW
+				0x01, 0x01, 0x0B, 0x02,
+				0x90, 0x00,
+			},
+			bufSize: 4,
+			timeout: time.Second,
+			res:     nil,
+			err:     device.ErrResponseBufferTooSmall,
+		},
+		{
+			name:     "Error_ContextDeadlineExceeded",
+			command:  []byte{0xe0, 0x06, 0x00, 0x00, 0x00},
+			response: nil,
+			bufSize:  16,
+			timeout:  50 * time.Millisecond,
+			res:      nil,
+			err:      context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			received := make(chan []byte, 1)
+			go func() {
+				var length [4]byte
+				if _, err := io.ReadFull(server, length[:]); err != nil {
+					return
+				}
+				command := make([]byte, binary.BigEndian.Uint32(length[:]))
+				if _, err := io.ReadFull(server, command); err != nil {
+					return
+				}
+				received <- command
+				if test.response != nil {
+					_, _ = server.Write(test.response)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+			dev := device.NewTCPDevice(client)
+
+			n, err := dev.Write(ctx, test.command)
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.command), n)
+			assert.Equal(t, test.command, <-received)
+
+			buf := make([]byte, test.bufSize)
+			n, err = dev.Read(ctx, buf)
+			assert.ErrorIs(t, err, test.err)
+			if err == nil {
+				assert.Equal(t, test.res, buf[:n])
+			}
+		})
+	}
+}
+
+func TestTCPDevice_Read_Resync(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	responses := make(chan []byte, 3)
+	go func() {
+		for response := range responses {
+			if _, err := server.Write(response); err != nil {
+				return
+			}
+		}
+	}()
+	dev := device.NewTCPDevice(client)
+	ctx := context.Background()
+	buf := make([]byte, 8)
+
+	// Response longer than buffer is discarded, so the next response is read from its length prefix
+	responses <- []byte{0x00, 0x00, 0x00, 0x08, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x90, 0x00}
+	_, err := dev.Read(ctx, buf)
+	assert.ErrorIs(t, err, device.ErrResponseBufferTooSmall)
+
+	responses <- []byte{0x00, 0x00, 0x00, 0x02, 0xA1, 0xA2, 0x90, 0x00}
+	n, err := dev.Read(ctx, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xA1, 0xA2, 0x90, 0x00}, buf[:n])
+
+	// Read aborted in the middle of a response is resumed by the next read
+	responses <- []byte{0x00, 0x00, 0x00, 0x02, 0xB1}
+	abortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = dev.Read(abortCtx, buf)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	responses <- []byte{0xB2, 0x90, 0x00}
+	close(responses)
+	n, err = dev.Read(ctx, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xB1, 0xB2, 0x90, 0x00}, buf[:n])
+}
@@ -0,0 +1,63 @@
+package device
+
+import (
+	"github.com/google/gousb"
+	"github.com/ntchjb/gohid/usb"
+)
+
+// USB context which also opens a device by its bus and address,
+// so that one of multiple devices with the same vendor ID and product ID can be opened
+type USBAddressContext interface {
+	usb.Context
+	// Open a USB device at given bus and address. It returns nil device if not found.
+	OpenDeviceAt(bus, address int) (usb.Device, error)
+}
+
+type gousbContext struct {
+	ctx *gousb.Context
+}
+
+// Create USB context backed by libusb, which is used by `OpenUSBDevice` to open devices by bus and address
+func NewUSBContext() USBAddressContext {
+	return &gousbContext{
+		ctx: gousb.NewContext(),
+	}
+}
+
+func (g *gousbContext) IterateDevices(reader func(desc *gousb.DeviceDesc)) error {
+	_, err := g.ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		reader(desc)
+		return false
+	})
+
+	return err
+}
+
+func (g *gousbContext) OpenDevice(vid, pid gousb.ID) (usb.Device, error) {
+	dev, err := g.ctx.OpenDeviceWithVIDPID(vid, pid)
+	if err != nil || dev == nil {
+		return nil, err
+	}
+
+	return usb.NewGOUSBDevice(dev)
+}
+
+func (g *gousbContext) OpenDeviceAt(bus, address int) (usb.Device, error) {
+	devs, err := g.ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		return desc.Bus == bus && desc.Address == address
+	})
+	// Errors of other devices are ignored, if the device is opened
+	if len(devs) == 0 {
+		return nil, err
+	}
+	// Bus and address are unique, so there is only one device
+	for _, dev := range devs[1:] {
+		dev.Close()
+	}
+
+	return usb.NewGOUSBDevice(devs[0])
+}
+
+func (g *gousbContext) Close() error {
+	return g.ctx.Close()
+}
@@ -0,0 +1,274 @@
+package eth
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+)
+
+var (
+	commandNames = map[uint8]string{
+		ADPU_INS_GET_CONFIGURATION:         "GET_CONFIGURATION",
+		ADPU_INS_GET_PUBLIC_KEY:            "GET_ADDRESS",
+		ADPU_INS_SIGN_TRANSACTION:          "SIGN_TX",
+		ADPU_INS_SIGN_PERSONAL_MESSAGE:     "SIGN_PERSONAL_MESSAGE",
+		ADPU_INS_SIGN_EIP712:               "SIGN_EIP712",
+		ADPU_INS_ETH2_GET_PUBLIC_KEY:       "ETH2_GET_PUBLIC_KEY",
+		ADPU_INS_ETH2_SET_WITHDRAWAL_INDEX: "ETH2_SET_WITHDRAWAL_INDEX",
+		ADPU_INS_PRIVACY_OPERATION:         "PRIVACY_OPERATION",
+		ADPU_INS_EIP712_SEND_STRUCT_DEF:    "EIP712 STRUCT_DEF",
+		ADPU_INS_EIP712_SEND_STRUCT_DATA:   "EIP712 STRUCT_DATA",
+		ADPU_INS_EIP712_CLEAR_SIGNING:      "EIP712 FILTER",
+		ADPU_INS_PROVIDE_ERC20_INFO:        "PROVIDE_ERC20_INFO",
+		ADPU_INS_GET_CHALLENGE:             "GET_CHALLENGE",
+		ADPU_INS_PROVIDE_DOMAIN_NAME:       "PROVIDE_DOMAIN_NAME",
+		ADPU_INS_PROVIDE_NFT_INFO:          "PROVIDE_NFT_INFO",
+		ADPU_INS_SET_PLUGIN:                "SET_PLUGIN",
+		ADPU_INS_SET_EXTERNAL_PLUGIN:       "SET_EXTERNAL_PLUGIN",
+	}
+
+	actionNames = map[eip712.Action]string{
+		eip712.ACTION_ACTIVATE:          "activate",
+		eip712.ACTION_MESSAGE_INFO:      "message info",
+		eip712.ACTION_DATETIME:          "datetime",
+		eip712.ACTION_AMOUNT_TOKEN_JOIN: "amount token join",
+		eip712.ACTION_AMOUNT_VALUE_JOIN: "amount value join",
+		eip712.ACTION_RAW:               "raw",
+	}
+)
+
+// Human-readable description of an ADPU command of Ethereum app
+type Dissection struct {
+	Header adpu.CommandHeader
+	// Short name of the command i.e. "SIGN_TX", "EIP712 STRUCT_DEF"
+	Name string
+	// Details decoded from P1, P2 and data i.e. "chunk 2 (more)", "field: uint256 amount"
+	Detail string
+	// Decoded fields of the command, attached when logged
+	Fields []slog.Attr
+}
+
+func (d Dissection) String() string {
+	if d.Detail == "" {
+		return d.Name
+	}
+
+	return d.Name + " " + d.Detail
+}
+
+func (d Dissection) LogValue() slog.Value {
+	attrs := append([]slog.Attr{slog.String("desc", d.String())}, d.Fields...)
+
+	return slog.GroupValue(attrs...)
+}
+
+// Dissector describes ADPU commands of Ethereum app. It keeps track of chunked commands,
+// so commands must be given in the order they are sent, i.e. in the order of a transcript.
+// Chunk numbers start from 0, the same as `adpu.ATTR_CHUNK`.
+type Dissector struct {
+	lock sync.Mutex
+	// Number of the last chunk sent, by INS
+	chunks map[uint8]int
+	// EIP712 struct data is chunked, but has no P1 to mark the first chunk
+	structDataPartial bool
+}
+
+func NewDissector() *Dissector {
+	return &Dissector{
+		chunks: make(map[uint8]int),
+	}
+}
+
+// Dissect a whole ADPU command, i.e. `TranscriptEntry.Command`
+func (d *Dissector) DissectCommand(command []byte) (Dissection, error) {
+	header, data, err := adpu.DecodeCommand(command)
+	if err != nil {
+		return Dissection{}, fmt.Errorf("unable to decode ADPU command: %w", err)
+	}
+
+	return d.Dissect(header, data), nil
+}
+
+func (d *Dissector) nextChunk(ins uint8, first bool) int {
+	chunk := 0
+	if !first {
+		chunk = d.chunks[ins] + 1
+	}
+	d.chunks[ins] = chunk
+
+	return chunk
+}
+
+func chunkDetail(chunk int, kind string) string {
+	return fmt.Sprintf("chunk %d (%s)", chunk, kind)
+}
+
+// Describe BIP-32 path at the beginning of data, and return the remaining data
+func pathDetail(data []byte, dissection *Dissection) []byte {
+	path, rest, err := schema.DecodeBIP32Path(data)
+	if err != nil {
+		dissection.Fields = append(dissection.Fields, slog.String("pathError", err.Error()))
+		return nil
+	}
+	dissection.Fields = append(dissection.Fields, slog.String("path", string(path)))
+
+	return rest
+}
+
+func (d *Dissector) Dissect(header adpu.CommandHeader, data []byte) Dissection {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	dissection := Dissection{
+		Header: header,
+	}
+	name, ok := commandNames[header.INS]
+	if header.CLA != ADPU_CLA || !ok {
+		dissection.Name = fmt.Sprintf("UNKNOWN CLA 0x%02x INS 0x%02x", header.CLA, header.INS)
+		dissection.Fields = append(dissection.Fields, slog.Int("length", len(data)))
+		return dissection
+	}
+	dissection.Name = name
+
+	var details []string
+	switch header.INS {
+	case ADPU_INS_GET_PUBLIC_KEY:
+		rest := pathDetail(data, &dissection)
+		if len(rest) == 8 {
+			dissection.Fields = append(dissection.Fields, slog.Uint64("chainID", binary.BigEndian.Uint64(rest)))
+		}
+		if header.P1 == P1_WITH_CONFIRM {
+			details = append(details, "(confirm)")
+		}
+		if header.P2 == 0x01 {
+			details = append(details, "(chaincode)")
+		}
+	case ADPU_INS_SIGN_TRANSACTION, ADPU_INS_SIGN_PERSONAL_MESSAGE:
+		first := header.P1 == P1_FIRST_CHUNK
+		chunk := d.nextChunk(header.INS, first)
+		if first {
+			details = append(details, chunkDetail(chunk, "first"))
+			rest := pathDetail(data, &dissection)
+			if header.INS == ADPU_INS_SIGN_PERSONAL_MESSAGE && len(rest) >= 4 {
+				dissection.Fields = append(dissection.Fields, slog.Uint64("messageLength", uint64(binary.BigEndian.Uint32(rest))))
+			}
+		} else {
+			details = append(details, chunkDetail(chunk, "more"))
+		}
+		dissection.Fields = append(dissection.Fields, slog.Int("chunk", chunk))
+	case ADPU_INS_SIGN_EIP712:
+		if header.P2 == 0x00 {
+			details = append(details, "hashed")
+		}
+		pathDetail(data, &dissection)
+	case ADPU_INS_ETH2_GET_PUBLIC_KEY:
+		pathDetail(data, &dissection)
+		if header.P1 == P1_WITH_CONFIRM {
+			details = append(details, "(confirm)")
+		}
+	case ADPU_INS_ETH2_SET_WITHDRAWAL_INDEX:
+		if len(data) == 4 {
+			details = append(details, fmt.Sprintf("index %d", binary.BigEndian.Uint32(data)))
+		}
+	case ADPU_INS_PRIVACY_OPERATION:
+		if header.P2 == 0x01 {
+			details = append(details, "shared secret")
+		} else {
+			details = append(details, "public key")
+		}
+		pathDetail(data, &dissection)
+		if header.P1 == P1_WITH_CONFIRM {
+			details = append(details, "(confirm)")
+		}
+	case ADPU_INS_EIP712_SEND_STRUCT_DEF:
+		switch eip712.Component(header.P2) {
+		case eip712.TYPE_COMPONENT_NAME:
+			details = append(details, "name: "+string(data))
+		case eip712.TYPE_COMPONENT_FIELD:
+			var field eip712.FieldDefinition
+			if err := field.UnmarshalADPU(data); err != nil {
+				details = append(details, "field: (malformed)")
+				dissection.Fields = append(dissection.Fields, slog.String("fieldError", err.Error()))
+			} else {
+				details = append(details, "field: "+field.TypeName()+" "+field.KeyName)
+			}
+		default:
+			details = append(details, fmt.Sprintf("unknown component 0x%02x", header.P2))
+		}
+	case ADPU_INS_EIP712_SEND_STRUCT_DATA:
+		first := !d.structDataPartial
+		d.structDataPartial = header.P1 == P1_PARTIAL
+		chunk := d.nextChunk(header.INS, first)
+		switch eip712.Component(header.P2) {
+		case eip712.DATA_COMPONENT_ROOT:
+			details = append(details, "root: "+string(data))
+		case eip712.DATA_COMPONENT_ARRAY:
+			if len(data) == 1 {
+				details = append(details, fmt.Sprintf("array: size %d", data[0]))
+			}
+		case eip712.DATA_COMPONENT_ATOMIC:
+			details = append(details, "field")
+			if first && len(data) >= 2 {
+				dissection.Fields = append(dissection.Fields, slog.Int("valueLength", int(binary.BigEndian.Uint16(data))))
+			}
+		default:
+			details = append(details, fmt.Sprintf("unknown component 0x%02x", header.P2))
+		}
+		if !first || d.structDataPartial {
+			kind := "complete"
+			if d.structDataPartial {
+				kind = "partial"
+			}
+			details = append(details, chunkDetail(chunk, kind))
+			dissection.Fields = append(dissection.Fields, slog.Int("chunk", chunk))
+		}
+	case ADPU_INS_EIP712_CLEAR_SIGNING:
+		if action, ok := actionNames[eip712.Action(header.P2)]; ok {
+			details = append(details, action)
+		} else {
+			details = append(details, fmt.Sprintf("unknown action 0x%02x", header.P2))
+		}
+	case ADPU_INS_PROVIDE_ERC20_INFO:
+		if len(data) > 0 && len(data) >= 1+int(data[0]) {
+			details = append(details, "ticker "+string(data[1:1+int(data[0])]))
+		}
+	case ADPU_INS_PROVIDE_DOMAIN_NAME:
+		first := header.P1 == P1_CS_FIRST_CHUNK
+		chunk := d.nextChunk(header.INS, first)
+		if first {
+			details = append(details, chunkDetail(chunk, "first"))
+		} else {
+			details = append(details, chunkDetail(chunk, "following"))
+		}
+		dissection.Fields = append(dissection.Fields, slog.Int("chunk", chunk))
+	}
+	dissection.Detail = strings.Join(details, " ")
+	dissection.Fields = append(dissection.Fields, slog.Int("length", len(data)))
+
+	return dissection
+}
+
+// Create an interceptor that logs every ADPU command sent to Ethereum app as a dissection, at debug level
+func NewDissectingInterceptor(logger *slog.Logger) adpu.Interceptor {
+	dissector := NewDissector()
+
+	return func(ctx context.Context, call adpu.Call, next adpu.Handler) (adpu.Result, error) {
+		dissection := dissector.Dissect(call.Header, call.Data)
+		logger.DebugContext(ctx, "Send ADPU command", "command", dissection)
+		res, err := next(ctx)
+		if err != nil {
+			logger.DebugContext(ctx, "ADPU command failed", "command", dissection.String(), "err", err)
+		} else {
+			logger.DebugContext(ctx, "ADPU command completed", "command", dissection.String(), "sw", fmt.Sprintf("0x%04x", res.SW), "latency", res.Latency)
+		}
+
+		return res, err
+	}
+}
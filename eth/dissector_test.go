@@ -0,0 +1,154 @@
+package eth_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/emulator"
+	"github.com/stretchr/testify/assert"
+)
+
+// BIP-32 path "m'/44'/60'/0'/0/0" in ADPU format
+var testBIP32PathADPU = mustDecodeHex("058000002c8000003c800000000000000000000000")
+
+func TestDissector_Dissect(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []adpu.Call
+		expected []string
+	}{
+		{
+			name: "GetAddress",
+			commands: []adpu.Call{
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_GET_PUBLIC_KEY, P1: eth.P1_WITH_CONFIRM, P2: 0x01}, Data: testBIP32PathADPU},
+			},
+			expected: []string{"GET_ADDRESS (confirm) (chaincode)"},
+		},
+		{
+			name: "SignTransaction",
+			commands: []adpu.Call{
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_SIGN_TRANSACTION, P1: eth.P1_FIRST_CHUNK}, Data: append(testBIP32PathADPU, 0xf8)},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_SIGN_TRANSACTION, P1: eth.P1_MORE_CHUNK}, Data: []byte{0x01}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_SIGN_TRANSACTION, P1: eth.P1_MORE_CHUNK}, Data: []byte{0x01}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_SIGN_TRANSACTION, P1: eth.P1_FIRST_CHUNK}, Data: testBIP32PathADPU},
+			},
+			expected: []string{"SIGN_TX chunk 0 (first)", "SIGN_TX chunk 1 (more)", "SIGN_TX chunk 2 (more)", "SIGN_TX chunk 0 (first)"},
+		},
+		{
+			name: "EIP712",
+			commands: []adpu.Call{
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DEF, P2: 0x00}, Data: []byte("Permit")},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DEF, P2: 0xFF}, Data: append([]byte{0x42, 0x20, 0x06}, "amount"...)},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DEF, P2: 0xFF}, Data: []byte{0x42}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_CLEAR_SIGNING, P2: 0x00}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DATA, P1: eth.P1_COMPLETE, P2: 0x00}, Data: []byte("Permit")},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DATA, P1: eth.P1_COMPLETE, P2: 0x0F}, Data: []byte{0x03}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DATA, P1: eth.P1_PARTIAL, P2: 0xFF}, Data: []byte{0x01, 0x2c}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_EIP712_SEND_STRUCT_DATA, P1: eth.P1_COMPLETE, P2: 0xFF}, Data: []byte{0x00}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_SIGN_EIP712, P2: 0x01}, Data: testBIP32PathADPU},
+			},
+			expected: []string{
+				"EIP712 STRUCT_DEF name: Permit",
+				"EIP712 STRUCT_DEF field: uint256 amount",
+				"EIP712 STRUCT_DEF field: (malformed)",
+				"EIP712 FILTER activate",
+				"EIP712 STRUCT_DATA root: Permit",
+				"EIP712 STRUCT_DATA array: size 3",
+				"EIP712 STRUCT_DATA field chunk 0 (partial)",
+				"EIP712 STRUCT_DATA field chunk 1 (complete)",
+				"SIGN_EIP712",
+			},
+		},
+		{
+			name: "Others",
+			commands: []adpu.Call{
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_PRIVACY_OPERATION, P2: 0x01}, Data: testBIP32PathADPU},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_ETH2_SET_WITHDRAWAL_INDEX}, Data: []byte{0x00, 0x00, 0x00, 0x07}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_PROVIDE_ERC20_INFO}, Data: append([]byte{0x04}, "USDC"...)},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_PROVIDE_DOMAIN_NAME, P1: eth.P1_CS_FIRST_CHUNK}},
+				{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_PROVIDE_DOMAIN_NAME, P1: eth.P1_CS_FOLLOWING_CHUNK}},
+				{Header: adpu.CommandHeader{CLA: 0xB0, INS: 0x01}},
+			},
+			expected: []string{
+				"PRIVACY_OPERATION shared secret",
+				"ETH2_SET_WITHDRAWAL_INDEX index 7",
+				"PROVIDE_ERC20_INFO ticker USDC",
+				"PROVIDE_DOMAIN_NAME chunk 0 (first)",
+				"PROVIDE_DOMAIN_NAME chunk 1 (following)",
+				"UNKNOWN CLA 0xb0 INS 0x01",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dissector := eth.NewDissector()
+			var descriptions []string
+			for _, command := range test.commands {
+				descriptions = append(descriptions, dissector.Dissect(command.Header, command.Data).String())
+			}
+
+			assert.Equal(t, test.expected, descriptions)
+		})
+	}
+}
+
+func TestDissection_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	dissection := eth.NewDissector().Dissect(adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_SIGN_TRANSACTION}, append(testBIP32PathADPU, 0xf8))
+
+	logger.Info("Send", "command", dissection)
+
+	assert.Contains(t, buf.String(), `command.desc="SIGN_TX chunk 0 (first)" command.path=m'/44'/60'/0'/0/0 command.chunk=0 command.length=22`)
+}
+
+func TestDissector_DissectCommand_Transcript(t *testing.T) {
+	var transcript bytes.Buffer
+	emu, err := emulator.NewProtocol(mustDecodeHex("5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"), slog.Default())
+	assert.NoError(t, err)
+	app := eth.NewEthereumApp(adpu.NewRecordingProtocol(emu, &transcript), slog.Default())
+	_, err = app.SignEIP712Message(context.Background(), testBIP32Path, mailMessage())
+	assert.NoError(t, err)
+	entries, err := adpu.ReadTranscript(&transcript)
+	assert.NoError(t, err)
+
+	dissector := eth.NewDissector()
+	var descriptions []string
+	for _, entry := range entries {
+		dissection, err := dissector.DissectCommand(entry.Command)
+		assert.NoError(t, err)
+		descriptions = append(descriptions, dissection.String())
+	}
+
+	assert.Contains(t, descriptions, "EIP712 STRUCT_DEF name: Mail")
+	assert.Contains(t, descriptions, "EIP712 STRUCT_DEF field: Person from")
+	assert.Contains(t, descriptions, "EIP712 STRUCT_DEF field: uint256 chainId")
+	assert.Contains(t, descriptions, "EIP712 STRUCT_DATA root: EIP712Domain")
+	assert.Equal(t, "SIGN_EIP712", descriptions[len(descriptions)-1])
+
+	_, err = dissector.DissectCommand([]byte{0xe0, 0x04})
+	assert.ErrorIs(t, err, adpu.ErrMalformedCommand)
+}
+
+func TestNewDissectingInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := func(ctx context.Context) (adpu.Result, error) {
+		return adpu.Result{SW: adpu.SW_OK}, nil
+	}
+	interceptor := eth.NewDissectingInterceptor(logger)
+
+	_, err := interceptor(context.Background(), adpu.Call{Header: adpu.CommandHeader{CLA: eth.ADPU_CLA, INS: eth.ADPU_INS_GET_CONFIGURATION}}, handler)
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "command.desc=GET_CONFIGURATION")
+	assert.Contains(t, lines[1], "sw=0x9000")
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/ntchjb/ledger-go/adpu"
 	"github.com/ntchjb/ledger-go/eth/schema"
 	"github.com/ntchjb/ledger-go/eth/schema/eip712"
-	"github.com/ntchjb/ledger-go/log"
 )
 
 func (e *ethereumAppImpl) EIP712SendStructDefinition(ctx context.Context, component eip712.Component, value []byte) error {
@@ -16,9 +15,9 @@ func (e *ethereumAppImpl) EIP712SendStructDefinition(ctx context.Context, compon
 	p1 := uint8(0x00)
 	p2 := uint8(component)
 
-	e.logger.Debug("Send EIP712 struct definition", "component", component, "value", log.HexDisplay(value))
+	e.logger.Debug("Send EIP712 struct definition", "component", component, "value", e.payloadValue(ADPU_INS_EIP712_SEND_STRUCT_DEF, value))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_EIP712_SEND_STRUCT_DEF, p1, p2, &req, &res); err != nil {
-		return fmt.Errorf("unable to send a send struct definition command to device: %w", err)
+		return fmt.Errorf("unable to send a send struct definition command to device: %w", translateError(err))
 	}
 
 	return nil
@@ -30,9 +29,9 @@ func (e *ethereumAppImpl) EIP712SendClearSigningData(ctx context.Context, action
 	p1 := uint8(0x00)
 	p2 := uint8(action)
 
-	e.logger.Debug("Provide EIP712 clear signing data", "action", action, "value", log.HexDisplay(value))
+	e.logger.Debug("Provide EIP712 clear signing data", "action", action, "value", e.payloadValue(ADPU_INS_EIP712_CLEAR_SIGNING, value))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_EIP712_CLEAR_SIGNING, p1, p2, &req, &res); err != nil {
-		return fmt.Errorf("unable to send EIP712 clear signing command to device: %w", err)
+		return fmt.Errorf("unable to send EIP712 clear signing command to device: %w", translateError(err))
 	}
 
 	return nil
@@ -42,7 +41,7 @@ func (e *ethereumAppImpl) EIP712SendStructData(ctx context.Context, component ei
 	var req schema.RawRequest
 	var res schema.EmptyResponse
 
-	for offset := 0; offset < len(value); {
+	for chunk, offset := 0, 0; offset < len(value); chunk++ {
 		chunkSize := 255
 		p1 := P1_PARTIAL
 		p2 := uint8(component)
@@ -52,9 +51,9 @@ func (e *ethereumAppImpl) EIP712SendStructData(ctx context.Context, component ei
 		}
 		req = value[offset : offset+chunkSize]
 
-		e.logger.Debug("Send EIP712 data", "val", log.HexDisplay(req), "component", component, "p1", p1, "p2", p2)
-		if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_EIP712_SEND_STRUCT_DATA, p1, p2, &req, &res); err != nil {
-			return fmt.Errorf("unable to send a send EIP712 data command to device: %w", err)
+		e.logger.Debug("Send EIP712 data", "val", e.payloadValue(ADPU_INS_EIP712_SEND_STRUCT_DATA, req), "component", component, "p1", p1, "p2", p2)
+		if err := adpu.Send(e.chunkContext(ctx, chunk), e.proto, ADPU_CLA, ADPU_INS_EIP712_SEND_STRUCT_DATA, p1, p2, &req, &res); err != nil {
+			return fmt.Errorf("unable to send a send EIP712 data command to device: %w", translateError(err))
 		}
 
 		offset += chunkSize
@@ -193,7 +192,7 @@ func (e *ethereumAppImpl) SignEIP712Message(ctx context.Context, bip32Path strin
 	req := schema.BIP32Path(bip32Path)
 	e.logger.Debug("Sign EIP712 message", "bip32Path", bip32Path)
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_SIGN_EIP712, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send sign EIP712 command to device: %w", err)
+		return res, fmt.Errorf("unable to send sign EIP712 command to device: %w", translateError(err))
 	}
 
 	return res, nil
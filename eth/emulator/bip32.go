@@ -0,0 +1,81 @@
+package emulator
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+const (
+	// Index offset of hardened child keys, written as `44'` in BIP-32 path string
+	BIP32_HARDENED_OFFSET uint32 = 0x8000_0000
+)
+
+// BIP-32 extended private key
+type extendedKey struct {
+	privateKey []byte
+	chainCode  []byte
+}
+
+func hmacSHA512(key []byte, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// Generate master key from BIP-32 seed
+func newMasterKey(seed []byte) (extendedKey, error) {
+	sum := hmacSHA512([]byte("Bitcoin seed"), seed)
+	var key secp256k1.ModNScalar
+	if overflow := key.SetByteSlice(sum[:32]); overflow || key.IsZero() {
+		return extendedKey{}, fmt.Errorf("unable to use seed as master key: %w", ErrInvalidSeed)
+	}
+
+	return extendedKey{
+		privateKey: sum[:32],
+		chainCode:  sum[32:],
+	}, nil
+}
+
+// Derive child private key (CKDpriv)
+func (k extendedKey) child(index uint32) (extendedKey, error) {
+	var data []byte
+	if index >= BIP32_HARDENED_OFFSET {
+		data = append([]byte{0x00}, k.privateKey...)
+	} else {
+		data = secp256k1.PrivKeyFromBytes(k.privateKey).PubKey().SerializeCompressed()
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	// Child key is parse256(IL) + parent key (mod n), which is invalid if IL >= n or the sum is zero
+	sum := hmacSHA512(k.chainCode, data)
+	var tweak, key secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return extendedKey{}, fmt.Errorf("unable to derive child key %d, IL is out of range: %w", index, ErrInvalidChildKey)
+	}
+	key.SetByteSlice(k.privateKey)
+	if key.Add(&tweak).IsZero() {
+		return extendedKey{}, fmt.Errorf("unable to derive child key %d, key is zero: %w", index, ErrInvalidChildKey)
+	}
+	privateKey := key.Bytes()
+
+	return extendedKey{
+		privateKey: privateKey[:],
+		chainCode:  sum[32:],
+	}, nil
+}
+
+func (k extendedKey) derive(path []uint32) (extendedKey, error) {
+	var err error
+	for _, index := range path {
+		if k, err = k.child(index); err != nil {
+			return extendedKey{}, err
+		}
+	}
+
+	return k, nil
+}
@@ -0,0 +1,285 @@
+package emulator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+)
+
+// A struct whose `hashStruct` is being computed from data commands
+type eip712Frame struct {
+	typeName string
+	fields   []eip712.FieldDefinition
+	// Index of the field to be filled by the next value
+	index int
+	// encodeData of the fields received so far
+	encoded []byte
+	// Arrays of the current field being received, from outermost to innermost
+	arrays []*eip712Array
+}
+
+type eip712Array struct {
+	remaining int
+	encoded   []byte
+}
+
+type eip712Context struct {
+	types map[string][]eip712.FieldDefinition
+	// Struct that the next field definition belongs to
+	currentType string
+
+	frames []*eip712Frame
+	// Atomic value received by partial chunks
+	partial []byte
+
+	domainHash  []byte
+	messageHash []byte
+}
+
+func newEIP712Context() *eip712Context {
+	return &eip712Context{
+		types: make(map[string][]eip712.FieldDefinition),
+	}
+}
+
+func (c *eip712Context) ready() bool {
+	return len(c.frames) == 0 && c.domainHash != nil && c.messageHash != nil
+}
+
+func (c *eip712Context) defineStruct(p1, p2 uint8, data []byte) error {
+	if p1 != 0x00 {
+		return reject(adpu.SW_INCORRECT_P1_P2, "unknown P1 0x%02x", p1)
+	}
+
+	switch eip712.Component(p2) {
+	case eip712.TYPE_COMPONENT_NAME:
+		c.currentType = string(data)
+		c.types[c.currentType] = nil
+	case eip712.TYPE_COMPONENT_FIELD:
+		if c.currentType == "" {
+			return reject(adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, "field is defined before struct name")
+		}
+		var field eip712.FieldDefinition
+		if err := field.UnmarshalADPU(data); err != nil {
+			return reject(adpu.SW_INCORRECT_DATA, "unable to decode field definition: %v", err)
+		}
+		c.types[c.currentType] = append(c.types[c.currentType], field)
+	default:
+		return reject(adpu.SW_INCORRECT_P1_P2, "unknown P2 0x%02x", p2)
+	}
+
+	return nil
+}
+
+// Collect names of struct types referenced by the type, recursively
+func (c *eip712Context) dependencies(typeName string, found map[string]bool) error {
+	if found[typeName] {
+		return nil
+	}
+	fields, ok := c.types[typeName]
+	if !ok {
+		return fmt.Errorf("type %s is not defined", typeName)
+	}
+	found[typeName] = true
+	for _, field := range fields {
+		if field.TypeDescription.Type != eip712.FIELD_TYPE_DESC_TYPE_CUSTOM {
+			continue
+		}
+		if err := c.dependencies(field.CustomTypeName, found); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EIP-712 `typeHash`, keccak256 of `encodeType`
+// i.e. `Mail(Person from,Person to,string contents)Person(string name,address wallet)`
+func (c *eip712Context) typeHash(typeName string) ([]byte, error) {
+	found := make(map[string]bool)
+	if err := c.dependencies(typeName, found); err != nil {
+		return nil, err
+	}
+	delete(found, typeName)
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var encodeType strings.Builder
+	for _, name := range append([]string{typeName}, names...) {
+		members := make([]string, len(c.types[name]))
+		for i, field := range c.types[name] {
+			members[i] = field.TypeName() + " " + field.KeyName
+		}
+		encodeType.WriteString(name + "(" + strings.Join(members, ",") + ")")
+	}
+
+	return schema.Keccak256([]byte(encodeType.String())), nil
+}
+
+func (c *eip712Context) pushFrame(typeName string) error {
+	fields, ok := c.types[typeName]
+	if !ok {
+		return reject(adpu.SW_INCORRECT_DATA, "type %s is not defined", typeName)
+	}
+	c.frames = append(c.frames, &eip712Frame{
+		typeName: typeName,
+		fields:   fields,
+	})
+
+	return c.settle()
+}
+
+// Append encoded value to the current field, completing arrays whose elements are all received
+func (c *eip712Context) deliver(value []byte) {
+	frame := c.frames[len(c.frames)-1]
+	for len(frame.arrays) > 0 {
+		array := frame.arrays[len(frame.arrays)-1]
+		array.encoded = append(array.encoded, value...)
+		array.remaining--
+		if array.remaining > 0 {
+			return
+		}
+		frame.arrays = frame.arrays[:len(frame.arrays)-1]
+		value = schema.Keccak256(array.encoded)
+	}
+	frame.encoded = append(frame.encoded, value...)
+	frame.index++
+}
+
+// Compute `hashStruct` of structs whose fields are all received
+func (c *eip712Context) settle() error {
+	for len(c.frames) > 0 {
+		frame := c.frames[len(c.frames)-1]
+		if frame.index < len(frame.fields) {
+			return nil
+		}
+		typeHash, err := c.typeHash(frame.typeName)
+		if err != nil {
+			return reject(adpu.SW_INCORRECT_DATA, "unable to encode type: %v", err)
+		}
+		hash := schema.Keccak256(typeHash, frame.encoded)
+		c.frames = c.frames[:len(c.frames)-1]
+
+		if len(c.frames) > 0 {
+			c.deliver(hash)
+		} else if frame.typeName == eip712.DOMAIN_TYPE_NAME {
+			c.domainHash = hash
+		} else {
+			c.messageHash = hash
+		}
+	}
+
+	return nil
+}
+
+// Get field to be filled by the next data command, entering nested structs if needed
+func (c *eip712Context) nextField() (*eip712Frame, eip712.FieldDefinition, error) {
+	for {
+		if len(c.frames) == 0 {
+			return nil, eip712.FieldDefinition{}, reject(adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, "no root struct is being received")
+		}
+		frame := c.frames[len(c.frames)-1]
+		field := frame.fields[frame.index]
+		if len(frame.arrays) < len(field.ArrayLevels) || field.TypeDescription.Type != eip712.FIELD_TYPE_DESC_TYPE_CUSTOM {
+			return frame, field, nil
+		}
+		if err := c.pushFrame(field.CustomTypeName); err != nil {
+			return nil, eip712.FieldDefinition{}, err
+		}
+	}
+}
+
+// Encode atomic value to 32 bytes, as EIP-712 `encodeData` does
+func encodeAtomic(field eip712.FieldDefinition, value []byte) ([]byte, error) {
+	switch field.TypeDescription.Type {
+	case eip712.FIELD_TYPE_DESC_TYPE_STRING, eip712.FIELD_TYPE_DESC_TYPE_DYNAMIC_SIZED_BYTES:
+		return schema.Keccak256(value), nil
+	}
+	if len(value) > 32 {
+		return nil, fmt.Errorf("value of %s is longer than 32 bytes", field.TypeName())
+	}
+
+	res := make([]byte, 32)
+	switch field.TypeDescription.Type {
+	case eip712.FIELD_TYPE_DESC_TYPE_FIXED_SIZE_BYTES:
+		copy(res, value)
+		return res, nil
+	case eip712.FIELD_TYPE_DESC_TYPE_INT:
+		// Negative number is sent as two's complement of its type size
+		if len(value) > 0 && len(value) == int(field.TypeSize) && value[0]&0x80 != 0 {
+			for i := range res {
+				res[i] = 0xFF
+			}
+		}
+	}
+	copy(res[32-len(value):], value)
+
+	return res, nil
+}
+
+func (c *eip712Context) receiveData(p1, p2 uint8, data []byte) error {
+	if p1 != eth.P1_COMPLETE && p1 != eth.P1_PARTIAL {
+		return reject(adpu.SW_INCORRECT_P1_P2, "unknown P1 0x%02x", p1)
+	}
+
+	switch eip712.Component(p2) {
+	case eip712.DATA_COMPONENT_ROOT:
+		if len(c.frames) > 0 {
+			return reject(adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, "struct %s is not fully received", c.frames[0].typeName)
+		}
+		return c.pushFrame(string(data))
+	case eip712.DATA_COMPONENT_ARRAY:
+		if len(data) != 1 {
+			return reject(adpu.SW_INCORRECT_DATA, "expected 1 byte of array length, got %d", len(data))
+		}
+		frame, field, err := c.nextField()
+		if err != nil {
+			return err
+		}
+		if len(frame.arrays) >= len(field.ArrayLevels) {
+			return reject(adpu.SW_INCORRECT_DATA, "field %s is not an array", field.KeyName)
+		}
+		if data[0] == 0 {
+			c.deliver(schema.Keccak256())
+		} else {
+			frame.arrays = append(frame.arrays, &eip712Array{remaining: int(data[0])})
+		}
+	case eip712.DATA_COMPONENT_ATOMIC:
+		c.partial = append(c.partial, data...)
+		if p1 == eth.P1_PARTIAL {
+			return nil
+		}
+		value := c.partial
+		c.partial = nil
+
+		// [length (2 bytes), value...]
+		if len(value) < 2 || int(binary.BigEndian.Uint16(value[:2])) != len(value)-2 {
+			return reject(adpu.SW_INCORRECT_DATA, "atomic value length does not match")
+		}
+		frame, field, err := c.nextField()
+		if err != nil {
+			return err
+		}
+		if len(frame.arrays) < len(field.ArrayLevels) {
+			return reject(adpu.SW_INCORRECT_DATA, "expected array length of field %s", field.KeyName)
+		}
+		encoded, err := encodeAtomic(field, value[2:])
+		if err != nil {
+			return reject(adpu.SW_INCORRECT_DATA, "unable to encode field %s: %v", field.KeyName, err)
+		}
+		c.deliver(encoded)
+	default:
+		return reject(adpu.SW_INCORRECT_P1_P2, "unknown P2 0x%02x", p2)
+	}
+
+	return c.settle()
+}
@@ -0,0 +1,327 @@
+package emulator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/log"
+)
+
+const (
+	// Version of Ethereum app reported by GET_CONFIGURATION
+	APP_VERSION_MAJOR uint8 = 1
+	APP_VERSION_MINOR uint8 = 13
+	APP_VERSION_PATCH uint8 = 0
+	// Configuration flags reported by GET_CONFIGURATION, only arbitrary data signing is enabled
+	APP_CONFIGURATION_FLAGS uint8 = 0x01
+
+	// Maximum number of BIP-32 path elements accepted by Ethereum app
+	MAX_BIP32_PATH_LENGTH int = 10
+	// Length of challenge returned by GET_CHALLENGE
+	CHALLENGE_LENGTH int = 4
+)
+
+var (
+	ErrInvalidSeed     = errors.New("invalid seed")
+	ErrInvalidChildKey = errors.New("invalid child key")
+)
+
+// Error that is sent to client as status word
+type statusError struct {
+	sw  uint16
+	err error
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("SW 0x%04x (%s): %v", e.sw, adpu.SWMessage[e.sw], e.err)
+}
+
+func reject(sw uint16, format string, args ...any) error {
+	return &statusError{
+		sw:  sw,
+		err: fmt.Errorf(format, args...),
+	}
+}
+
+type ethereumAppEmulator struct {
+	logger    *slog.Logger
+	masterKey extendedKey
+
+	// States of multi-command operations
+	tx              *txContext
+	personalMessage *personalMessageContext
+	eip712          *eip712Context
+	erc20Count      uint8
+
+	lock sync.Mutex
+}
+
+// Create a protocol that emulates Ledger Ethereum app in memory,
+// with keys derived from BIP-32 `seed`, for testing `eth.EthereumApp` without a device.
+//
+// Every request is approved immediately as if user confirmed it on the device.
+// Signatures are real, and V values are computed the same way as the app does,
+// including its truncated V of legacy transactions with large chain ID.
+func NewProtocol(seed []byte, logger *slog.Logger) (adpu.Protocol, error) {
+	masterKey, err := newMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSeed, err)
+	}
+
+	return &ethereumAppEmulator{
+		logger:    logger,
+		masterKey: masterKey,
+	}, nil
+}
+
+func (e *ethereumAppEmulator) Exchange(ctx context.Context, command []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	header, data, err := adpu.DecodeCommand(command)
+	if err != nil {
+		e.logger.Debug("Emulator rejected malformed command", "command", log.HexDisplay(command), "err", err)
+		return binary.BigEndian.AppendUint16(nil, adpu.SW_INCORRECT_LENGTH), nil
+	}
+
+	res, sw := e.handle(header.CLA, header.INS, header.P1, header.P2, data)
+
+	return binary.BigEndian.AppendUint16(res, sw), nil
+}
+
+func (e *ethereumAppEmulator) Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	res, sw := e.handle(cla, ins, p1, p2, data)
+
+	return res, sw, nil
+}
+
+// Emulator has no transport, so there is no stale frame to discard
+func (e *ethereumAppEmulator) Reset(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (e *ethereumAppEmulator) handle(cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.logger.Debug("Emulator received command", "cla", cla, "ins", ins, "p1", p1, "p2", p2, "data", log.HexDisplay(data))
+
+	var res []byte
+	var err error
+	if cla != eth.ADPU_CLA {
+		err = reject(adpu.SW_CLA_NOT_SUPPORTED, "unknown CLA 0x%02x", cla)
+	} else {
+		switch ins {
+		case eth.ADPU_INS_GET_CONFIGURATION:
+			res = []byte{APP_CONFIGURATION_FLAGS, APP_VERSION_MAJOR, APP_VERSION_MINOR, APP_VERSION_PATCH}
+		case eth.ADPU_INS_GET_PUBLIC_KEY:
+			res, err = e.getPublicKey(p1, p2, data)
+		case eth.ADPU_INS_SIGN_TRANSACTION:
+			res, err = e.signTransaction(p1, data)
+		case eth.ADPU_INS_SIGN_PERSONAL_MESSAGE:
+			res, err = e.signPersonalMessage(p1, data)
+		case eth.ADPU_INS_EIP712_SEND_STRUCT_DEF:
+			err = e.eip712Context().defineStruct(p1, p2, data)
+		case eth.ADPU_INS_EIP712_SEND_STRUCT_DATA:
+			err = e.eip712Context().receiveData(p1, p2, data)
+		case eth.ADPU_INS_EIP712_CLEAR_SIGNING:
+			// Filters only affect what is displayed, so they are accepted as is
+		case eth.ADPU_INS_SIGN_EIP712:
+			res, err = e.signEIP712(p1, p2, data)
+		case eth.ADPU_INS_PROVIDE_ERC20_INFO:
+			// Token signature cannot be verified without Ledger's key, so every token is accepted
+			res = []byte{e.erc20Count}
+			e.erc20Count++
+		case eth.ADPU_INS_GET_CHALLENGE:
+			res = make([]byte, CHALLENGE_LENGTH)
+			_, err = rand.Read(res)
+		default:
+			err = reject(adpu.SW_INS_NOT_SUPPORTED, "INS 0x%02x is not emulated", ins)
+		}
+	}
+
+	if err != nil {
+		var statusErr *statusError
+		if !errors.As(err, &statusErr) {
+			statusErr = &statusError{sw: adpu.SW_TECHNICAL_PROBLEM, err: err}
+		}
+		e.logger.Debug("Emulator rejected command", "ins", ins, "err", statusErr)
+
+		return nil, statusErr.sw
+	}
+
+	return res, adpu.SW_OK
+}
+
+// Parse BIP-32 path at the beginning of command data, [length (1 byte), path elements (4 bytes each)...]
+func parsePath(data []byte) ([]uint32, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, reject(adpu.SW_INCORRECT_DATA, "missing BIP-32 path")
+	}
+	length := int(data[0])
+	if length == 0 || length > MAX_BIP32_PATH_LENGTH {
+		return nil, nil, reject(adpu.SW_INCORRECT_DATA, "invalid BIP-32 path length %d", length)
+	}
+	if len(data) < 1+length*4 {
+		return nil, nil, reject(adpu.SW_INCORRECT_DATA, "BIP-32 path is truncated, expected %d bytes, got %d", 1+length*4, len(data))
+	}
+
+	path := make([]uint32, length)
+	for i := range path {
+		path[i] = binary.BigEndian.Uint32(data[1+i*4:])
+	}
+
+	return path, data[1+length*4:], nil
+}
+
+// Sign hash by key at BIP-32 path, and return [V, R (32 bytes), S (32 bytes)],
+// where V is computed from Y parity by `v`
+func (e *ethereumAppEmulator) sign(path []uint32, hash []byte, v func(parity byte) byte) ([]byte, error) {
+	key, err := e.masterKey.derive(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive key: %w", err)
+	}
+	// [27 + recovery ID, R, S], where bit 0 of recovery ID is Y parity
+	res := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(key.privateKey), hash, false)
+	res[0] = v((res[0] - 27) & 0x01)
+
+	return res, nil
+}
+
+// V value of personal message and EIP-712 signatures
+func v27(parity byte) byte {
+	return 27 + parity
+}
+
+func (e *ethereumAppEmulator) getPublicKey(p1, p2 uint8, data []byte) ([]byte, error) {
+	if p1 > eth.P1_WITH_CONFIRM || p2 > 0x01 {
+		return nil, reject(adpu.SW_INCORRECT_P1_P2, "unknown P1 0x%02x or P2 0x%02x", p1, p2)
+	}
+	path, rest, err := parsePath(data)
+	if err != nil {
+		return nil, err
+	}
+	// Optional chain ID only affects how address is displayed
+	if len(rest) != 0 && len(rest) != 8 {
+		return nil, reject(adpu.SW_INCORRECT_DATA, "unexpected %d bytes after BIP-32 path", len(rest))
+	}
+
+	key, err := e.masterKey.derive(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive key: %w", err)
+	}
+	publicKey := secp256k1.PrivKeyFromBytes(key.privateKey).PubKey().SerializeUncompressed()
+	uncompressed := schema.PublicKey(publicKey)
+	address := uncompressed.Address()
+
+	// [public key length, public key..., address length, address as hex string..., chaincode (optional)]
+	res := append([]byte{byte(len(publicKey))}, publicKey...)
+	res = append(res, byte(schema.ADDRESS_LENGTH*2))
+	res = append(res, hex.EncodeToString(address[:])...)
+	if p2 == 0x01 {
+		res = append(res, key.chainCode...)
+	}
+
+	return res, nil
+}
+
+type personalMessageContext struct {
+	path    []uint32
+	length  int
+	message []byte
+}
+
+func (e *ethereumAppEmulator) signPersonalMessage(p1 uint8, data []byte) ([]byte, error) {
+	switch p1 {
+	case eth.P1_FIRST_CHUNK:
+		path, rest, err := parsePath(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) < 4 {
+			return nil, reject(adpu.SW_INCORRECT_DATA, "missing message length")
+		}
+		e.personalMessage = &personalMessageContext{
+			path:   path,
+			length: int(binary.BigEndian.Uint32(rest[:4])),
+		}
+		data = rest[4:]
+	case eth.P1_MORE_CHUNK:
+		if e.personalMessage == nil {
+			return nil, reject(adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, "no personal message is being signed")
+		}
+	default:
+		return nil, reject(adpu.SW_INCORRECT_P1_P2, "unknown P1 0x%02x", p1)
+	}
+
+	msg := e.personalMessage
+	msg.message = append(msg.message, data...)
+	if len(msg.message) > msg.length {
+		e.personalMessage = nil
+		return nil, reject(adpu.SW_INCORRECT_DATA, "message is longer than declared length %d", msg.length)
+	}
+	if len(msg.message) < msg.length {
+		return nil, nil
+	}
+	e.personalMessage = nil
+
+	// ERC-191 version 0x45
+	hash := schema.Keccak256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(msg.length)), msg.message)
+
+	return e.sign(msg.path, hash, v27)
+}
+
+func (e *ethereumAppEmulator) eip712Context() *eip712Context {
+	if e.eip712 == nil {
+		e.eip712 = newEIP712Context()
+	}
+
+	return e.eip712
+}
+
+func (e *ethereumAppEmulator) signEIP712(p1, p2 uint8, data []byte) ([]byte, error) {
+	if p1 != 0x00 {
+		return nil, reject(adpu.SW_INCORRECT_P1_P2, "unknown P1 0x%02x", p1)
+	}
+	path, rest, err := parsePath(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var domainHash, messageHash []byte
+	switch p2 {
+	case 0x00:
+		// Hashes are computed by client, [domain separator hash (32 bytes), message hash (32 bytes)]
+		if len(rest) != 64 {
+			return nil, reject(adpu.SW_INCORRECT_DATA, "expected 64 bytes of hashes, got %d", len(rest))
+		}
+		domainHash, messageHash = rest[:32], rest[32:]
+	case 0x01:
+		// Hashes are computed from struct definitions and data sent earlier
+		ctx := e.eip712
+		e.eip712 = nil
+		if ctx == nil || !ctx.ready() {
+			return nil, reject(adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, "domain or message is not fully received")
+		}
+		domainHash, messageHash = ctx.domainHash, ctx.messageHash
+	default:
+		return nil, reject(adpu.SW_INCORRECT_P1_P2, "unknown P2 0x%02x", p2)
+	}
+
+	return e.sign(path, schema.Keccak256([]byte{0x19, 0x01}, domainHash, messageHash), v27)
+}
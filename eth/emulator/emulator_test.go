@@ -0,0 +1,154 @@
+package emulator_test
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/emulator"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func TestNewProtocol_BIP32(t *testing.T) {
+	// Test vector 1 of BIP-32
+	seed := mustDecodeHex("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		name      string
+		path      string
+		publicKey string
+		chainCode string
+	}{
+		{
+			name:      "Success_Hardened",
+			path:      "m'/0'",
+			publicKey: "035a784662a4a20a65bf6aab9ae98a6c068a81c52e4b032c0fb5400c706cfccc56",
+			chainCode: "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+		},
+		{
+			name:      "Success_NonHardened",
+			path:      "m'/0'/1",
+			publicKey: "03501e454bf00751f24b1b489aa925215d66af2234e3891c3b21a52bedb3cd711c",
+			chainCode: "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+		},
+	}
+
+	proto, err := emulator.NewProtocol(seed, slog.Default())
+	assert.NoError(t, err)
+	app := eth.NewEthereumApp(proto, slog.Default())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := app.GetAddress(context.Background(), test.path, false, true, 0)
+			assert.NoError(t, err)
+
+			pub, err := secp256k1.ParsePubKey(res.PublicKey[:])
+			assert.NoError(t, err)
+			assert.Equal(t, test.publicKey, hex.EncodeToString(pub.SerializeCompressed()))
+			assert.Equal(t, test.chainCode, hex.EncodeToString(res.Chaincode[:]))
+			assert.Equal(t, res.PublicKey.Address(), res.Address)
+		})
+	}
+}
+
+func TestProtocol_Exchange(t *testing.T) {
+	proto, err := emulator.NewProtocol(mustDecodeHex("000102030405060708090a0b0c0d0e0f"), slog.Default())
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		command []byte
+		res     []byte
+	}{
+		{
+			name:    "Success_GetConfiguration",
+			command: []byte{0xE0, 0x06, 0x00, 0x00, 0x00},
+			res:     []byte{emulator.APP_CONFIGURATION_FLAGS, emulator.APP_VERSION_MAJOR, emulator.APP_VERSION_MINOR, emulator.APP_VERSION_PATCH, 0x90, 0x00},
+		},
+		{
+			name:    "Success_ExtendedLength",
+			command: []byte{0xE0, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00},
+			res:     []byte{emulator.APP_CONFIGURATION_FLAGS, emulator.APP_VERSION_MAJOR, emulator.APP_VERSION_MINOR, emulator.APP_VERSION_PATCH, 0x90, 0x00},
+		},
+		{
+			name:    "Error_MalformedCommand",
+			command: []byte{0xE0, 0x06, 0x00, 0x00, 0x05, 0x01},
+			res:     []byte{0x67, 0x00},
+		},
+		{
+			name:    "Error_UnknownCLA",
+			command: []byte{0xB0, 0x01, 0x00, 0x00, 0x00},
+			res:     []byte{0x6E, 0x00},
+		},
+		{
+			name:    "Error_UnknownINS",
+			command: []byte{0xE0, 0xFF, 0x00, 0x00, 0x00},
+			res:     []byte{0x6D, 0x00},
+		},
+		{
+			name:    "Error_InvalidPath",
+			command: []byte{0xE0, 0x02, 0x00, 0x00, 0x03, 0x01, 0x80, 0x00},
+			res:     []byte{0x6A, 0x80},
+		},
+		{
+			name:    "Error_MoreChunkWithoutFirstChunk",
+			command: []byte{0xE0, 0x04, 0x80, 0x00, 0x01, 0x80},
+			res:     []byte{0x69, 0x85},
+		},
+		{
+			name:    "Error_SignEIP712WithoutData",
+			command: []byte{0xE0, 0x0C, 0x00, 0x01, 0x05, 0x01, 0x80, 0x00, 0x00, 0x2C},
+			res:     []byte{0x69, 0x85},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := proto.Exchange(context.Background(), test.command)
+			assert.NoError(t, err)
+			assert.Equal(t, test.res, res)
+		})
+	}
+}
+
+func TestProtocol_SignTransaction_ChunkEndsAfterData(t *testing.T) {
+	proto, err := emulator.NewProtocol(mustDecodeHex("000102030405060708090a0b0c0d0e0f"), slog.Default())
+	assert.NoError(t, err)
+	ctx := context.Background()
+	path := []byte{0x01, 0x80, 0x00, 0x00, 0x2C}
+	// rlp([nonce=1, gasPrice=2, gas=3, to=0x11..., value=4, data=0xAABB, chainID=137, 0, 0])
+	fieldsBeforeChainID := append([]byte{0x01, 0x02, 0x03, 0x94}, append(make([]byte, 20), 0x04, 0x82, 0xAA, 0xBB)...)
+	tx := append([]byte{0xC0 + byte(len(fieldsBeforeChainID)) + 4}, fieldsBeforeChainID...)
+	tx = append(tx, 0x81, 0x89, 0x80, 0x80)
+
+	// Chunk ends right after `data` field, so the app signs it without chain ID
+	res, sw, err := proto.Send(ctx, eth.ADPU_CLA, eth.ADPU_INS_SIGN_TRANSACTION, eth.P1_FIRST_CHUNK, 0x00, append(path, tx[:len(tx)-4]...))
+	assert.NoError(t, err)
+	assert.Equal(t, adpu.SW_OK, sw)
+	assert.Len(t, res, 65)
+	assert.Contains(t, []byte{27, 28}, res[0])
+
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(tx[:len(tx)-4])
+	_, _, err = ecdsa.RecoverCompact(res, hasher.Sum(nil))
+	assert.NoError(t, err)
+
+	// The rest of transaction is rejected as there is no transaction being signed
+	_, sw, err = proto.Send(ctx, eth.ADPU_CLA, eth.ADPU_INS_SIGN_TRANSACTION, eth.P1_MORE_CHUNK, 0x00, tx[len(tx)-4:])
+	assert.NoError(t, err)
+	assert.Equal(t, adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, sw)
+}
@@ -0,0 +1,191 @@
+package emulator
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/rlp"
+	"github.com/ntchjb/ledger-go/eth/schema"
+)
+
+const (
+	// Number of fields of legacy transaction before chain ID, [nonce, gasprice, startgas, to, value, data]
+	LEGACY_TX_FIELDS_BEFORE_CHAIN_ID int = 6
+)
+
+type txContext struct {
+	path []uint32
+	// Unsigned transaction received so far
+	data []byte
+}
+
+// Decode header of RLP item at the beginning of `data`
+//
+// It returns false if the header is not fully received yet.
+func decodeRLPHeader(data []byte) (headerLength, contentLength int, isList bool, ok bool, err error) {
+	if len(data) == 0 {
+		return 0, 0, false, false, nil
+	}
+	prefix := data[0]
+	var offset byte
+	switch {
+	case prefix <= 0x7F:
+		return 0, 1, false, true, nil
+	case prefix <= 0xB7:
+		return 1, int(prefix - 0x80), false, true, nil
+	case prefix <= 0xBF:
+		offset = 0xB7
+	case prefix <= 0xF7:
+		return 1, int(prefix - 0xC0), true, true, nil
+	default:
+		offset = 0xF7
+		isList = true
+	}
+
+	lengthOfLength := int(prefix - offset)
+	if lengthOfLength > 4 {
+		return 0, 0, false, false, fmt.Errorf("RLP item is too long, length of length is %d", lengthOfLength)
+	}
+	if len(data) < 1+lengthOfLength {
+		return 0, 0, false, false, nil
+	}
+	var lengthBytes [4]byte
+	copy(lengthBytes[4-lengthOfLength:], data[1:1+lengthOfLength])
+
+	return 1 + lengthOfLength, int(binary.BigEndian.Uint32(lengthBytes[:])), isList, true, nil
+}
+
+// Parse RLP list header of transaction payload
+//
+// It returns total length of transaction, or 0 if the header is not fully received yet.
+func (t *txContext) totalLength() (typeLength, headerLength, total int, err error) {
+	if len(t.data) == 0 {
+		return 0, 0, 0, nil
+	}
+	if t.data[0] < 0xC0 {
+		txType := schema.TxType(t.data[0])
+		if txType != schema.TX_TYPE_ACCESS_LIST && txType != schema.TX_TYPE_DYNAMIC_FEE {
+			return 0, 0, 0, fmt.Errorf("unsupported transaction type 0x%02x", t.data[0])
+		}
+		typeLength = 1
+	}
+
+	headerLength, contentLength, isList, ok, err := decodeRLPHeader(t.data[typeLength:])
+	if err != nil || !ok {
+		return 0, 0, 0, err
+	}
+	if !isList {
+		return 0, 0, 0, fmt.Errorf("transaction payload is not an RLP list, prefix 0x%02x", t.data[typeLength])
+	}
+
+	return typeLength, headerLength, typeLength + headerLength + contentLength, nil
+}
+
+// Check whether the app would start signing after the data received so far.
+//
+// Like the app, a legacy transaction is considered complete if a chunk ends right after `data` field,
+// even if chain ID follows, in which case it's signed as a pre-EIP-155 transaction.
+// https://github.com/LedgerHQ/app-ethereum/issues/409
+func (t *txContext) complete() (bool, error) {
+	typeLength, headerLength, total, err := t.totalLength()
+	if err != nil || total == 0 {
+		return false, err
+	}
+	if len(t.data) > total {
+		return false, fmt.Errorf("received %d bytes, but transaction length is %d", len(t.data), total)
+	}
+	if len(t.data) == total {
+		return true, nil
+	}
+	if typeLength > 0 {
+		return false, nil
+	}
+
+	// Count legacy tx fields fully received in this chunk
+	fields := t.data[headerLength:]
+	count := 0
+	for len(fields) > 0 {
+		fieldHeaderLength, contentLength, _, ok, err := decodeRLPHeader(fields)
+		if err != nil {
+			return false, err
+		}
+		if !ok || len(fields) < fieldHeaderLength+contentLength {
+			return false, nil
+		}
+		fields = fields[fieldHeaderLength+contentLength:]
+		count++
+	}
+
+	return count == LEGACY_TX_FIELDS_BEFORE_CHAIN_ID, nil
+}
+
+// Compute V of legacy transaction signature as the app does.
+// The app only uses the first 4 bytes of chain ID, and returns only the lowest byte of V.
+func (t *txContext) legacyV() (func(parity byte) byte, error) {
+	// Chain ID is not received when the transaction is cut off right after `data` field
+	if _, _, total, _ := t.totalLength(); total > len(t.data) {
+		return v27, nil
+	}
+	item, _, err := rlp.Decode(t.data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode legacy transaction: %w", err)
+	}
+	if len(item.List) <= LEGACY_TX_FIELDS_BEFORE_CHAIN_ID {
+		return v27, nil
+	}
+
+	chainID := item.List[LEGACY_TX_FIELDS_BEFORE_CHAIN_ID].Data
+	var chainIDBytes [4]byte
+	truncated := chainID[:min(4, len(chainID))]
+	copy(chainIDBytes[4-len(truncated):], truncated)
+	truncatedChainID := binary.BigEndian.Uint32(chainIDBytes[:])
+
+	return func(parity byte) byte {
+		return byte(truncatedChainID*2 + 35 + uint32(parity))
+	}, nil
+}
+
+func (e *ethereumAppEmulator) signTransaction(p1 uint8, data []byte) ([]byte, error) {
+	switch p1 {
+	case eth.P1_FIRST_CHUNK:
+		path, rest, err := parsePath(data)
+		if err != nil {
+			return nil, err
+		}
+		e.tx = &txContext{
+			path: path,
+			data: append([]byte{}, rest...),
+		}
+	case eth.P1_MORE_CHUNK:
+		if e.tx == nil {
+			return nil, reject(adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, "no transaction is being signed")
+		}
+		e.tx.data = append(e.tx.data, data...)
+	default:
+		return nil, reject(adpu.SW_INCORRECT_P1_P2, "unknown P1 0x%02x", p1)
+	}
+
+	tx := e.tx
+	complete, err := tx.complete()
+	if err != nil {
+		e.tx = nil
+		return nil, reject(adpu.SW_INCORRECT_DATA, "invalid transaction: %v", err)
+	}
+	if !complete {
+		return nil, nil
+	}
+	e.tx = nil
+
+	v := func(parity byte) byte {
+		return parity
+	}
+	if tx.data[0] >= 0xC0 {
+		if v, err = tx.legacyV(); err != nil {
+			return nil, reject(adpu.SW_INCORRECT_DATA, "invalid transaction: %v", err)
+		}
+	}
+
+	return e.sign(tx.path, schema.Keccak256(tx.data), v)
+}
@@ -6,9 +6,9 @@ import (
 	"log/slog"
 
 	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth/policy"
 	"github.com/ntchjb/ledger-go/eth/schema"
 	"github.com/ntchjb/ledger-go/eth/schema/eip712"
-	"github.com/ntchjb/ledger-go/log"
 )
 
 type EthereumApp interface {
@@ -18,6 +18,8 @@ type EthereumApp interface {
 	GetAddress(ctx context.Context, bip32Path string, needHWConfirm bool, chaincode bool, chainID uint64) (schema.GetAddressResponse, error)
 	// Sign a raw transaction and get signature. `rawTx` is RLP-encoded Ethereum transaction payload (EIP-155 or EIP-2718 TransactionPayload)
 	SignTransaction(ctx context.Context, bip32Path string, rawTx []byte) (schema.SignDataResponse, error)
+	// Sign a typed transaction i.e. `*schema.DynamicFeeTx`, which is encoded to raw transaction for `SignTransaction`
+	SignTypedTransaction(ctx context.Context, bip32Path string, tx schema.Transaction) (schema.SignDataResponse, error)
 	// Sign a personal message following ERC-191 standard
 	// The message is usually a string, but it supports arbitrary data
 	// Signature V value can be either `27` (even), or `28` (odd)
@@ -106,13 +108,46 @@ type EthereumApp interface {
 type ethereumAppImpl struct {
 	proto  adpu.Protocol
 	logger *slog.Logger
+	tracer adpu.Tracer
+	policy policy.Policy
+	// Redaction of payloads in debug logs
+	redaction *adpu.RedactionPolicy
+	// Verify signatures before returning them
+	verifySignatures bool
 }
 
-func NewEthereumApp(proto adpu.Protocol, logger *slog.Logger) EthereumApp {
-	return &ethereumAppImpl{
+type EthereumAppOption func(e *ethereumAppImpl)
+
+func NewEthereumApp(proto adpu.Protocol, logger *slog.Logger, opts ...EthereumAppOption) EthereumApp {
+	e := &ethereumAppImpl{
 		proto:  proto,
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	var app EthereumApp = e
+	if e.verifySignatures {
+		app = &verifyingEthereumApp{
+			app:       app,
+			addresses: make(map[string]schema.Address),
+		}
+	}
+	if e.policy != nil {
+		app = &policyEthereumApp{
+			app:    app,
+			policy: e.policy,
+		}
+	}
+	if e.tracer != nil {
+		app = &tracedEthereumApp{
+			app:    app,
+			tracer: e.tracer,
+		}
+	}
+
+	return app
 }
 
 func (e *ethereumAppImpl) GetConfiguration(ctx context.Context) (schema.GetConfigurationResponse, error) {
@@ -124,7 +159,7 @@ func (e *ethereumAppImpl) GetConfiguration(ctx context.Context) (schema.GetConfi
 		&adpu.EmptyData{},
 		&conf,
 	); err != nil {
-		return conf, fmt.Errorf("unable to send get configuration to device: %w", err)
+		return conf, fmt.Errorf("unable to send get configuration to device: %w", translateError(err))
 	}
 
 	return conf, nil
@@ -148,12 +183,21 @@ func (e *ethereumAppImpl) GetAddress(ctx context.Context, bip32Path string, need
 	e.logger.Debug("Get address request", "bip32Path", req.BIP32Path, "chainID", chainID)
 
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_GET_PUBLIC_KEY, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send get address to device: %w", err)
+		return res, fmt.Errorf("unable to send get address to device: %w", translateError(err))
 	}
 
 	return res, nil
 }
 
+func (e *ethereumAppImpl) SignTypedTransaction(ctx context.Context, bip32Path string, tx schema.Transaction) (schema.SignDataResponse, error) {
+	rawTx, err := tx.MarshalUnsigned()
+	if err != nil {
+		return schema.SignDataResponse{}, fmt.Errorf("unable to encode typed tx: %w", err)
+	}
+
+	return e.SignTransaction(ctx, bip32Path, rawTx)
+}
+
 func (e *ethereumAppImpl) SignTransaction(ctx context.Context, bip32Path string, rawTx []byte) (schema.SignDataResponse, error) {
 	req := schema.SignTxRequest{
 		BIP32Path: schema.BIP32Path(bip32Path),
@@ -164,13 +208,13 @@ func (e *ethereumAppImpl) SignTransaction(ctx context.Context, bip32Path string,
 	var sw uint16
 	var err error
 
-	e.logger.Debug("Sign tx request", "bip32Path", req.BIP32Path, "rawTx", log.HexDisplay(req.Data))
+	e.logger.Debug("Sign tx request", "bip32Path", req.BIP32Path, "rawTx", e.payloadValue(ADPU_INS_SIGN_TRANSACTION, req.Data))
 
 	txInfo, err := schema.DecodeTxInfo(rawTx)
 	if err != nil {
 		return res, fmt.Errorf("unable to decode raw tx info: %w", err)
 	}
-	e.logger.Debug("Tx info", "type", txInfo.TxType, "chainID", txInfo.ChainID, "chainOffset", txInfo.ChainIDOffset, "to", log.HexDisplay(txInfo.To[:]), "data", log.HexDisplay(txInfo.Data))
+	e.logger.Debug("Tx info", "type", txInfo.TxType, "chainID", txInfo.ChainID, "chainOffset", txInfo.ChainIDOffset, "to", e.payloadValue(ADPU_INS_SIGN_TRANSACTION, txInfo.To[:]), "data", e.payloadValue(ADPU_INS_SIGN_TRANSACTION, txInfo.Data))
 	if int(txInfo.TxType) >= len(schema.SupportedTxTypes) || !schema.SupportedTxTypes[txInfo.TxType] {
 		return res, fmt.Errorf("unsupported transaction type: 0x%X", txInfo.TxType)
 	}
@@ -181,7 +225,7 @@ func (e *ethereumAppImpl) SignTransaction(ctx context.Context, bip32Path string,
 	}
 	pathLength := req.BIP32Path.Len()
 
-	for offset := 0; offset < len(reqBuf); {
+	for chunk, offset := 0, 0; offset < len(reqBuf); chunk++ {
 		chunkSize := 255
 
 		if offset+chunkSize > len(reqBuf) {
@@ -201,7 +245,7 @@ func (e *ethereumAppImpl) SignTransaction(ctx context.Context, bip32Path string,
 			chunkSize--
 		}
 
-		e.logger.Debug("Building a chunk", "offset", offset, "chunkSize", chunkSize, "chunk", log.HexDisplay(reqBuf[offset:offset+chunkSize]))
+		e.logger.Debug("Building a chunk", "offset", offset, "chunkSize", chunkSize, "chunk", e.payloadValue(ADPU_INS_SIGN_TRANSACTION, reqBuf[offset:offset+chunkSize]))
 
 		p1 := P1_FIRST_CHUNK
 		var p2 uint8 // unused
@@ -209,12 +253,12 @@ func (e *ethereumAppImpl) SignTransaction(ctx context.Context, bip32Path string,
 			p1 = P1_MORE_CHUNK
 		}
 
-		resBuf, sw, err = e.proto.Send(ctx, ADPU_CLA, ADPU_INS_SIGN_TRANSACTION, p1, p2, reqBuf[offset:offset+chunkSize])
+		resBuf, sw, err = e.proto.Send(e.chunkContext(ctx, chunk), ADPU_CLA, ADPU_INS_SIGN_TRANSACTION, p1, p2, reqBuf[offset:offset+chunkSize])
 		if err != nil {
 			return res, fmt.Errorf("unable to send ADPU command to sign transaction: %w", err)
 		}
 		if sw != adpu.SW_OK {
-			return res, fmt.Errorf("SW status: %s, %w", adpu.SWMessage[sw], adpu.ErrSWNotOK)
+			return res, translateError(adpu.NewStatusError(sw, ADPU_CLA, ADPU_INS_SIGN_TRANSACTION, p1, p2))
 		}
 
 		offset += chunkSize
@@ -242,14 +286,14 @@ func (e *ethereumAppImpl) SignPersonalMessage(ctx context.Context, bip32Path str
 	var resBuf []byte
 	var sw uint16
 
-	e.logger.Debug("Sign personal message", "bip32Path", req.BIP32Path, "message", log.HexDisplay(message))
+	e.logger.Debug("Sign personal message", "bip32Path", req.BIP32Path, "message", e.payloadValue(ADPU_INS_SIGN_PERSONAL_MESSAGE, message))
 
 	reqBuf, err := adpu.Marshal(&req)
 	if err != nil {
 		return res, fmt.Errorf("unable to marshal sign personal message request: %w", err)
 	}
 
-	for offset := 0; offset < len(reqBuf); {
+	for chunk, offset := 0, 0; offset < len(reqBuf); chunk++ {
 		chunkSize := 255
 		if offset+chunkSize > len(reqBuf) {
 			chunkSize = len(reqBuf) - offset
@@ -259,14 +303,14 @@ func (e *ethereumAppImpl) SignPersonalMessage(ctx context.Context, bip32Path str
 		if offset > 0 {
 			p1 = P1_MORE_CHUNK
 		}
-		e.logger.Debug("Building a chunk", "offset", offset, "chunkSize", chunkSize, "chunk", log.HexDisplay(reqBuf[offset:offset+chunkSize]))
+		e.logger.Debug("Building a chunk", "offset", offset, "chunkSize", chunkSize, "chunk", e.payloadValue(ADPU_INS_SIGN_PERSONAL_MESSAGE, reqBuf[offset:offset+chunkSize]))
 
-		resBuf, sw, err = e.proto.Send(ctx, ADPU_CLA, ADPU_INS_SIGN_PERSONAL_MESSAGE, p1, p2, reqBuf[offset:offset+chunkSize])
+		resBuf, sw, err = e.proto.Send(e.chunkContext(ctx, chunk), ADPU_CLA, ADPU_INS_SIGN_PERSONAL_MESSAGE, p1, p2, reqBuf[offset:offset+chunkSize])
 		if err != nil {
 			return res, fmt.Errorf("unable to send ADPU command to sign personal message: %w", err)
 		}
 		if sw != adpu.SW_OK {
-			return res, fmt.Errorf("SW status: %s, %w", adpu.SWMessage[sw], adpu.ErrSWNotOK)
+			return res, translateError(adpu.NewStatusError(sw, ADPU_CLA, ADPU_INS_SIGN_PERSONAL_MESSAGE, p1, p2))
 		}
 
 		offset += chunkSize
@@ -296,9 +340,9 @@ func (e *ethereumAppImpl) SignEIP712MessageHash(ctx context.Context, bip32Path s
 		return res, fmt.Errorf("unexpected hashed domain separator length, expected 32, got %d", len(messageHash))
 	}
 
-	e.logger.Debug("Sign EIP712 message", "bip32Path", req.BIP32Path, "domain", log.HexDisplay(req.HashedDomainSeparator[:]), "message", log.HexDisplay(req.HashedMessage[:]))
+	e.logger.Debug("Sign EIP712 message", "bip32Path", req.BIP32Path, "domain", e.payloadValue(ADPU_INS_SIGN_EIP712, req.HashedDomainSeparator[:]), "message", e.payloadValue(ADPU_INS_SIGN_EIP712, req.HashedMessage[:]))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_SIGN_EIP712, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send sign EIP712 command to device: %w", err)
+		return res, fmt.Errorf("unable to send sign EIP712 command to device: %w", translateError(err))
 	}
 
 	return res, nil
@@ -314,7 +358,7 @@ func (e *ethereumAppImpl) ETH2GetPublicKey(ctx context.Context, bip32Path string
 
 	e.logger.Debug("Get ETH2 public key", "bip32Path", bip32Path, "confirm", needHWConfirm)
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_ETH2_GET_PUBLIC_KEY, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send ETH2 get public key command to device: %w", err)
+		return res, fmt.Errorf("unable to send ETH2 get public key command to device: %w", translateError(err))
 	}
 
 	return res, nil
@@ -327,7 +371,7 @@ func (e *ethereumAppImpl) ETH2SetWithdrawalIndex(ctx context.Context, index uint
 
 	e.logger.Debug("Set ETH2 withdrawal index", "index", index)
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_ETH2_SET_WITHDRAWAL_INDEX, p1, p2, &req, &res); err != nil {
-		return fmt.Errorf("unable to send ETH2 set withdrawal index command to device: %w", err)
+		return fmt.Errorf("unable to send ETH2 set withdrawal index command to device: %w", translateError(err))
 	}
 
 	return nil
@@ -343,7 +387,7 @@ func (e *ethereumAppImpl) GetPrivacyPublicKey(ctx context.Context, bip32Path str
 
 	e.logger.Debug("Get privacy public key", "bip32Path", bip32Path, "confirm", needHWConfirm)
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_PRIVACY_OPERATION, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send get privacy public key command to device: %w", err)
+		return res, fmt.Errorf("unable to send get privacy public key command to device: %w", translateError(err))
 	}
 
 	return res, nil
@@ -360,9 +404,9 @@ func (e *ethereumAppImpl) GetPrivacySharedSecret(ctx context.Context, bip32Path
 		p1 = P1_WITH_CONFIRM
 	}
 
-	e.logger.Debug("Get shared secret key", "bip32Path", bip32Path, "confirm", needHWConfirm, "remotePublicKey", log.HexDisplay(remotePublicKey))
+	e.logger.Debug("Get shared secret key", "bip32Path", bip32Path, "confirm", needHWConfirm, "remotePublicKey", e.payloadValue(ADPU_INS_PRIVACY_OPERATION, remotePublicKey))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_PRIVACY_OPERATION, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send get shared secret command to device: %w", err)
+		return res, fmt.Errorf("unable to send get shared secret command to device: %w", translateError(err))
 	}
 
 	return res, nil
@@ -373,9 +417,9 @@ func (e *ethereumAppImpl) ProvideERC20Information(ctx context.Context, info []by
 	var res schema.ProvideERC20InfoResponse
 	var p1, p2 uint8
 
-	e.logger.Debug("Provide ERC20 information", "info", log.HexDisplay(info))
+	e.logger.Debug("Provide ERC20 information", "info", e.payloadValue(ADPU_INS_PROVIDE_ERC20_INFO, info))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_PROVIDE_ERC20_INFO, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send provide ERC20 information command to device: %w", err)
+		return res, fmt.Errorf("unable to send provide ERC20 information command to device: %w", translateError(err))
 	}
 
 	return res, nil
@@ -389,7 +433,7 @@ func (e *ethereumAppImpl) GetChallenge(ctx context.Context) (schema.Challenge, e
 
 	e.logger.Debug("Get challenge data")
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_GET_CHALLENGE, p1, p2, &req, &res); err != nil {
-		return res, fmt.Errorf("unable to send get challenge data command to device: %w", err)
+		return res, fmt.Errorf("unable to send get challenge data command to device: %w", translateError(err))
 	}
 
 	return res, nil
@@ -404,7 +448,7 @@ func (e *ethereumAppImpl) ProvideDomainNameInformation(ctx context.Context, info
 		return fmt.Errorf("unable to marshal domain name blob: %w", err)
 	}
 
-	for offset := 0; offset < len(payload); {
+	for chunk, offset := 0, 0; offset < len(payload); chunk++ {
 		p1, p2 := P1_CS_FOLLOWING_CHUNK, uint8(0x00)
 		chunkSize := 255
 		if offset+chunkSize > len(payload) {
@@ -415,9 +459,9 @@ func (e *ethereumAppImpl) ProvideDomainNameInformation(ctx context.Context, info
 		}
 		req := schema.RawRequest(payload[offset : offset+chunkSize])
 
-		e.logger.Debug("Provide domain name info", "blobWithLength", log.HexDisplay(payload))
-		if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_PROVIDE_DOMAIN_NAME, p1, p2, &req, &res); err != nil {
-			return fmt.Errorf("unable to send provide domain name information command to device: %w", err)
+		e.logger.Debug("Provide domain name info", "blobWithLength", e.payloadValue(ADPU_INS_PROVIDE_DOMAIN_NAME, payload))
+		if err := adpu.Send(e.chunkContext(ctx, chunk), e.proto, ADPU_CLA, ADPU_INS_PROVIDE_DOMAIN_NAME, p1, p2, &req, &res); err != nil {
+			return fmt.Errorf("unable to send provide domain name information command to device: %w", translateError(err))
 		}
 
 		offset += chunkSize
@@ -432,9 +476,9 @@ func (e *ethereumAppImpl) ProvideNFTInformation(ctx context.Context, info []byte
 
 	var p1, p2 uint8
 
-	e.logger.Debug("Provide NFT info", "info", log.HexDisplay(info))
+	e.logger.Debug("Provide NFT info", "info", e.payloadValue(ADPU_INS_PROVIDE_NFT_INFO, info))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_PROVIDE_NFT_INFO, p1, p2, &req, &res); err != nil {
-		return fmt.Errorf("unable to send provide NFT info command to device: %w", err)
+		return fmt.Errorf("unable to send provide NFT info command to device: %w", translateError(err))
 	}
 
 	return nil
@@ -446,9 +490,9 @@ func (e *ethereumAppImpl) SetPlugin(ctx context.Context, info []byte) error {
 
 	var p1, p2 uint8
 
-	e.logger.Debug("Set plugin", "info", log.HexDisplay(info))
+	e.logger.Debug("Set plugin", "info", e.payloadValue(ADPU_INS_SET_PLUGIN, info))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_SET_PLUGIN, p1, p2, &req, &res); err != nil {
-		return fmt.Errorf("unable to send set plugin command to device: %w", err)
+		return fmt.Errorf("unable to send set plugin command to device: %w", translateError(err))
 	}
 
 	return nil
@@ -460,9 +504,9 @@ func (e *ethereumAppImpl) SetExternalPlugin(ctx context.Context, payload []byte,
 
 	var p1, p2 uint8
 
-	e.logger.Debug("Set external plugin", "info", log.HexDisplay(req))
+	e.logger.Debug("Set external plugin", "info", e.payloadValue(ADPU_INS_SET_EXTERNAL_PLUGIN, req))
 	if err := adpu.Send(ctx, e.proto, ADPU_CLA, ADPU_INS_SET_EXTERNAL_PLUGIN, p1, p2, &req, &res); err != nil {
-		return fmt.Errorf("unable to send set external plugin to device: %w", err)
+		return fmt.Errorf("unable to send set external plugin to device: %w", translateError(err))
 	}
 
 	return nil
@@ -0,0 +1,479 @@
+package eth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/emulator"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testBIP32Path = "m'/44'/60'/0'/0/0"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func rlpHeader(offset byte, length int) []byte {
+	if length <= 55 {
+		return []byte{offset + byte(length)}
+	}
+	lengthBytes := rlpUintBytes(uint64(length))
+
+	return append([]byte{offset + 55 + byte(len(lengthBytes))}, lengthBytes...)
+}
+
+func rlpUintBytes(num uint64) []byte {
+	b := binary.BigEndian.AppendUint64(nil, num)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+
+	return b
+}
+
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpUint(num uint64) []byte {
+	return rlpString(rlpUintBytes(num))
+}
+
+func rlpList(items ...[]byte) []byte {
+	content := bytes.Join(items, nil)
+
+	return append(rlpHeader(0xC0, len(content)), content...)
+}
+
+func newTestEthereumApp(t *testing.T) (eth.EthereumApp, schema.Address) {
+	proto, err := emulator.NewProtocol(mustDecodeHex("5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"), slog.Default())
+	assert.NoError(t, err)
+	app := eth.NewEthereumApp(proto, slog.Default())
+
+	res, err := app.GetAddress(context.Background(), testBIP32Path, false, false, 0)
+	assert.NoError(t, err)
+
+	return app, res.Address
+}
+
+func recoverAddress(t *testing.T, hash []byte, sig schema.SignDataResponse, parity byte) schema.Address {
+	compact := append(append([]byte{27 + parity}, sig.R[:]...), sig.S[:]...)
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if !assert.NoError(t, err) {
+		return schema.Address{}
+	}
+	publicKey := schema.PublicKey(pub.SerializeUncompressed())
+
+	return publicKey.Address()
+}
+
+func TestEthereumApp_GetConfiguration(t *testing.T) {
+	app, _ := newTestEthereumApp(t)
+
+	conf, err := app.GetConfiguration(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, conf.ArbitraryDataEnabled)
+	assert.Equal(t, "1.13.0", conf.Version)
+}
+
+func TestEthereumApp_GetAddress(t *testing.T) {
+	app, address := newTestEthereumApp(t)
+
+	res, err := app.GetAddress(context.Background(), testBIP32Path, true, true, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, address, res.Address)
+	assert.Equal(t, res.PublicKey.Address(), res.Address)
+	assert.NotEqual(t, schema.ChainCode{}, res.Chaincode)
+
+	other, err := app.GetAddress(context.Background(), "m'/44'/60'/0'/0/1", false, false, 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, address, other.Address)
+}
+
+func TestEthereumApp_GetChallenge(t *testing.T) {
+	app, _ := newTestEthereumApp(t)
+
+	_, err := app.GetChallenge(context.Background())
+	assert.NoError(t, err)
+}
+
+func legacyTx(data []byte, chainID ...uint64) []byte {
+	to := mustDecodeHex("d8da6bf26964af9d7eed9e03e53415d37aa96045")
+	items := [][]byte{rlpUint(7), rlpUint(20_000_000_000), rlpUint(21000), rlpString(to), rlpUint(1_000_000_000_000_000), rlpString(data)}
+	for _, id := range chainID {
+		items = append(items, rlpUint(id), rlpUint(0), rlpUint(0))
+	}
+
+	return rlpList(items...)
+}
+
+// Legacy tx whose `data` field ends right at the end of the first ADPU chunk
+func legacyTxAtChunkBoundary(t *testing.T) []byte {
+	pathLength := 1 + 5*4
+	for dataLength := 0; dataLength < 255; dataLength++ {
+		rawTx := legacyTx(make([]byte, dataLength), 1)
+		txInfo, err := schema.DecodeTxInfo(rawTx)
+		assert.NoError(t, err)
+		if pathLength+txInfo.ChainIDOffset == 255 {
+			return rawTx
+		}
+	}
+	t.Fatal("unable to build tx at chunk boundary")
+
+	return nil
+}
+
+func TestEthereumApp_SignTransaction(t *testing.T) {
+	app, address := newTestEthereumApp(t)
+	to := mustDecodeHex("d8da6bf26964af9d7eed9e03e53415d37aa96045")
+	longData := bytes.Repeat([]byte{0xAB}, 600)
+
+	tests := []struct {
+		name  string
+		rawTx []byte
+		// V = vOffset + y_parity
+		vOffset uint64
+	}{
+		{
+			name:    "Success_Legacy_PreEIP155",
+			rawTx:   legacyTx(nil),
+			vOffset: 27,
+		},
+		{
+			name:    "Success_Legacy_Mainnet",
+			rawTx:   legacyTx([]byte{0x01, 0x02}, 1),
+			vOffset: 1*2 + 35,
+		},
+		{
+			name:    "Success_Legacy_Polygon",
+			rawTx:   legacyTx(nil, 137),
+			vOffset: 137*2 + 35,
+		},
+		{
+			name:    "Success_Legacy_FourBytesChainID",
+			rawTx:   legacyTx(nil, 0x8000_0089),
+			vOffset: 0x8000_0089*2 + 35,
+		},
+		{
+			name:    "Success_Legacy_FiveBytesChainID",
+			rawTx:   legacyTx(nil, 0x01_0000_0089),
+			vOffset: 0x01_0000_0089*2 + 35,
+		},
+		{
+			name:    "Success_Legacy_MultipleChunks",
+			rawTx:   legacyTx(longData, 56),
+			vOffset: 56*2 + 35,
+		},
+		{
+			name:    "Success_Legacy_ChainIDAtChunkBoundary",
+			rawTx:   legacyTxAtChunkBoundary(t),
+			vOffset: 1*2 + 35,
+		},
+		{
+			name:  "Success_AccessList",
+			rawTx: append([]byte{0x01}, rlpList(rlpUint(1), rlpUint(3), rlpUint(1_000_000_000), rlpUint(50000), rlpString(to), rlpUint(0), rlpString(nil), rlpList(rlpList(rlpString(to), rlpList(rlpString(make([]byte, 32))))))...),
+		},
+		{
+			name:  "Success_DynamicFee_MultipleChunks",
+			rawTx: append([]byte{0x02}, rlpList(rlpUint(10), rlpUint(3), rlpUint(1_000_000_000), rlpUint(30_000_000_000), rlpUint(50000), rlpString(to), rlpUint(0), rlpString(longData), rlpList())...),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sig, err := app.SignTransaction(context.Background(), testBIP32Path, test.rawTx)
+			assert.NoError(t, err)
+
+			parity := uint64(sig.V) - test.vOffset
+			assert.LessOrEqual(t, parity, uint64(1))
+			assert.Equal(t, address, recoverAddress(t, schema.Keccak256(test.rawTx), sig, byte(parity)))
+		})
+	}
+}
+
+func TestEthereumApp_SignTypedTransaction(t *testing.T) {
+	app, address := newTestEthereumApp(t)
+	to := schema.Address(mustDecodeHex("d8da6bf26964af9d7eed9e03e53415d37aa96045"))
+
+	tests := []struct {
+		name    string
+		tx      schema.Transaction
+		vOffset uint64
+		err     error
+	}{
+		{
+			name:    "Success_Legacy_ContractCreation",
+			tx:      &schema.LegacyTx{Nonce: 1, GasPrice: uint256.NewInt(1_000_000_000), Gas: 100000, Data: []byte{0x60, 0x00}, ChainID: 1},
+			vOffset: 1*2 + 35,
+		},
+		{
+			name: "Success_DynamicFee",
+			tx: &schema.DynamicFeeTx{
+				ChainID: 10, Nonce: 3, GasTipCap: uint256.NewInt(1_000_000_000), GasFeeCap: uint256.NewInt(30_000_000_000), Gas: 50000, To: &to, Value: uint256.NewInt(1),
+				AccessList: schema.AccessList{{Address: to, StorageKeys: [][32]byte{{0x01}}}},
+			},
+		},
+		{
+			name: "Error_UnsupportedType",
+			tx:   &schema.SetCodeTx{ChainID: 1, To: to},
+			err:  errors.New("unsupported transaction type: 0x4"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sig, err := app.SignTypedTransaction(context.Background(), testBIP32Path, test.tx)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			rawTx, err := test.tx.MarshalUnsigned()
+			assert.NoError(t, err)
+			parity := uint64(sig.V) - test.vOffset
+			assert.LessOrEqual(t, parity, uint64(1))
+			assert.Equal(t, address, recoverAddress(t, schema.Keccak256(rawTx), sig, byte(parity)))
+		})
+	}
+}
+
+func TestAssembleSignedTx_Emulator(t *testing.T) {
+	app, _ := newTestEthereumApp(t)
+	to := schema.Address(mustDecodeHex("d8da6bf26964af9d7eed9e03e53415d37aa96045"))
+	dynamicFee, err := (&schema.DynamicFeeTx{ChainID: 10, Nonce: 3, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: &to}).MarshalUnsigned()
+	assert.NoError(t, err)
+
+	for _, rawTx := range [][]byte{legacyTx(nil), legacyTx(nil, 0x01_0000_0089), dynamicFee} {
+		sig, err := app.SignTransaction(context.Background(), testBIP32Path, rawTx)
+		assert.NoError(t, err)
+
+		signedTx, err := schema.AssembleSignedTx(rawTx, sig)
+
+		assert.NoError(t, err)
+		assert.Equal(t, [32]byte(schema.Keccak256(signedTx.Raw)), signedTx.Hash)
+	}
+}
+
+func TestEthereumApp_SignPersonalMessage(t *testing.T) {
+	app, address := newTestEthereumApp(t)
+
+	tests := []struct {
+		name    string
+		message []byte
+	}{
+		{
+			name:    "Success_ShortMessage",
+			message: []byte("Hello, Ledger!"),
+		},
+		{
+			name:    "Success_MultipleChunks",
+			message: bytes.Repeat([]byte("0123456789"), 60),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sig, err := app.SignPersonalMessage(context.Background(), testBIP32Path, test.message)
+			assert.NoError(t, err)
+			assert.Contains(t, []schema.SignatureV{27, 28}, sig.V)
+
+			hash := schema.Keccak256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(test.message))), test.message)
+			assert.Equal(t, address, recoverAddress(t, hash, sig, byte(sig.V-27)))
+		})
+	}
+}
+
+// Example of EIP-712 specification, with its domain separator and message hash
+func mailMessage() eip712.Message {
+	cow := mustDecodeHex("CD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	bob := mustDecodeHex("bBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	person := func(name string, wallet []byte) eip712.StructItem {
+		return eip712.StructItem{
+			TypeName: "Person",
+			Members: []eip712.StructItemMember{
+				{Name: "name", Item: eip712.AtomicItem{Item: eip712.StringData(name)}},
+				{Name: "wallet", Item: eip712.AtomicItem{Item: eip712.AddressData(wallet)}},
+			},
+		}
+	}
+	domain := eip712.Domain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainID:           uint256.NewInt(1),
+		VerifyingContract: schema.Address(mustDecodeHex("CcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")),
+	}
+
+	return eip712.Message{
+		Types: eip712.TypeStructs{
+			domain.TypeStruct(),
+			{
+				Name: "Person",
+				Members: []eip712.FieldDefinition{
+					{TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_STRING}, KeyName: "name"},
+					{TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_ADDRESS}, KeyName: "wallet"},
+				},
+			},
+			{
+				Name: "Mail",
+				Members: []eip712.FieldDefinition{
+					{TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_CUSTOM}, CustomTypeName: "Person", KeyName: "from"},
+					{TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_CUSTOM}, CustomTypeName: "Person", KeyName: "to"},
+					{TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_STRING}, KeyName: "contents"},
+				},
+			},
+		},
+		Domain: domain,
+		Primary: eip712.StructItem{
+			TypeName: "Mail",
+			Members: []eip712.StructItemMember{
+				{Name: "from", Item: person("Cow", cow)},
+				{Name: "to", Item: person("Bob", bob)},
+				{Name: "contents", Item: eip712.AtomicItem{Item: eip712.StringData("Hello, Bob!")}},
+			},
+		},
+	}
+}
+
+// Message with arrays, i.e. Group(string name,address[] members,uint8[2][] scores)
+func groupMessage() (eip712.Message, []byte, []byte) {
+	verifyingContract := mustDecodeHex("1111111111111111111111111111111111111111")
+	members := [][]byte{
+		mustDecodeHex("2222222222222222222222222222222222222222"),
+		mustDecodeHex("3333333333333333333333333333333333333333"),
+	}
+	domain := eip712.Domain{
+		Name:              "Groups",
+		ChainID:           uint256.NewInt(10),
+		VerifyingContract: schema.Address(verifyingContract),
+	}
+	pad := func(b []byte) []byte {
+		return append(make([]byte, 32-len(b)), b...)
+	}
+
+	domainHash := schema.Keccak256(
+		schema.Keccak256([]byte("EIP712Domain(string name,uint256 chainId,address verifyingContract)")),
+		schema.Keccak256([]byte("Groups")),
+		pad([]byte{10}),
+		pad(verifyingContract),
+	)
+	messageHash := schema.Keccak256(
+		schema.Keccak256([]byte("Group(string name,address[] members,uint8[2][] scores)")),
+		schema.Keccak256([]byte("Admins")),
+		schema.Keccak256(pad(members[0]), pad(members[1])),
+		schema.Keccak256(schema.Keccak256(pad([]byte{1}), pad([]byte{2})), schema.Keccak256(pad([]byte{3}), pad([]byte{4}))),
+	)
+
+	number := func(n uint64) eip712.Item {
+		return eip712.AtomicItem{Item: eip712.NumberData{Num: uint256.NewInt(n), NumBits: 8}}
+	}
+	message := eip712.Message{
+		Types: eip712.TypeStructs{
+			domain.TypeStruct(),
+			{
+				Name: "Group",
+				Members: []eip712.FieldDefinition{
+					{TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_STRING}, KeyName: "name"},
+					{
+						TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_ADDRESS, IsArray: true},
+						ArrayLevels:     []eip712.FieldArrayLevel{{Type: eip712.STRUCT_DEF_ARRAY_TYPE_DYNAMIC}},
+						KeyName:         "members",
+					},
+					{
+						TypeDescription: eip712.FieldTypeDescription{Type: eip712.FIELD_TYPE_DESC_TYPE_UINT, IsArray: true, IsSizeSpecified: true},
+						TypeSize:        1,
+						ArrayLevels: []eip712.FieldArrayLevel{
+							{Type: eip712.STRUCT_DEF_ARRAY_TYPE_FIXED, FixedArraySize: 2},
+							{Type: eip712.STRUCT_DEF_ARRAY_TYPE_DYNAMIC},
+						},
+						KeyName: "scores",
+					},
+				},
+			},
+		},
+		Domain: domain,
+		Primary: eip712.StructItem{
+			TypeName: "Group",
+			Members: []eip712.StructItemMember{
+				{Name: "name", Item: eip712.AtomicItem{Item: eip712.StringData("Admins")}},
+				{Name: "members", Item: eip712.ArrayItem{
+					eip712.AtomicItem{Item: eip712.AddressData(members[0])},
+					eip712.AtomicItem{Item: eip712.AddressData(members[1])},
+				}},
+				{Name: "scores", Item: eip712.ArrayItem{
+					eip712.ArrayItem{number(1), number(2)},
+					eip712.ArrayItem{number(3), number(4)},
+				}},
+			},
+		},
+	}
+
+	return message, domainHash, messageHash
+}
+
+func TestEthereumApp_SignEIP712Message(t *testing.T) {
+	app, address := newTestEthereumApp(t)
+	groupMsg, groupDomainHash, groupMessageHash := groupMessage()
+
+	tests := []struct {
+		name        string
+		message     eip712.Message
+		domainHash  []byte
+		messageHash []byte
+	}{
+		{
+			name:        "Success_NestedStruct",
+			message:     mailMessage(),
+			domainHash:  mustDecodeHex("f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"),
+			messageHash: mustDecodeHex("c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"),
+		},
+		{
+			name:        "Success_Arrays",
+			message:     groupMsg,
+			domainHash:  groupDomainHash,
+			messageHash: groupMessageHash,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hash := schema.Keccak256([]byte{0x19, 0x01}, test.domainHash, test.messageHash)
+			domainHash, messageHash, err := test.message.Hash()
+			assert.NoError(t, err)
+			assert.Equal(t, test.domainHash, domainHash[:])
+			assert.Equal(t, test.messageHash, messageHash[:])
+
+			sig, err := app.SignEIP712Message(context.Background(), testBIP32Path, test.message)
+			assert.NoError(t, err)
+			assert.Contains(t, []schema.SignatureV{27, 28}, sig.V)
+			assert.Equal(t, address, recoverAddress(t, hash, sig, byte(sig.V-27)))
+
+			hashedSig, err := app.SignEIP712MessageHash(context.Background(), testBIP32Path, test.domainHash, test.messageHash)
+			assert.NoError(t, err)
+			assert.Equal(t, sig, hashedSig)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntchjb/ledger-go/eth/policy"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+)
+
+// Check every operation against the policy before any ADPU is sent to device.
+// Rejected operations return error wrapping `policy.ErrPolicyViolation`.
+func WithPolicy(p policy.Policy) EthereumAppOption {
+	return func(e *ethereumAppImpl) {
+		e.policy = p
+	}
+}
+
+// EthereumApp that checks requests against a policy
+type policyEthereumApp struct {
+	app    EthereumApp
+	policy policy.Policy
+}
+
+func (p *policyEthereumApp) check(ctx context.Context, req policy.Request) error {
+	if err := p.policy.Check(ctx, req); err != nil {
+		return fmt.Errorf("request is rejected by policy: %w", err)
+	}
+
+	return nil
+}
+
+func (p *policyEthereumApp) GetConfiguration(ctx context.Context) (schema.GetConfigurationResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_GET_CONFIGURATION}); err != nil {
+		return schema.GetConfigurationResponse{}, err
+	}
+
+	return p.app.GetConfiguration(ctx)
+}
+
+func (p *policyEthereumApp) GetAddress(ctx context.Context, bip32Path string, needHWConfirm bool, chaincode bool, chainID uint64) (schema.GetAddressResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_GET_ADDRESS, BIP32Path: bip32Path}); err != nil {
+		return schema.GetAddressResponse{}, err
+	}
+
+	return p.app.GetAddress(ctx, bip32Path, needHWConfirm, chaincode, chainID)
+}
+
+func (p *policyEthereumApp) SignTransaction(ctx context.Context, bip32Path string, rawTx []byte) (schema.SignDataResponse, error) {
+	txInfo, err := schema.DecodeTxInfo(rawTx)
+	if err != nil {
+		return schema.SignDataResponse{}, fmt.Errorf("unable to decode raw tx info: %w", err)
+	}
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, BIP32Path: bip32Path, Tx: &txInfo}); err != nil {
+		return schema.SignDataResponse{}, err
+	}
+
+	return p.app.SignTransaction(ctx, bip32Path, rawTx)
+}
+
+func (p *policyEthereumApp) SignTypedTransaction(ctx context.Context, bip32Path string, tx schema.Transaction) (schema.SignDataResponse, error) {
+	rawTx, err := tx.MarshalUnsigned()
+	if err != nil {
+		return schema.SignDataResponse{}, fmt.Errorf("unable to encode typed tx: %w", err)
+	}
+
+	return p.SignTransaction(ctx, bip32Path, rawTx)
+}
+
+func (p *policyEthereumApp) SignPersonalMessage(ctx context.Context, bip32Path string, message []byte) (schema.SignDataResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_SIGN_PERSONAL_MESSAGE, BIP32Path: bip32Path, Message: message}); err != nil {
+		return schema.SignDataResponse{}, err
+	}
+
+	return p.app.SignPersonalMessage(ctx, bip32Path, message)
+}
+
+func (p *policyEthereumApp) SignEIP712Message(ctx context.Context, bip32Path string, message eip712.Message) (schema.SignDataResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_SIGN_EIP712_MESSAGE, BIP32Path: bip32Path, EIP712: &message}); err != nil {
+		return schema.SignDataResponse{}, err
+	}
+
+	return p.app.SignEIP712Message(ctx, bip32Path, message)
+}
+
+func (p *policyEthereumApp) EIP712SendStructDefinition(ctx context.Context, component eip712.Component, value []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_EIP712_SEND_STRUCT_DEFINITION}); err != nil {
+		return err
+	}
+
+	return p.app.EIP712SendStructDefinition(ctx, component, value)
+}
+
+func (p *policyEthereumApp) EIP712SendStructData(ctx context.Context, component eip712.Component, value []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_EIP712_SEND_STRUCT_DATA}); err != nil {
+		return err
+	}
+
+	return p.app.EIP712SendStructData(ctx, component, value)
+}
+
+func (p *policyEthereumApp) EIP712SendClearSigningData(ctx context.Context, action eip712.Action, value []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_EIP712_SEND_CLEAR_SIGNING}); err != nil {
+		return err
+	}
+
+	return p.app.EIP712SendClearSigningData(ctx, action, value)
+}
+
+func (p *policyEthereumApp) SignEIP712MessageHash(ctx context.Context, bip32Path string, domainSeparatorHash []byte, messageHash []byte) (schema.SignDataResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_SIGN_EIP712_HASH, BIP32Path: bip32Path}); err != nil {
+		return schema.SignDataResponse{}, err
+	}
+
+	return p.app.SignEIP712MessageHash(ctx, bip32Path, domainSeparatorHash, messageHash)
+}
+
+func (p *policyEthereumApp) ETH2GetPublicKey(ctx context.Context, bip32Path string, needHWConfirm bool) (schema.ETH2PublicKey, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_ETH2_GET_PUBLIC_KEY, BIP32Path: bip32Path}); err != nil {
+		return schema.ETH2PublicKey{}, err
+	}
+
+	return p.app.ETH2GetPublicKey(ctx, bip32Path, needHWConfirm)
+}
+
+func (p *policyEthereumApp) ETH2SetWithdrawalIndex(ctx context.Context, index uint32) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_ETH2_SET_WITHDRAWAL_INDEX}); err != nil {
+		return err
+	}
+
+	return p.app.ETH2SetWithdrawalIndex(ctx, index)
+}
+
+func (p *policyEthereumApp) GetPrivacyPublicKey(ctx context.Context, bip32Path string, needHWConfirm bool) (schema.GetPrivacyPublicKeyResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_GET_PRIVACY_PUBLIC_KEY, BIP32Path: bip32Path}); err != nil {
+		return schema.GetPrivacyPublicKeyResponse{}, err
+	}
+
+	return p.app.GetPrivacyPublicKey(ctx, bip32Path, needHWConfirm)
+}
+
+func (p *policyEthereumApp) GetPrivacySharedSecret(ctx context.Context, bip32Path string, remotePublicKey []byte, needHWConfirm bool) (schema.GetPrivacySharedSecretResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_GET_PRIVACY_SHARED_SECRET, BIP32Path: bip32Path}); err != nil {
+		return schema.GetPrivacySharedSecretResponse{}, err
+	}
+
+	return p.app.GetPrivacySharedSecret(ctx, bip32Path, remotePublicKey, needHWConfirm)
+}
+
+func (p *policyEthereumApp) GetChallenge(ctx context.Context) (schema.Challenge, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_GET_CHALLENGE}); err != nil {
+		return schema.Challenge{}, err
+	}
+
+	return p.app.GetChallenge(ctx)
+}
+
+func (p *policyEthereumApp) ProvideDomainNameInformation(ctx context.Context, info []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_PROVIDE_DOMAIN_NAME}); err != nil {
+		return err
+	}
+
+	return p.app.ProvideDomainNameInformation(ctx, info)
+}
+
+func (p *policyEthereumApp) ProvideNFTInformation(ctx context.Context, info []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_PROVIDE_NFT_INFO}); err != nil {
+		return err
+	}
+
+	return p.app.ProvideNFTInformation(ctx, info)
+}
+
+func (p *policyEthereumApp) ProvideERC20Information(ctx context.Context, info []byte) (schema.ProvideERC20InfoResponse, error) {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_PROVIDE_ERC20_INFO}); err != nil {
+		return 0, err
+	}
+
+	return p.app.ProvideERC20Information(ctx, info)
+}
+
+func (p *policyEthereumApp) SetPlugin(ctx context.Context, info []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_SET_PLUGIN}); err != nil {
+		return err
+	}
+
+	return p.app.SetPlugin(ctx, info)
+}
+
+func (p *policyEthereumApp) SetExternalPlugin(ctx context.Context, payload []byte, signature []byte) error {
+	if err := p.check(ctx, policy.Request{Operation: policy.OPERATION_SET_EXTERNAL_PLUGIN}); err != nil {
+		return err
+	}
+
+	return p.app.SetExternalPlugin(ctx, payload, signature)
+}
@@ -0,0 +1,108 @@
+// Package policy decides whether requests of Ethereum app are allowed to reach device.
+//
+// Rule set is read by `ReadRulePolicy` in JSON format only. YAML is not supported by this package,
+// but `Rules` carries YAML tags, so rule set decoded by a YAML library can be passed to `NewRulePolicy`.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+)
+
+// Operation of Ethereum app, which is a method of `eth.EthereumApp`
+type Operation string
+
+const (
+	OPERATION_GET_CONFIGURATION             Operation = "GET_CONFIGURATION"
+	OPERATION_GET_ADDRESS                   Operation = "GET_ADDRESS"
+	OPERATION_SIGN_TRANSACTION              Operation = "SIGN_TRANSACTION"
+	OPERATION_SIGN_PERSONAL_MESSAGE         Operation = "SIGN_PERSONAL_MESSAGE"
+	OPERATION_SIGN_EIP712_MESSAGE           Operation = "SIGN_EIP712_MESSAGE"
+	OPERATION_SIGN_EIP712_HASH              Operation = "SIGN_EIP712_HASH"
+	OPERATION_EIP712_SEND_STRUCT_DEFINITION Operation = "EIP712_SEND_STRUCT_DEFINITION"
+	OPERATION_EIP712_SEND_STRUCT_DATA       Operation = "EIP712_SEND_STRUCT_DATA"
+	OPERATION_EIP712_SEND_CLEAR_SIGNING     Operation = "EIP712_SEND_CLEAR_SIGNING"
+	OPERATION_ETH2_GET_PUBLIC_KEY           Operation = "ETH2_GET_PUBLIC_KEY"
+	OPERATION_ETH2_SET_WITHDRAWAL_INDEX     Operation = "ETH2_SET_WITHDRAWAL_INDEX"
+	OPERATION_GET_PRIVACY_PUBLIC_KEY        Operation = "GET_PRIVACY_PUBLIC_KEY"
+	OPERATION_GET_PRIVACY_SHARED_SECRET     Operation = "GET_PRIVACY_SHARED_SECRET"
+	OPERATION_GET_CHALLENGE                 Operation = "GET_CHALLENGE"
+	OPERATION_PROVIDE_DOMAIN_NAME           Operation = "PROVIDE_DOMAIN_NAME"
+	OPERATION_PROVIDE_NFT_INFO              Operation = "PROVIDE_NFT_INFO"
+	OPERATION_PROVIDE_ERC20_INFO            Operation = "PROVIDE_ERC20_INFO"
+	OPERATION_SET_PLUGIN                    Operation = "SET_PLUGIN"
+	OPERATION_SET_EXTERNAL_PLUGIN           Operation = "SET_EXTERNAL_PLUGIN"
+)
+
+var (
+	ErrPolicyViolation = errors.New("request violates policy")
+	ErrInvalidRule     = errors.New("invalid policy rule")
+
+	operations = map[Operation]bool{
+		OPERATION_GET_CONFIGURATION:             true,
+		OPERATION_GET_ADDRESS:                   true,
+		OPERATION_SIGN_TRANSACTION:              true,
+		OPERATION_SIGN_PERSONAL_MESSAGE:         true,
+		OPERATION_SIGN_EIP712_MESSAGE:           true,
+		OPERATION_SIGN_EIP712_HASH:              true,
+		OPERATION_EIP712_SEND_STRUCT_DEFINITION: true,
+		OPERATION_EIP712_SEND_STRUCT_DATA:       true,
+		OPERATION_EIP712_SEND_CLEAR_SIGNING:     true,
+		OPERATION_ETH2_GET_PUBLIC_KEY:           true,
+		OPERATION_ETH2_SET_WITHDRAWAL_INDEX:     true,
+		OPERATION_GET_PRIVACY_PUBLIC_KEY:        true,
+		OPERATION_GET_PRIVACY_SHARED_SECRET:     true,
+		OPERATION_GET_CHALLENGE:                 true,
+		OPERATION_PROVIDE_DOMAIN_NAME:           true,
+		OPERATION_PROVIDE_NFT_INFO:              true,
+		OPERATION_PROVIDE_ERC20_INFO:            true,
+		OPERATION_SET_PLUGIN:                    true,
+		OPERATION_SET_EXTERNAL_PLUGIN:           true,
+	}
+)
+
+// Request of an operation, decoded before any ADPU of the operation is sent to device
+type Request struct {
+	Operation Operation
+	// BIP32 path of the key used by the operation, if any
+	BIP32Path string
+	// Decoded transaction, for SIGN_TRANSACTION
+	Tx *schema.TxInfo
+	// Message, for SIGN_PERSONAL_MESSAGE
+	Message []byte
+	// Typed data, for SIGN_EIP712_MESSAGE
+	EIP712 *eip712.Message
+}
+
+func (r Request) String() string {
+	desc := []string{string(r.Operation)}
+	if r.BIP32Path != "" {
+		desc = append(desc, "path "+r.BIP32Path)
+	}
+	if r.Tx != nil {
+		to, chainID := "to "+r.Tx.To.String(), fmt.Sprintf("chain ID %d", r.Tx.ChainID)
+		if r.Tx.IsCreate {
+			to = "contract creation"
+		}
+		if !r.Tx.HasChainID {
+			chainID = "no chain ID"
+		}
+		desc = append(desc, to+", "+chainID)
+	}
+	if r.EIP712 != nil {
+		desc = append(desc, fmt.Sprintf("domain %q, verifying contract %s, primary type %s", r.EIP712.Domain.Name, r.EIP712.Domain.VerifyingContract.String(), r.EIP712.Primary.TypeName))
+	}
+
+	return strings.Join(desc, ", ")
+}
+
+// Policy decides whether a request is allowed to reach device
+type Policy interface {
+	// Return error wrapping `ErrPolicyViolation` if the request is not allowed
+	Check(ctx context.Context, req Request) error
+}
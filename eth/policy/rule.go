@@ -0,0 +1,246 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ntchjb/ledger-go/eth/schema"
+)
+
+// Rule allows requests of an operation that match every condition of the rule.
+// Condition with empty value matches any request.
+type Rule struct {
+	Operation Operation `json:"operation" yaml:"operation"`
+	// Allowed BIP32 paths i.e. "m'/44'/60'/0'/0/0"
+	BIP32Paths []string `json:"bip32Paths,omitempty" yaml:"bip32Paths,omitempty"`
+	// Allowed chain IDs of transaction, or EIP712 domain.
+	// Pre-EIP-155 transaction, or EIP712 domain without chain ID, never matches.
+	ChainIDs []uint64 `json:"chainIDs,omitempty" yaml:"chainIDs,omitempty"`
+	// Allowed `to` addresses of transaction, in hex. Contract creation never matches,
+	// even if zero address is allowed.
+	To []string `json:"to,omitempty" yaml:"to,omitempty"`
+	// Allow contract creation transaction, which is denied otherwise
+	AllowCreate bool `json:"allowCreate,omitempty" yaml:"allowCreate,omitempty"`
+	// Allowed 4-byte method selectors of transaction calldata, in hex.
+	// Use "0x" to allow transaction without calldata
+	Selectors []string `json:"selectors,omitempty" yaml:"selectors,omitempty"`
+	// Regular expression that the whole personal message must match
+	MessagePattern string `json:"messagePattern,omitempty" yaml:"messagePattern,omitempty"`
+	// Allowed names of EIP712 domain
+	DomainNames []string `json:"domainNames,omitempty" yaml:"domainNames,omitempty"`
+	// Allowed verifying contracts of EIP712 domain, in hex
+	VerifyingContracts []string `json:"verifyingContracts,omitempty" yaml:"verifyingContracts,omitempty"`
+	// Allowed primary types of EIP712 message
+	PrimaryTypes []string `json:"primaryTypes,omitempty" yaml:"primaryTypes,omitempty"`
+}
+
+// Rule set, in JSON format i.e.
+//
+//	{"rules": [{"operation": "SIGN_TRANSACTION", "chainIDs": [1], "to": ["0x..."]}]}
+type Rules struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+type compiledRule struct {
+	Rule
+	bip32Paths         [][]uint32
+	to                 []schema.Address
+	selectors          [][]byte
+	messagePattern     *regexp.Regexp
+	verifyingContracts []schema.Address
+}
+
+type rulePolicy struct {
+	rules []compiledRule
+}
+
+// Create a policy that denies every request unless a rule allows it
+func NewRulePolicy(rules Rules) (Policy, error) {
+	p := &rulePolicy{}
+	for i, rule := range rules.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		p.rules = append(p.rules, compiled)
+	}
+
+	return p, nil
+}
+
+// Read rule set in JSON format and create a policy from it. Unknown fields are rejected,
+// so that a misspelled condition does not silently allow more requests.
+// Only JSON is supported; rule set in other formats can be decoded into `Rules` and passed to `NewRulePolicy`.
+func ReadRulePolicy(r io.Reader) (Policy, error) {
+	var rules Rules
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("unable to decode rules: %w: %w", ErrInvalidRule, err)
+	}
+
+	return NewRulePolicy(rules)
+}
+
+func parseAddresses(field string, values []string) ([]schema.Address, error) {
+	var addresses []schema.Address
+	for _, value := range values {
+		b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil || len(b) != schema.ADDRESS_LENGTH {
+			return nil, fmt.Errorf("%s: invalid address %q: %w", field, value, ErrInvalidRule)
+		}
+		addresses = append(addresses, schema.Address(b))
+	}
+
+	return addresses, nil
+}
+
+func compileRule(rule Rule) (compiledRule, error) {
+	compiled := compiledRule{Rule: rule}
+	if !operations[rule.Operation] {
+		return compiled, fmt.Errorf("unknown operation %q: %w", rule.Operation, ErrInvalidRule)
+	}
+
+	// Conditions must be applicable to the operation, otherwise they would be ignored
+	isTx := rule.Operation == OPERATION_SIGN_TRANSACTION
+	isEIP712 := rule.Operation == OPERATION_SIGN_EIP712_MESSAGE
+	for field, applicable := range map[string]bool{
+		"chainIDs":           len(rule.ChainIDs) == 0 || isTx || isEIP712,
+		"to":                 len(rule.To) == 0 || isTx,
+		"allowCreate":        !rule.AllowCreate || isTx,
+		"selectors":          len(rule.Selectors) == 0 || isTx,
+		"messagePattern":     rule.MessagePattern == "" || rule.Operation == OPERATION_SIGN_PERSONAL_MESSAGE,
+		"domainNames":        len(rule.DomainNames) == 0 || isEIP712,
+		"verifyingContracts": len(rule.VerifyingContracts) == 0 || isEIP712,
+		"primaryTypes":       len(rule.PrimaryTypes) == 0 || isEIP712,
+	} {
+		if !applicable {
+			return compiled, fmt.Errorf("%s is not applicable to %s: %w", field, rule.Operation, ErrInvalidRule)
+		}
+	}
+
+	for _, path := range rule.BIP32Paths {
+		paths, err := schema.SplitBIP32Paths(path)
+		if err != nil {
+			return compiled, fmt.Errorf("bip32Paths: invalid path %q: %w: %w", path, ErrInvalidRule, err)
+		}
+		compiled.bip32Paths = append(compiled.bip32Paths, paths)
+	}
+	var err error
+	if compiled.to, err = parseAddresses("to", rule.To); err != nil {
+		return compiled, err
+	}
+	if compiled.verifyingContracts, err = parseAddresses("verifyingContracts", rule.VerifyingContracts); err != nil {
+		return compiled, err
+	}
+	for _, selector := range rule.Selectors {
+		b, err := hex.DecodeString(strings.TrimPrefix(selector, "0x"))
+		if err != nil || (len(b) != 0 && len(b) != 4) {
+			return compiled, fmt.Errorf("selectors: invalid selector %q: %w", selector, ErrInvalidRule)
+		}
+		compiled.selectors = append(compiled.selectors, b)
+	}
+	if rule.MessagePattern != "" {
+		// Anchor the pattern, so it must match the whole message
+		if compiled.messagePattern, err = regexp.Compile("^(?:" + rule.MessagePattern + ")$"); err != nil {
+			return compiled, fmt.Errorf("messagePattern: %w: %w", ErrInvalidRule, err)
+		}
+	}
+
+	return compiled, nil
+}
+
+func (r *compiledRule) matchBIP32Path(bip32Path string) bool {
+	if len(r.bip32Paths) == 0 {
+		return true
+	}
+	paths, err := schema.SplitBIP32Paths(bip32Path)
+	if err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(r.bip32Paths, func(allowed []uint32) bool {
+		return slices.Equal(allowed, paths)
+	})
+}
+
+func (r *compiledRule) matchChainID(chainID uint64, exists bool) bool {
+	return len(r.ChainIDs) == 0 || (exists && slices.Contains(r.ChainIDs, chainID))
+}
+
+func matchAddress(allowed []schema.Address, address schema.Address) bool {
+	return len(allowed) == 0 || slices.Contains(allowed, address)
+}
+
+// Contract creation has zero address as `to`, so it is matched by `AllowCreate` only
+func (r *compiledRule) matchTo(tx *schema.TxInfo) bool {
+	if tx.IsCreate {
+		return r.AllowCreate
+	}
+
+	return matchAddress(r.to, tx.To)
+}
+
+func (r *compiledRule) matchSelector(data []byte) bool {
+	if len(r.selectors) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(r.selectors, func(selector []byte) bool {
+		if len(selector) == 0 {
+			return len(data) == 0
+		}
+		return len(data) >= 4 && bytes.Equal(data[:4], selector)
+	})
+}
+
+func (r *compiledRule) match(req Request) bool {
+	if r.Operation != req.Operation || !r.matchBIP32Path(req.BIP32Path) {
+		return false
+	}
+
+	switch req.Operation {
+	case OPERATION_SIGN_TRANSACTION:
+		if req.Tx == nil {
+			return false
+		}
+		return r.matchChainID(uint64(req.Tx.ChainID), req.Tx.HasChainID) &&
+			r.matchTo(req.Tx) &&
+			r.matchSelector(req.Tx.Data)
+	case OPERATION_SIGN_PERSONAL_MESSAGE:
+		return r.messagePattern == nil || r.messagePattern.Match(req.Message)
+	case OPERATION_SIGN_EIP712_MESSAGE:
+		if req.EIP712 == nil {
+			return false
+		}
+		domain := req.EIP712.Domain
+		var chainID uint64
+		hasChainID := domain.ChainID != nil && domain.ChainID.IsUint64()
+		if hasChainID {
+			chainID = domain.ChainID.Uint64()
+		}
+		return r.matchChainID(chainID, hasChainID) &&
+			(len(r.DomainNames) == 0 || slices.Contains(r.DomainNames, domain.Name)) &&
+			matchAddress(r.verifyingContracts, domain.VerifyingContract) &&
+			(len(r.PrimaryTypes) == 0 || slices.Contains(r.PrimaryTypes, req.EIP712.Primary.TypeName))
+	}
+
+	return true
+}
+
+func (p *rulePolicy) Check(ctx context.Context, req Request) error {
+	for _, rule := range p.rules {
+		if rule.match(req) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no rule allows %s", ErrPolicyViolation, req)
+}
@@ -0,0 +1,172 @@
+package policy_test
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/policy"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPath     = "m'/44'/60'/0'/0/0"
+	testTo       = "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"
+	testContract = "0xcccccccccccccccccccccccccccccccccccccccc"
+)
+
+func mustAddress(t *testing.T, s string) schema.Address {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	assert.NoError(t, err)
+	return schema.Address(b)
+}
+
+func TestReadRulePolicy_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "UnknownField", rules: `{"rules": [{"operation": "GET_ADDRESS", "bip32Path": ["m'/44'/60'/0'/0/0"]}]}`},
+		{name: "UnknownOperation", rules: `{"rules": [{"operation": "SIGN_EVERYTHING"}]}`},
+		{name: "NotApplicable", rules: `{"rules": [{"operation": "GET_ADDRESS", "chainIDs": [1]}]}`},
+		{name: "InvalidPath", rules: `{"rules": [{"operation": "GET_ADDRESS", "bip32Paths": ["m'/44'/x"]}]}`},
+		{name: "InvalidAddress", rules: `{"rules": [{"operation": "SIGN_TRANSACTION", "to": ["0x1234"]}]}`},
+		{name: "InvalidSelector", rules: `{"rules": [{"operation": "SIGN_TRANSACTION", "selectors": ["0xa9059c"]}]}`},
+		{name: "InvalidPattern", rules: `{"rules": [{"operation": "SIGN_PERSONAL_MESSAGE", "messagePattern": "("}]}`},
+		{name: "AllowCreateNotApplicable", rules: `{"rules": [{"operation": "SIGN_PERSONAL_MESSAGE", "allowCreate": true}]}`},
+		{name: "InvalidJSON", rules: `{"rules": [`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := policy.ReadRulePolicy(strings.NewReader(test.rules))
+
+			assert.ErrorIs(t, err, policy.ErrInvalidRule)
+		})
+	}
+}
+
+func TestRulePolicy_Check(t *testing.T) {
+	p, err := policy.ReadRulePolicy(strings.NewReader(`{"rules": [
+		{"operation": "GET_ADDRESS", "bip32Paths": ["m'/44'/60'/0'/0/0"]},
+		{"operation": "SIGN_TRANSACTION", "chainIDs": [1], "to": ["` + testTo + `"], "selectors": ["0x", "0xa9059cbb"]},
+		{"operation": "SIGN_TRANSACTION", "chainIDs": [1], "to": ["0x0000000000000000000000000000000000000000"]},
+		{"operation": "SIGN_TRANSACTION", "chainIDs": [10], "to": ["0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"], "allowCreate": true},
+		{"operation": "SIGN_PERSONAL_MESSAGE", "messagePattern": "Login nonce: [0-9]+"},
+		{"operation": "SIGN_EIP712_MESSAGE", "chainIDs": [1], "domainNames": ["Permit2"], "verifyingContracts": ["` + testContract + `"], "primaryTypes": ["PermitSingle"]}
+	]}`))
+	assert.NoError(t, err)
+
+	typedData := func(chainID *uint256.Int, name string, primaryType string) *eip712.Message {
+		return &eip712.Message{
+			Domain: eip712.Domain{
+				Name:              name,
+				ChainID:           chainID,
+				VerifyingContract: mustAddress(t, testContract),
+			},
+			Primary: eip712.StructItem{TypeName: primaryType},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     policy.Request
+		allowed bool
+	}{
+		{
+			name:    "GetAddress",
+			req:     policy.Request{Operation: policy.OPERATION_GET_ADDRESS, BIP32Path: testPath},
+			allowed: true,
+		},
+		{
+			name: "GetAddress_OtherPath",
+			req:  policy.Request{Operation: policy.OPERATION_GET_ADDRESS, BIP32Path: "m'/44'/60'/0'/0/1"},
+		},
+		{
+			name: "NoRule",
+			req:  policy.Request{Operation: policy.OPERATION_GET_CONFIGURATION},
+		},
+		{
+			name:    "SignTransaction_Transfer",
+			req:     policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{To: mustAddress(t, testTo), ChainID: 1, HasChainID: true}},
+			allowed: true,
+		},
+		{
+			name:    "SignTransaction_AllowedSelector",
+			req:     policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{To: mustAddress(t, testTo), ChainID: 1, HasChainID: true, Data: []byte{0xa9, 0x05, 0x9c, 0xbb, 0x00}}},
+			allowed: true,
+		},
+		{
+			name: "SignTransaction_OtherSelector",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{To: mustAddress(t, testTo), ChainID: 1, HasChainID: true, Data: []byte{0x09, 0x5e, 0xa7, 0xb3}}},
+		},
+		{
+			name: "SignTransaction_OtherChain",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{To: mustAddress(t, testTo), ChainID: 5, HasChainID: true}},
+		},
+		{
+			name: "SignTransaction_PreEIP155",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{To: mustAddress(t, testTo), ChainID: 1, HasChainID: false}},
+		},
+		{
+			name:    "SignTransaction_ZeroAddress",
+			req:     policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{ChainID: 1, HasChainID: true}},
+			allowed: true,
+		},
+		{
+			name: "SignTransaction_CreateNotAllowed",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{IsCreate: true, ChainID: 1, HasChainID: true}},
+		},
+		{
+			name:    "SignTransaction_CreateAllowed",
+			req:     policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{IsCreate: true, ChainID: 10, HasChainID: true, Data: []byte{0x60, 0x80}}},
+			allowed: true,
+		},
+		{
+			name: "SignTransaction_OtherTo",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_TRANSACTION, Tx: &schema.TxInfo{To: mustAddress(t, testContract), ChainID: 1, HasChainID: true}},
+		},
+		{
+			name:    "SignPersonalMessage",
+			req:     policy.Request{Operation: policy.OPERATION_SIGN_PERSONAL_MESSAGE, Message: []byte("Login nonce: 1234")},
+			allowed: true,
+		},
+		{
+			name: "SignPersonalMessage_PartialMatch",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_PERSONAL_MESSAGE, Message: []byte("Login nonce: 1234 and transfer everything")},
+		},
+		{
+			name:    "SignEIP712Message",
+			req:     policy.Request{Operation: policy.OPERATION_SIGN_EIP712_MESSAGE, EIP712: typedData(uint256.NewInt(1), "Permit2", "PermitSingle")},
+			allowed: true,
+		},
+		{
+			name: "SignEIP712Message_NoChainID",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_EIP712_MESSAGE, EIP712: typedData(nil, "Permit2", "PermitSingle")},
+		},
+		{
+			name: "SignEIP712Message_OtherDomain",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_EIP712_MESSAGE, EIP712: typedData(uint256.NewInt(1), "Seaport", "PermitSingle")},
+		},
+		{
+			name: "SignEIP712Message_OtherPrimaryType",
+			req:  policy.Request{Operation: policy.OPERATION_SIGN_EIP712_MESSAGE, EIP712: typedData(uint256.NewInt(1), "Permit2", "PermitBatch")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := p.Check(context.Background(), test.req)
+
+			if test.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, policy.ErrPolicyViolation)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package eth_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/policy"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEthereumApp_WithPolicy(t *testing.T) {
+	p, err := policy.ReadRulePolicy(strings.NewReader(`{"rules": [
+		{"operation": "SIGN_TRANSACTION", "chainIDs": [1], "selectors": ["0x"]}
+	]}`))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		rawTx   []byte
+		allowed bool
+	}{
+		{
+			name:    "Allowed",
+			rawTx:   legacyTx(nil, 1),
+			allowed: true,
+		},
+		{
+			name:  "OtherChain",
+			rawTx: legacyTx(nil, 5),
+		},
+		{
+			name:  "Calldata",
+			rawTx: legacyTx([]byte{0xAA, 0xBB, 0xCC, 0xDD}, 1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			proto := adpu.NewMockProtocol(ctrl)
+			if test.allowed {
+				proto.EXPECT().Send(gomock.Any(), eth.ADPU_CLA, eth.ADPU_INS_SIGN_TRANSACTION, eth.P1_FIRST_CHUNK, uint8(0x00), gomock.Any()).Return(nil, adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, nil)
+			}
+			app := eth.NewEthereumApp(proto, slog.Default(), eth.WithPolicy(p))
+
+			_, err := app.SignTransaction(context.Background(), testBIP32Path, test.rawTx)
+
+			if test.allowed {
+				assert.ErrorIs(t, err, adpu.ErrUserRefused)
+			} else {
+				// Rejected requests never reach device
+				assert.ErrorIs(t, err, policy.ErrPolicyViolation)
+			}
+		})
+	}
+}
+
+func TestEthereumApp_WithPolicy_DenyByDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	p, err := policy.NewRulePolicy(policy.Rules{})
+	assert.NoError(t, err)
+	app := eth.NewEthereumApp(adpu.NewMockProtocol(ctrl), slog.Default(), eth.WithPolicy(p))
+
+	_, err = app.GetConfiguration(context.Background())
+
+	assert.ErrorIs(t, err, policy.ErrPolicyViolation)
+}
+
+func TestEthereumApp_WithPolicy_SignTypedTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	p, err := policy.ReadRulePolicy(strings.NewReader(`{"rules": [{"operation": "SIGN_TRANSACTION", "chainIDs": [1]}]}`))
+	assert.NoError(t, err)
+	app := eth.NewEthereumApp(adpu.NewMockProtocol(ctrl), slog.Default(), eth.WithPolicy(p))
+
+	_, err = app.SignTypedTransaction(context.Background(), testBIP32Path, &schema.DynamicFeeTx{ChainID: 5})
+
+	assert.ErrorIs(t, err, policy.ErrPolicyViolation)
+}
@@ -0,0 +1,39 @@
+package eth
+
+import (
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/log"
+)
+
+const (
+	// Maximum number of bytes of ADPU payload written to debug logs by `DefaultRedactionPolicy`
+	DEFAULT_REDACTION_MAX_PAYLOAD_LENGTH = 64
+)
+
+// Redaction policy of protocol debug logs for Ethereum app, to be used with `adpu.WithRedaction`.
+// It masks responses of privacy operations i.e. shared secrets, and truncates long payloads i.e. transactions.
+func DefaultRedactionPolicy() adpu.RedactionPolicy {
+	return adpu.RedactionPolicy{
+		Rules: map[uint8]adpu.RedactionRule{
+			ADPU_INS_PRIVACY_OPERATION: {MaskResponse: true},
+		},
+		MaxPayloadLength: DEFAULT_REDACTION_MAX_PAYLOAD_LENGTH,
+	}
+}
+
+// Redact payloads in debug logs of Ethereum app i.e. raw transactions and messages, by rules of their INS.
+// It is usually the same policy as given to `adpu.WithRedaction`. By default, payloads are logged in hex.
+func WithRedaction(policy adpu.RedactionPolicy) EthereumAppOption {
+	return func(e *ethereumAppImpl) {
+		e.redaction = &policy
+	}
+}
+
+// Payload of a command of the INS, as logged
+func (e *ethereumAppImpl) payloadValue(ins uint8, data []byte) any {
+	if e.redaction == nil {
+		return log.HexDisplay(data)
+	}
+
+	return e.redaction.CommandData(ins, data)
+}
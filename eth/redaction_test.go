@@ -0,0 +1,77 @@
+package eth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEthereumApp_WithRedaction(t *testing.T) {
+	txData := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 32)
+	message := []byte("Hello, Ledger! This is a secret message")
+	mailContents := hex.EncodeToString([]byte("Hello, Bob!"))
+	masked := adpu.RedactionPolicy{
+		Rules: map[uint8]adpu.RedactionRule{
+			eth.ADPU_INS_SIGN_TRANSACTION:        {MaskCommand: true},
+			eth.ADPU_INS_SIGN_PERSONAL_MESSAGE:   {MaskCommand: true},
+			eth.ADPU_INS_SIGN_EIP712:             {MaskCommand: true},
+			eth.ADPU_INS_EIP712_SEND_STRUCT_DATA: {MaskCommand: true},
+			eth.ADPU_INS_EIP712_SEND_STRUCT_DEF:  {MaskCommand: true},
+			eth.ADPU_INS_EIP712_CLEAR_SIGNING:    {MaskCommand: true},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		opts        []eth.EthereumAppOption
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "NoRedaction",
+			contains: []string{strings.Repeat("deadbeef", 32), hex.EncodeToString(message), mailContents},
+		},
+		{
+			name:        "Masked",
+			opts:        []eth.EthereumAppOption{eth.WithRedaction(masked)},
+			contains:    []string{"<redacted 39 bytes>"},
+			notContains: []string{"deadbeef", hex.EncodeToString(message[:8]), mailContents},
+		},
+		{
+			name:        "Truncated",
+			opts:        []eth.EthereumAppOption{eth.WithRedaction(eth.DefaultRedactionPolicy())},
+			contains:    []string{strings.Repeat("deadbeef", 16) + "<truncated 64 bytes>"},
+			notContains: []string{strings.Repeat("deadbeef", 17)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			app := eth.NewEthereumApp(newTestProtocol(t), logger, test.opts...)
+			ctx := context.Background()
+
+			_, err := app.SignTransaction(ctx, testBIP32Path, legacyTx(txData, 1))
+			assert.NoError(t, err)
+			_, err = app.SignPersonalMessage(ctx, testBIP32Path, message)
+			assert.NoError(t, err)
+			_, err = app.SignEIP712Message(ctx, testBIP32Path, mailMessage())
+			assert.NoError(t, err)
+
+			for _, s := range test.contains {
+				assert.Contains(t, logs.String(), s)
+			}
+			for _, s := range test.notContains {
+				assert.NotContains(t, logs.String(), s)
+			}
+		})
+	}
+}
@@ -62,7 +62,7 @@ func decodeItem(encoded []byte) (ItemInfo, error) {
 		res.Offset = 1
 		res.Length = int(prefix - 0x80)
 		res.Type = ITEM_TYPE_STRING
-	} else if prefix <= 0xBF && len(encoded) > int(prefix-0xB7) && uint64(len(encoded)) > uint64(prefix-0xB7)+getDynamicDataLength(encoded[1:prefix-0xB7]) {
+	} else if prefix <= 0xBF && len(encoded) > int(prefix-0xB7) && uint64(len(encoded)) > uint64(prefix-0xB7)+getDynamicDataLength(encoded[1:1+prefix-0xB7]) {
 		// A long data,
 		// [(length of data length number, 1 byte), (data length, 0-8 bytes), (data)...]
 		// first byte tell number of bytes to store string length number,
@@ -78,7 +78,7 @@ func decodeItem(encoded []byte) (ItemInfo, error) {
 		res.Offset = 1
 		res.Length = int(prefix - 0xC0)
 		res.Type = ITEM_TYPE_LIST
-	} else if len(encoded) > int(prefix-0xF7) && uint64(len(encoded)) > uint64(prefix-0xF7)+getDynamicDataLength(encoded[1:prefix-0xF7]) {
+	} else if len(encoded) > int(prefix-0xF7) && uint64(len(encoded)) > uint64(prefix-0xF7)+getDynamicDataLength(encoded[1:1+prefix-0xF7]) {
 		// A long list,
 		// [(length of data length number, 1 byte), (data length, 0-8 bytes), (data)...]
 		// the first byte indicates number of bytes to store list data length number
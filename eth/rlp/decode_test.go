@@ -317,6 +317,24 @@ func TestDecode(t *testing.T) {
 			n:   0,
 			err: rlp.ErrInvalidRLPFormat,
 		},
+		{
+			name: "Error_LongStringTruncated",
+			encoded: []byte{
+				0xb8, 0x38, 'd', 'o', 'g',
+			},
+			res: rlp.Item{},
+			n:   0,
+			err: rlp.ErrInvalidRLPFormat,
+		},
+		{
+			name: "Error_LongListTruncated",
+			encoded: []byte{
+				0xf8, 0x38, 0x83, 'd', 'o', 'g',
+			},
+			res: rlp.Item{},
+			n:   0,
+			err: rlp.ErrInvalidRLPFormat,
+		},
 		{
 			name: "Error_InvalidFormatNested",
 
@@ -0,0 +1,107 @@
+package rlp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+// Encode item to RLP format. Item that has neither data nor list is encoded as an empty string.
+func Encode(item Item) ([]byte, error) {
+	return appendItem(nil, item)
+}
+
+func appendItem(buf []byte, item Item) ([]byte, error) {
+	if item.Data != nil && item.List != nil {
+		return nil, fmt.Errorf("item has both data and list: %w", ErrInvalidItem)
+	}
+
+	if item.List != nil {
+		var content []byte
+		for i, child := range item.List {
+			var err error
+			if content, err = appendItem(content, child); err != nil {
+				return nil, fmt.Errorf("unable to encode list item %d: %w", i, err)
+			}
+		}
+		buf = appendHeader(buf, 0xC0, len(content))
+
+		return append(buf, content...), nil
+	}
+
+	// A single byte in [0x00, 0x7F] is its own encoding
+	if len(item.Data) == 1 && item.Data[0] <= 0x7F {
+		return append(buf, item.Data[0]), nil
+	}
+	buf = appendHeader(buf, 0x80, len(item.Data))
+
+	return append(buf, item.Data...), nil
+}
+
+// Append header of string (offset 0x80) or list (offset 0xC0) with given content length
+func appendHeader(buf []byte, offset byte, length int) []byte {
+	if length <= 55 {
+		return append(buf, offset+byte(length))
+	}
+	lengthBytes := uintBytes(uint64(length))
+	buf = append(buf, offset+55+byte(len(lengthBytes)))
+
+	return append(buf, lengthBytes...)
+}
+
+// Big-endian bytes of number without leading zeros, where zero is an empty byte array
+func uintBytes(num uint64) []byte {
+	b := binary.BigEndian.AppendUint64(nil, num)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+
+	return b
+}
+
+// Create string item
+func NewString(data []byte) Item {
+	if data == nil {
+		data = []byte{}
+	}
+
+	return Item{Data: data}
+}
+
+// Create list item
+func NewList(items ...Item) Item {
+	if items == nil {
+		items = []Item{}
+	}
+
+	return Item{List: items}
+}
+
+// Create item of unsigned integer in canonical form, big-endian without leading zeros
+func NewUint(num uint64) Item {
+	return Item{Data: uintBytes(num)}
+}
+
+// Create item of 256-bit unsigned integer in canonical form. Nil is encoded as zero.
+func NewUint256(num *uint256.Int) Item {
+	if num == nil || num.IsZero() {
+		return Item{Data: []byte{}}
+	}
+
+	return Item{Data: num.Bytes()}
+}
+
+// Create item of big integer in canonical form. Nil is encoded as zero.
+// RLP has no representation of negative integers.
+func NewBigInt(num *big.Int) (Item, error) {
+	if num == nil {
+		return Item{Data: []byte{}}, nil
+	}
+	if num.Sign() < 0 {
+		return Item{}, fmt.Errorf("negative integer %s: %w", num, ErrInvalidItem)
+	}
+
+	return Item{Data: num.Bytes()}, nil
+}
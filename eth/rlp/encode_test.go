@@ -0,0 +1,151 @@
+package rlp_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	lorem := []byte("Lorem ipsum dolor sit amet, consectetur adipisicing elit")
+
+	tests := []struct {
+		name    string
+		item    rlp.Item
+		encoded []byte
+		err     error
+	}{
+		{
+			name:    "Success_ShortString",
+			item:    rlp.NewString([]byte("dog")),
+			encoded: []byte{0x83, 'd', 'o', 'g'},
+		},
+		{
+			name:    "Success_ShortList",
+			item:    rlp.NewList(rlp.NewString([]byte("cat")), rlp.NewString([]byte("dog"))),
+			encoded: []byte{0xc8, 0x83, 'c', 'a', 't', 0x83, 'd', 'o', 'g'},
+		},
+		{
+			name:    "Success_EmptyString",
+			item:    rlp.NewString(nil),
+			encoded: []byte{0x80},
+		},
+		{
+			name:    "Success_ZeroItem",
+			item:    rlp.Item{},
+			encoded: []byte{0x80},
+		},
+		{
+			name:    "Success_EmptyList",
+			item:    rlp.NewList(),
+			encoded: []byte{0xc0},
+		},
+		{
+			name:    "Success_Byte0",
+			item:    rlp.NewString([]byte{0x00}),
+			encoded: []byte{0x00},
+		},
+		{
+			name:    "Success_Byte80",
+			item:    rlp.NewString([]byte{0x80}),
+			encoded: []byte{0x81, 0x80},
+		},
+		{
+			name:    "Success_Uint0",
+			item:    rlp.NewUint(0),
+			encoded: []byte{0x80},
+		},
+		{
+			name:    "Success_Uint15",
+			item:    rlp.NewUint(15),
+			encoded: []byte{0x0f},
+		},
+		{
+			name:    "Success_Uint1024",
+			item:    rlp.NewUint(1024),
+			encoded: []byte{0x82, 0x04, 0x00},
+		},
+		{
+			name:    "Success_Uint256",
+			item:    rlp.NewUint256(uint256.NewInt(0x0400)),
+			encoded: []byte{0x82, 0x04, 0x00},
+		},
+		{
+			name:    "Success_Uint256Nil",
+			item:    rlp.NewUint256(nil),
+			encoded: []byte{0x80},
+		},
+		{
+			name: "Success_SetTheoreticalRepresentation",
+			item: rlp.NewList(
+				rlp.NewList(),
+				rlp.NewList(rlp.NewList()),
+				rlp.NewList(rlp.NewList(), rlp.NewList(rlp.NewList())),
+			),
+			encoded: []byte{0xc7, 0xc0, 0xc1, 0xc0, 0xc3, 0xc0, 0xc1, 0xc0},
+		},
+		{
+			name:    "Success_LongString",
+			item:    rlp.NewString(lorem),
+			encoded: append([]byte{0xb8, 0x38}, lorem...),
+		},
+		{
+			name:    "Success_LongList",
+			item:    rlp.NewList(rlp.NewString(lorem)),
+			encoded: append([]byte{0xf8, 0x3a, 0xb8, 0x38}, lorem...),
+		},
+		{
+			name:    "Success_VeryLongString",
+			item:    rlp.NewString(bytes.Repeat([]byte{0xaa}, 1024)),
+			encoded: append([]byte{0xb9, 0x04, 0x00}, bytes.Repeat([]byte{0xaa}, 1024)...),
+		},
+		{
+			name: "Error_DataAndList",
+			item: rlp.Item{Data: []byte{0x01}, List: []rlp.Item{}},
+			err:  rlp.ErrInvalidItem,
+		},
+		{
+			name: "Error_NestedDataAndList",
+			item: rlp.NewList(rlp.Item{Data: []byte{0x01}, List: []rlp.Item{}}),
+			err:  rlp.ErrInvalidItem,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := rlp.Encode(test.item)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.encoded, encoded)
+			if test.err == nil {
+				// Length of zero item is unknown, as it has neither data nor list
+				if test.item.Data != nil || test.item.List != nil {
+					assert.Equal(t, len(encoded), test.item.Len())
+				}
+				decoded, n, err := rlp.Decode(encoded)
+				assert.NoError(t, err)
+				assert.Equal(t, len(encoded), n)
+				reencoded, err := rlp.Encode(decoded)
+				assert.NoError(t, err)
+				assert.Equal(t, encoded, reencoded)
+			}
+		})
+	}
+}
+
+func TestNewBigInt(t *testing.T) {
+	item, err := rlp.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 64))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0, 0, 0, 0, 0, 0, 0, 0}, item.Data)
+
+	item, err = rlp.NewBigInt(big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, item.Data)
+
+	_, err = rlp.NewBigInt(big.NewInt(-1))
+	assert.ErrorIs(t, err, rlp.ErrInvalidItem)
+}
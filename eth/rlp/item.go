@@ -17,6 +17,10 @@ const (
 var (
 	ErrEmptyInput       = errors.New("empty input")
 	ErrInvalidRLPFormat = errors.New("invalid RLP format")
+	ErrInvalidItem      = errors.New("invalid RLP item")
+	ErrUnsupportedType  = errors.New("unsupported type")
+	ErrTypeMismatch     = errors.New("RLP item does not match type")
+	ErrNonCanonical     = errors.New("non-canonical RLP value")
 )
 
 type ItemInfo struct {
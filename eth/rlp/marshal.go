@@ -0,0 +1,358 @@
+package rlp
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/holiman/uint256"
+)
+
+var (
+	itemType    = reflect.TypeOf(Item{})
+	bigIntType  = reflect.TypeOf(big.Int{})
+	uint256Type = reflect.TypeOf(uint256.Int{})
+)
+
+// Encode value to RLP format. Supported types are
+//   - `Item`, as is
+//   - []byte, [N]byte, and string, as string
+//   - bool, as integer 0 or 1
+//   - unsigned integers, `*big.Int`, and `*uint256.Int`, as integer in canonical form
+//   - slices and arrays of other types, as list
+//   - structs, as list of exported fields in declaration order
+//
+// Nil pointers are encoded as an empty list if their element type is encoded as list, otherwise as an empty string.
+// Struct fields can be tagged by `rlp:"-"` to be skipped, or by `rlp:"optional"` to be omitted
+// when the field and all following fields are zero. Fields following an optional field must be optional.
+// Pointer fields can be tagged by `rlp:"nil"` to be decoded as nil from an empty string or an empty list.
+func Marshal(v any) ([]byte, error) {
+	item, err := ToItem(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(item)
+}
+
+// Decode RLP data to value pointed by `v`, following the same rules as `Marshal`.
+// Data is decoded by `DecodeStrict`, integers must be in canonical form, and the whole data must be consumed.
+func Unmarshal(encoded []byte, v any) error {
+	item, n, err := DecodeStrict(encoded)
+	if err != nil {
+		return fmt.Errorf("unable to decode RLP data: %w", err)
+	}
+	if n != len(encoded) {
+		return fmt.Errorf("unexpected trailing data, %d bytes: %w", len(encoded)-n, ErrInvalidRLPFormat)
+	}
+
+	return FromItem(item, v)
+}
+
+// Convert value to item, following the rules of `Marshal`
+func ToItem(v any) (Item, error) {
+	return toItem(reflect.ValueOf(v))
+}
+
+// Set value pointed by `v` from item, following the rules of `Unmarshal`
+func FromItem(item Item, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("expected non-nil pointer, got %T: %w", v, ErrUnsupportedType)
+	}
+
+	return fromItem(item, rv.Elem())
+}
+
+type structField struct {
+	index    int
+	name     string
+	optional bool
+	nilable  bool
+}
+
+func structFields(t reflect.Type) ([]structField, error) {
+	var fields []structField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		switch tag := f.Tag.Get("rlp"); tag {
+		case "-":
+		case "optional":
+			fields = append(fields, structField{index: i, name: f.Name, optional: true})
+		case "", "nil":
+			if len(fields) > 0 && fields[len(fields)-1].optional {
+				return nil, fmt.Errorf("field %s.%s follows an optional field, but is not optional: %w", t.Name(), f.Name, ErrUnsupportedType)
+			}
+			if tag == "nil" && f.Type.Kind() != reflect.Pointer {
+				return nil, fmt.Errorf("field %s.%s is tagged nil, but is not a pointer: %w", t.Name(), f.Name, ErrUnsupportedType)
+			}
+			fields = append(fields, structField{index: i, name: f.Name, nilable: tag == "nil"})
+		default:
+			return nil, fmt.Errorf("unknown tag %q of field %s.%s: %w", tag, t.Name(), f.Name, ErrUnsupportedType)
+		}
+	}
+
+	return fields, nil
+}
+
+func isByteType(t reflect.Type) bool {
+	return t.Kind() == reflect.Uint8
+}
+
+// Whether value of the type is encoded as list
+func isListType(t reflect.Type) bool {
+	switch t {
+	case itemType, bigIntType, uint256Type:
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return !isByteType(t.Elem())
+	case reflect.Struct:
+		return true
+	}
+
+	return false
+}
+
+// Whether item is an empty string or an empty list
+func isEmptyItem(item Item) bool {
+	return len(item.Data) == 0 && len(item.List) == 0
+}
+
+func toItem(v reflect.Value) (Item, error) {
+	if !v.IsValid() {
+		return Item{}, fmt.Errorf("nil value: %w", ErrUnsupportedType)
+	}
+	switch v.Type() {
+	case itemType:
+		return v.Interface().(Item), nil
+	case bigIntType, uint256Type:
+		// Encode by pointer, as methods of big integers have pointer receivers
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return toItem(p)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		switch num := v.Interface().(type) {
+		case *big.Int:
+			return NewBigInt(num)
+		case *uint256.Int:
+			return NewUint256(num), nil
+		}
+		if v.IsNil() {
+			if isListType(v.Type().Elem()) {
+				return NewList(), nil
+			}
+			return NewString(nil), nil
+		}
+		return toItem(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return Item{}, fmt.Errorf("nil interface: %w", ErrUnsupportedType)
+		}
+		return toItem(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return NewUint(1), nil
+		}
+		return NewUint(0), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return NewUint(v.Uint()), nil
+	case reflect.String:
+		return NewString([]byte(v.String())), nil
+	case reflect.Slice, reflect.Array:
+		if isByteType(v.Type().Elem()) {
+			data := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(data), v)
+			return NewString(data), nil
+		}
+		items := make([]Item, v.Len())
+		for i := range items {
+			item, err := toItem(v.Index(i))
+			if err != nil {
+				return Item{}, fmt.Errorf("item %d: %w", i, err)
+			}
+			items[i] = item
+		}
+		return NewList(items...), nil
+	case reflect.Struct:
+		fields, err := structFields(v.Type())
+		if err != nil {
+			return Item{}, err
+		}
+		// Omit trailing optional fields with zero value
+		for len(fields) > 0 && fields[len(fields)-1].optional && v.Field(fields[len(fields)-1].index).IsZero() {
+			fields = fields[:len(fields)-1]
+		}
+		items := make([]Item, len(fields))
+		for i, field := range fields {
+			item, err := toItem(v.Field(field.index))
+			if err != nil {
+				return Item{}, fmt.Errorf("field %s: %w", field.name, err)
+			}
+			items[i] = item
+		}
+		return NewList(items...), nil
+	}
+
+	return Item{}, fmt.Errorf("type %s: %w", v.Type(), ErrUnsupportedType)
+}
+
+func stringData(item Item) ([]byte, error) {
+	if item.List != nil {
+		return nil, fmt.Errorf("expected string, got list: %w", ErrTypeMismatch)
+	}
+
+	return item.Data, nil
+}
+
+func listItems(item Item) ([]Item, error) {
+	if item.List == nil {
+		return nil, fmt.Errorf("expected list, got string: %w", ErrTypeMismatch)
+	}
+
+	return item.List, nil
+}
+
+// Data of integer item, which must not have leading zeros, and must fit in `maxSize` bytes if it's positive
+func uintData(item Item, maxSize int) ([]byte, error) {
+	data, err := stringData(item)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[0] == 0 {
+		return nil, fmt.Errorf("integer has leading zero: %w", ErrNonCanonical)
+	}
+	if maxSize > 0 && len(data) > maxSize {
+		return nil, fmt.Errorf("integer of %d bytes overflows %d bytes: %w", len(data), maxSize, ErrTypeMismatch)
+	}
+
+	return data, nil
+}
+
+func fromItem(item Item, v reflect.Value) error {
+	switch v.Type() {
+	case itemType:
+		v.Set(reflect.ValueOf(item))
+		return nil
+	case bigIntType:
+		data, err := uintData(item, 0)
+		if err != nil {
+			return err
+		}
+		v.Addr().Interface().(*big.Int).SetBytes(data)
+		return nil
+	case uint256Type:
+		data, err := uintData(item, 32)
+		if err != nil {
+			return err
+		}
+		v.Addr().Interface().(*uint256.Int).SetBytes(data)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return fromItem(item, v.Elem())
+	case reflect.Bool:
+		data, err := uintData(item, 1)
+		if err != nil {
+			return err
+		}
+		if len(data) == 1 && data[0] != 1 {
+			return fmt.Errorf("invalid boolean 0x%x: %w", data, ErrTypeMismatch)
+		}
+		v.SetBool(len(data) == 1)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		data, err := uintData(item, int(v.Type().Size()))
+		if err != nil {
+			return err
+		}
+		var num uint64
+		for _, b := range data {
+			num = num<<8 | uint64(b)
+		}
+		v.SetUint(num)
+		return nil
+	case reflect.String:
+		data, err := stringData(item)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(data))
+		return nil
+	case reflect.Slice, reflect.Array:
+		if isByteType(v.Type().Elem()) {
+			data, err := stringData(item)
+			if err != nil {
+				return err
+			}
+			if v.Kind() == reflect.Slice {
+				v.Set(reflect.MakeSlice(v.Type(), len(data), len(data)))
+			} else if len(data) != v.Len() {
+				return fmt.Errorf("expected %d bytes, got %d: %w", v.Len(), len(data), ErrTypeMismatch)
+			}
+			reflect.Copy(v, reflect.ValueOf(data))
+			return nil
+		}
+		items, err := listItems(item)
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		} else if len(items) != v.Len() {
+			return fmt.Errorf("expected %d items, got %d: %w", v.Len(), len(items), ErrTypeMismatch)
+		}
+		for i, child := range items {
+			if err := fromItem(child, v.Index(i)); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Struct:
+		fields, err := structFields(v.Type())
+		if err != nil {
+			return err
+		}
+		items, err := listItems(item)
+		if err != nil {
+			return err
+		}
+		required := 0
+		for _, field := range fields {
+			if !field.optional {
+				required++
+			}
+		}
+		if len(items) < required || len(items) > len(fields) {
+			return fmt.Errorf("expected %d-%d items for %s, got %d: %w", required, len(fields), v.Type(), len(items), ErrTypeMismatch)
+		}
+		for i, field := range fields {
+			if i >= len(items) {
+				v.Field(field.index).SetZero()
+				continue
+			}
+			if field.nilable && isEmptyItem(items[i]) {
+				v.Field(field.index).SetZero()
+				continue
+			}
+			if err := fromItem(items[i], v.Field(field.index)); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("type %s: %w", v.Type(), ErrUnsupportedType)
+}
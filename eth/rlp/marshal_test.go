@@ -0,0 +1,228 @@
+package rlp_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAccessTuple struct {
+	Address     [20]byte
+	StorageKeys [][32]byte
+}
+
+type testTx struct {
+	ChainID    *uint256.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	To         []byte
+	Data       []byte
+	AccessList []testAccessTuple
+	Note       string `rlp:"-"`
+	Enabled    bool   `rlp:"optional"`
+	Extra      uint16 `rlp:"optional"`
+	unexported int
+}
+
+type testInvalidOptional struct {
+	A uint64 `rlp:"optional"`
+	B uint64
+}
+
+type testUnknownTag struct {
+	A uint64 `rlp:"tail"`
+}
+
+type testNilable struct {
+	To    *[20]byte        `rlp:"nil"`
+	Tuple *testAccessTuple `rlp:"nil"`
+}
+
+type testInvalidNil struct {
+	A uint64 `rlp:"nil"`
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		encoded []byte
+		err     error
+	}{
+		{
+			name:    "Success_String",
+			value:   "dog",
+			encoded: []byte{0x83, 'd', 'o', 'g'},
+		},
+		{
+			name:    "Success_StringList",
+			value:   []string{"cat", "dog"},
+			encoded: []byte{0xc8, 0x83, 'c', 'a', 't', 0x83, 'd', 'o', 'g'},
+		},
+		{
+			name:    "Success_Uint",
+			value:   uint32(1024),
+			encoded: []byte{0x82, 0x04, 0x00},
+		},
+		{
+			name:    "Success_BigInt",
+			value:   big.NewInt(1024),
+			encoded: []byte{0x82, 0x04, 0x00},
+		},
+		{
+			name:    "Success_NilBigInt",
+			value:   (*big.Int)(nil),
+			encoded: []byte{0x80},
+		},
+		{
+			name:    "Success_Bool",
+			value:   []bool{true, false},
+			encoded: []byte{0xc2, 0x01, 0x80},
+		},
+		{
+			name:    "Success_ByteArray",
+			value:   [2]byte{0x04, 0x00},
+			encoded: []byte{0x82, 0x04, 0x00},
+		},
+		{
+			name:    "Success_Item",
+			value:   rlp.NewList(rlp.NewUint(1)),
+			encoded: []byte{0xc1, 0x01},
+		},
+		{
+			name: "Success_StructOptionalOmitted",
+			value: testTx{
+				ChainID:  uint256.NewInt(1),
+				Nonce:    9,
+				GasPrice: big.NewInt(0x1234),
+				To:       []byte{0xaa},
+				Note:     "ignored",
+			},
+			encoded: []byte{0xc9, 0x01, 0x09, 0x82, 0x12, 0x34, 0x81, 0xaa, 0x80, 0xc0},
+		},
+		{
+			name: "Success_StructOptionalKept",
+			value: &testTx{
+				ChainID: uint256.NewInt(1),
+				Extra:   2,
+				AccessList: []testAccessTuple{
+					{Address: [20]byte{0x01}},
+				},
+			},
+			encoded: append(append([]byte{0xdf, 0x01, 0x80, 0x80, 0x80, 0x80, 0xd7, 0xd6, 0x94, 0x01}, make([]byte, 19)...), 0xc0, 0x80, 0x02),
+		},
+		{
+			name:    "Success_NilPointers",
+			value:   testNilable{},
+			encoded: []byte{0xc2, 0x80, 0xc0},
+		},
+		{
+			name:  "Error_NegativeBigInt",
+			value: []*big.Int{big.NewInt(-1)},
+			err:   rlp.ErrInvalidItem,
+		},
+		{
+			name:  "Error_UnsupportedType",
+			value: []int{1},
+			err:   rlp.ErrUnsupportedType,
+		},
+		{
+			name:  "Error_InvalidOptional",
+			value: testInvalidOptional{},
+			err:   rlp.ErrUnsupportedType,
+		},
+		{
+			name:  "Error_UnknownTag",
+			value: testUnknownTag{},
+			err:   rlp.ErrUnsupportedType,
+		},
+		{
+			name:  "Error_NilTagNotPointer",
+			value: testInvalidNil{},
+			err:   rlp.ErrUnsupportedType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := rlp.Marshal(test.value)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.encoded, encoded)
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("Success_Struct", func(t *testing.T) {
+		expected := testTx{
+			ChainID:  uint256.NewInt(1),
+			Nonce:    9,
+			GasPrice: big.NewInt(0x1234),
+			To:       []byte{0xaa},
+			Data:     []byte{},
+			AccessList: []testAccessTuple{
+				{Address: [20]byte{0x01}, StorageKeys: [][32]byte{{0x02}}},
+			},
+			Enabled: true,
+		}
+		encoded, err := rlp.Marshal(expected)
+		assert.NoError(t, err)
+
+		var actual testTx
+		err = rlp.Unmarshal(encoded, &actual)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Success_OptionalOmitted", func(t *testing.T) {
+		actual := testTx{Enabled: true, Extra: 3}
+		err := rlp.Unmarshal([]byte{0xc6, 0x01, 0x09, 0x80, 0x80, 0x80, 0xc0}, &actual)
+
+		assert.NoError(t, err)
+		assert.False(t, actual.Enabled)
+		assert.Zero(t, actual.Extra)
+		assert.Equal(t, uint64(9), actual.Nonce)
+	})
+
+	t.Run("Success_NilTag", func(t *testing.T) {
+		actual := testNilable{To: &[20]byte{0x01}, Tuple: &testAccessTuple{}}
+		err := rlp.Unmarshal([]byte{0xc2, 0x80, 0xc0}, &actual)
+
+		assert.NoError(t, err)
+		assert.Nil(t, actual.To)
+		assert.Nil(t, actual.Tuple)
+	})
+
+	tests := []struct {
+		name    string
+		encoded []byte
+		value   any
+		err     error
+	}{
+		{name: "Error_LeadingZero", encoded: []byte{0x82, 0x00, 0x01}, value: new(uint64), err: rlp.ErrNonCanonical},
+		{name: "Error_UintOverflow", encoded: []byte{0x82, 0x01, 0x00}, value: new(uint8), err: rlp.ErrTypeMismatch},
+		{name: "Error_Uint256Overflow", encoded: append([]byte{0xa1, 0x01}, make([]byte, 32)...), value: new(uint256.Int), err: rlp.ErrTypeMismatch},
+		{name: "Error_BigIntLeadingZero", encoded: []byte{0x82, 0x00, 0x01}, value: new(big.Int), err: rlp.ErrNonCanonical},
+		{name: "Error_InvalidBool", encoded: []byte{0x02}, value: new(bool), err: rlp.ErrTypeMismatch},
+		{name: "Error_ListAsString", encoded: []byte{0xc0}, value: new(string), err: rlp.ErrTypeMismatch},
+		{name: "Error_StringAsList", encoded: []byte{0x80}, value: new([]string), err: rlp.ErrTypeMismatch},
+		{name: "Error_ByteArrayLength", encoded: []byte{0x81, 0xaa}, value: new([2]byte), err: rlp.ErrTypeMismatch},
+		{name: "Error_TooFewFields", encoded: []byte{0xc1, 0x01}, value: new(testTx), err: rlp.ErrTypeMismatch},
+		{name: "Error_TrailingData", encoded: []byte{0x01, 0x02}, value: new(uint64), err: rlp.ErrInvalidRLPFormat},
+		{name: "Error_InvalidFormat", encoded: []byte{0x83, 'd', 'o'}, value: new(string), err: rlp.ErrInvalidRLPFormat},
+		{name: "Error_NotPointer", encoded: []byte{0x01}, value: uint64(0), err: rlp.ErrUnsupportedType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := rlp.Unmarshal(test.encoded, test.value)
+
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
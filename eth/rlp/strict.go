@@ -0,0 +1,123 @@
+package rlp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// Canonical form violations, rejected by strict decoding. They wrap `ErrNonCanonical`
+	ErrNonCanonicalSingleByte = fmt.Errorf("%w: single byte below 0x80 is not encoded as itself", ErrNonCanonical)
+	ErrNonCanonicalLongForm   = fmt.Errorf("%w: length below 56 is encoded in long form", ErrNonCanonical)
+	ErrNonCanonicalLength     = fmt.Errorf("%w: length has leading zero", ErrNonCanonical)
+
+	ErrUnexpectedEnd  = errors.New("unexpected end of input")
+	ErrListOverflow   = errors.New("item overflows its list")
+	ErrLengthOverflow = errors.New("length overflows")
+)
+
+// Error of strict decoding, at offset of the item that cannot be decoded.
+// It wraps `ErrInvalidRLPFormat`, and the cause i.e. `ErrNonCanonicalSingleByte`.
+type DecodeError struct {
+	// Offset of the item's first byte from the beginning of input
+	Offset int
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s at offset %d: %s", ErrInvalidRLPFormat, e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() []error {
+	return []error{ErrInvalidRLPFormat, e.Err}
+}
+
+// Decode RLP data like `Decode`, but reject any input that is not in canonical form, or not well-formed,
+// with `*DecodeError`. Every item in a list must be within the list.
+func DecodeStrict(encoded []byte) (Item, int, error) {
+	return decodeStrict(encoded, 0, false)
+}
+
+func decodeStrict(encoded []byte, offset int, inList bool) (Item, int, error) {
+	info, err := decodeItemStrict(encoded)
+	if err != nil {
+		if inList && errors.Is(err, ErrUnexpectedEnd) {
+			err = fmt.Errorf("%w: %w", ErrListOverflow, err)
+		}
+		return Item{}, 0, &DecodeError{Offset: offset, Err: err}
+	}
+	content := encoded[info.Offset : info.Offset+info.Length]
+	if info.Type == ITEM_TYPE_STRING {
+		return Item{Data: content}, info.Offset + info.Length, nil
+	}
+
+	res := Item{List: []Item{}}
+	for pos := 0; pos < len(content); {
+		item, n, err := decodeStrict(content[pos:], offset+info.Offset+pos, true)
+		if err != nil {
+			return Item{}, 0, err
+		}
+		res.List = append(res.List, item)
+		pos += n
+	}
+
+	return res, info.Offset + info.Length, nil
+}
+
+func decodeItemStrict(encoded []byte) (ItemInfo, error) {
+	var res ItemInfo
+	if len(encoded) == 0 {
+		return res, ErrEmptyInput
+	}
+	prefix := encoded[0]
+
+	var err error
+	switch {
+	case prefix <= 0x7F:
+		res = ItemInfo{Offset: 0, Length: 1, Type: ITEM_TYPE_STRING}
+	case prefix <= 0xB7:
+		res = ItemInfo{Offset: 1, Length: int(prefix - 0x80), Type: ITEM_TYPE_STRING}
+		if res.Length == 1 && len(encoded) > 1 && encoded[1] <= 0x7F {
+			return res, fmt.Errorf("got 0x%02x%02x: %w", prefix, encoded[1], ErrNonCanonicalSingleByte)
+		}
+	case prefix <= 0xBF:
+		res, err = decodeLongForm(encoded, int(prefix-0xB7), ITEM_TYPE_STRING)
+	case prefix <= 0xF7:
+		res = ItemInfo{Offset: 1, Length: int(prefix - 0xC0), Type: ITEM_TYPE_LIST}
+	default:
+		res, err = decodeLongForm(encoded, int(prefix-0xF7), ITEM_TYPE_LIST)
+	}
+	if err != nil {
+		return res, err
+	}
+
+	if len(encoded)-res.Offset < res.Length {
+		return res, fmt.Errorf("expected %d bytes of content, got %d: %w", res.Length, len(encoded)-res.Offset, ErrUnexpectedEnd)
+	}
+
+	return res, nil
+}
+
+// Decode header of long string or long list,
+// [(length of data length number, 1 byte), (data length, 1-8 bytes), (data)...]
+func decodeLongForm(encoded []byte, lengthOfLength int, itemType ItemType) (ItemInfo, error) {
+	res := ItemInfo{Offset: 1 + lengthOfLength, Type: itemType}
+	if len(encoded) < 1+lengthOfLength {
+		return res, fmt.Errorf("expected %d bytes of length, got %d: %w", lengthOfLength, len(encoded)-1, ErrUnexpectedEnd)
+	}
+	lengthBytes := encoded[1 : 1+lengthOfLength]
+	if lengthBytes[0] == 0 {
+		return res, ErrNonCanonicalLength
+	}
+	length := getDynamicDataLength(lengthBytes)
+	if length < 56 {
+		return res, fmt.Errorf("got length %d: %w", length, ErrNonCanonicalLongForm)
+	}
+	if length > uint64(math.MaxInt-res.Offset) {
+		return res, fmt.Errorf("got length %d: %w", length, ErrLengthOverflow)
+	}
+	res.Length = int(length)
+
+	return res, nil
+}
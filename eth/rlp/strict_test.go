@@ -0,0 +1,132 @@
+package rlp_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/eth/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeStrict(t *testing.T) {
+	longString := bytes.Repeat([]byte{'a'}, 56)
+
+	tests := []struct {
+		name    string
+		encoded []byte
+		res     rlp.Item
+		n       int
+		err     error
+		offset  int
+	}{
+		{
+			name:    "Success_ShortList",
+			encoded: []byte{0xc8, 0x83, 'c', 'a', 't', 0x83, 'd', 'o', 'g'},
+			res:     rlp.Item{List: []rlp.Item{{Data: []byte("cat")}, {Data: []byte("dog")}}},
+			n:       9,
+		},
+		{
+			name:    "Success_Byte80",
+			encoded: []byte{0x81, 0x80},
+			res:     rlp.Item{Data: []byte{0x80}},
+			n:       2,
+		},
+		{
+			name:    "Success_LongString",
+			encoded: append([]byte{0xb8, 0x38}, longString...),
+			res:     rlp.Item{Data: longString},
+			n:       58,
+		},
+		{
+			name:    "Success_TrailingData",
+			encoded: []byte{0x01, 0x02},
+			res:     rlp.Item{Data: []byte{0x01}},
+			n:       1,
+		},
+		{
+			name:    "Error_EmptyInput",
+			encoded: []byte{},
+			err:     rlp.ErrEmptyInput,
+		},
+		{
+			name:    "Error_SingleByteWrapped",
+			encoded: []byte{0x81, 0x05},
+			err:     rlp.ErrNonCanonicalSingleByte,
+		},
+		{
+			name:    "Error_SingleByteWrappedNested",
+			encoded: []byte{0xc4, 0xc3, 0xc2, 0x81, 0x01},
+			err:     rlp.ErrNonCanonicalSingleByte,
+			offset:  3,
+		},
+		{
+			name:    "Error_LongFormShortString",
+			encoded: []byte{0xb8, 0x03, 'd', 'o', 'g'},
+			err:     rlp.ErrNonCanonicalLongForm,
+		},
+		{
+			name:    "Error_LongFormShortList",
+			encoded: []byte{0xc4, 0x01, 0xf8, 0x01, 0x01},
+			err:     rlp.ErrNonCanonicalLongForm,
+			offset:  2,
+		},
+		{
+			name:    "Error_LengthLeadingZero",
+			encoded: append([]byte{0xb9, 0x00, 0x38}, longString...),
+			err:     rlp.ErrNonCanonicalLength,
+		},
+		{
+			name:    "Error_TruncatedString",
+			encoded: []byte{0x83, 'd', 'o'},
+			err:     rlp.ErrUnexpectedEnd,
+		},
+		{
+			name:    "Error_TruncatedLength",
+			encoded: []byte{0xb9, 0x01},
+			err:     rlp.ErrUnexpectedEnd,
+		},
+		{
+			name:    "Error_ItemOverflowsList",
+			encoded: []byte{0xc2, 0x83, 'd', 'o', 'g'},
+			err:     rlp.ErrListOverflow,
+			offset:  1,
+		},
+		{
+			name:    "Error_LengthOverflow",
+			encoded: []byte{0xbf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			err:     rlp.ErrLengthOverflow,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, n, err := rlp.DecodeStrict(test.encoded)
+
+			assert.Equal(t, test.res, actual)
+			assert.Equal(t, test.n, n)
+			assert.ErrorIs(t, err, test.err)
+			if test.err != nil {
+				assert.ErrorIs(t, err, rlp.ErrInvalidRLPFormat)
+				var decodeErr *rlp.DecodeError
+				assert.True(t, errors.As(err, &decodeErr))
+				assert.Equal(t, test.offset, decodeErr.Offset)
+			}
+		})
+	}
+}
+
+func TestDecodeStrict_NonCanonical(t *testing.T) {
+	for _, encoded := range [][]byte{
+		{0x81, 0x05},
+		{0xb8, 0x03, 'd', 'o', 'g'},
+		append([]byte{0xb9, 0x00, 0x38}, bytes.Repeat([]byte{'a'}, 56)...),
+	} {
+		// Accepted by lenient decoding, but rejected by strict decoding
+		_, _, err := rlp.Decode(encoded)
+		assert.NoError(t, err)
+
+		_, _, err = rlp.DecodeStrict(encoded)
+		assert.ErrorIs(t, err, rlp.ErrNonCanonical)
+	}
+}
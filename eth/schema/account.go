@@ -18,6 +18,15 @@ func (p *PublicKey) String() string {
 	return "0x" + hex.EncodeToString(p[:])
 }
 
+// Derive address from uncompressed public key, which is the last 20 bytes of keccak256(X || Y)
+func (p *PublicKey) Address() Address {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(p[1:])
+	hash := hasher.Sum(nil)
+
+	return Address(hash[len(hash)-ADDRESS_LENGTH:])
+}
+
 type Address [ADDRESS_LENGTH]byte
 
 func (a *Address) hexBytes() [ADDRESS_LENGTH*2 + 2]byte {
@@ -40,8 +40,40 @@ func SplitBIP32Paths(bip32Path string) ([]uint32, error) {
 	return res, nil
 }
 
+// Join path elements back to BIP-32 path string, which is the reverse of `SplitBIP32Paths`
+func JoinBIP32Paths(paths []uint32) string {
+	elements := []string{"m'"}
+	for _, num := range paths {
+		if num >= 0x8000_0000 {
+			elements = append(elements, strconv.FormatUint(uint64(num-0x8000_0000), 10)+"'")
+		} else {
+			elements = append(elements, strconv.FormatUint(uint64(num), 10))
+		}
+	}
+
+	return strings.Join(elements, "/")
+}
+
 type BIP32Path string
 
+// Decode BIP-32 path at the beginning of ADPU data, [length (1 byte), path elements (4 bytes each)...],
+// and return the remaining data
+func DecodeBIP32Path(data []byte) (BIP32Path, []byte, error) {
+	if len(data) == 0 {
+		return "", nil, fmt.Errorf("empty data, cannot get BIP-32 path length")
+	}
+	length := int(data[0])
+	if len(data) < 1+length*4 {
+		return "", nil, fmt.Errorf("BIP-32 path is truncated, expected %d bytes, got %d", 1+length*4, len(data))
+	}
+	paths := make([]uint32, length)
+	for i := range paths {
+		paths[i] = binary.BigEndian.Uint32(data[1+i*4:])
+	}
+
+	return BIP32Path(JoinBIP32Paths(paths)), data[1+length*4:], nil
+}
+
 func (p *BIP32Path) Len() int {
 	paths, err := SplitBIP32Paths(string(*p))
 	if err != nil {
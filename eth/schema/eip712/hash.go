@@ -0,0 +1,191 @@
+package eip712
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ntchjb/ledger-go/eth/schema"
+)
+
+var (
+	ErrInvalidMessage = errors.New("invalid EIP-712 message")
+)
+
+// Compute EIP-712 `hashStruct` of domain, i.e. domain separator, and of primary data,
+// which are the same hashes as signed by `SignEIP712MessageHash`
+func (m *Message) Hash() (domainSeparator [32]byte, messageHash [32]byte, err error) {
+	types := make(map[string][]FieldDefinition, len(m.Types)+1)
+	for _, typeDef := range m.Types {
+		types[typeDef.Name] = typeDef.Members
+	}
+	if _, ok := types[DOMAIN_TYPE_NAME]; !ok {
+		types[DOMAIN_TYPE_NAME] = m.Domain.TypeStruct().Members
+	}
+
+	hash, err := hashStruct(types, m.Domain.StructItem())
+	if err != nil {
+		return domainSeparator, messageHash, fmt.Errorf("unable to hash domain: %w", err)
+	}
+	copy(domainSeparator[:], hash)
+	if hash, err = hashStruct(types, m.Primary); err != nil {
+		return domainSeparator, messageHash, fmt.Errorf("unable to hash primary data: %w", err)
+	}
+	copy(messageHash[:], hash)
+
+	return domainSeparator, messageHash, nil
+}
+
+// Hash signed by device, keccak256("\x19\x01" || domainSeparator || messageHash)
+func (m *Message) SigningHash() ([32]byte, error) {
+	var res [32]byte
+	domainSeparator, messageHash, err := m.Hash()
+	if err != nil {
+		return res, err
+	}
+	copy(res[:], schema.Keccak256([]byte{0x19, 0x01}, domainSeparator[:], messageHash[:]))
+
+	return res, nil
+}
+
+// Collect names of custom types referenced by the type, including itself
+func dependencies(types map[string][]FieldDefinition, typeName string, found map[string]bool) error {
+	if found[typeName] {
+		return nil
+	}
+	fields, ok := types[typeName]
+	if !ok {
+		return fmt.Errorf("type %s is not defined: %w", typeName, ErrInvalidMessage)
+	}
+	found[typeName] = true
+	for _, field := range fields {
+		if field.TypeDescription.Type != FIELD_TYPE_DESC_TYPE_CUSTOM {
+			continue
+		}
+		if err := dependencies(types, field.CustomTypeName, found); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EIP-712 `typeHash`, keccak256 of `encodeType`
+// i.e. `Mail(Person from,Person to,string contents)Person(string name,address wallet)`
+func typeHash(types map[string][]FieldDefinition, typeName string) ([]byte, error) {
+	found := make(map[string]bool)
+	if err := dependencies(types, typeName, found); err != nil {
+		return nil, err
+	}
+	delete(found, typeName)
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var encodeType strings.Builder
+	for _, name := range append([]string{typeName}, names...) {
+		members := make([]string, len(types[name]))
+		for i, field := range types[name] {
+			members[i] = field.TypeName() + " " + field.KeyName
+		}
+		encodeType.WriteString(name + "(" + strings.Join(members, ",") + ")")
+	}
+
+	return schema.Keccak256([]byte(encodeType.String())), nil
+}
+
+// EIP-712 `hashStruct`, keccak256(typeHash || encodeData)
+func hashStruct(types map[string][]FieldDefinition, item StructItem) ([]byte, error) {
+	hash, err := typeHash(types, item.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	fields := types[item.TypeName]
+	if len(fields) != len(item.Members) {
+		return nil, fmt.Errorf("expected %d members of %s, got %d: %w", len(fields), item.TypeName, len(item.Members), ErrInvalidMessage)
+	}
+
+	encoded := hash
+	for i, field := range fields {
+		if item.Members[i].Name != field.KeyName {
+			return nil, fmt.Errorf("expected member %s of %s, got %s: %w", field.KeyName, item.TypeName, item.Members[i].Name, ErrInvalidMessage)
+		}
+		value, err := encodeValue(types, field, len(field.ArrayLevels), item.Members[i].Item)
+		if err != nil {
+			return nil, fmt.Errorf("member %s of %s: %w", field.KeyName, item.TypeName, err)
+		}
+		encoded = append(encoded, value...)
+	}
+
+	return schema.Keccak256(encoded), nil
+}
+
+// Encode value of the field to 32 bytes, where `levels` is the number of array levels of the value.
+// The outermost level is the last of `ArrayLevels`, i.e. `uint8[2][]` is a dynamic array of `uint8[2]`.
+func encodeValue(types map[string][]FieldDefinition, field FieldDefinition, levels int, item Item) ([]byte, error) {
+	if levels > 0 {
+		array, ok := item.(ArrayItem)
+		if !ok {
+			return nil, fmt.Errorf("expected array, got item type %d: %w", item.Type(), ErrInvalidMessage)
+		}
+		level := field.ArrayLevels[levels-1]
+		if level.Type == STRUCT_DEF_ARRAY_TYPE_FIXED && len(array) != int(level.FixedArraySize) {
+			return nil, fmt.Errorf("expected %d array items, got %d: %w", level.FixedArraySize, len(array), ErrInvalidMessage)
+		}
+		var encoded []byte
+		for _, elem := range array {
+			value, err := encodeValue(types, field, levels-1, elem)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, value...)
+		}
+		return schema.Keccak256(encoded), nil
+	}
+
+	if field.TypeDescription.Type == FIELD_TYPE_DESC_TYPE_CUSTOM {
+		structItem, ok := item.(StructItem)
+		if !ok || structItem.TypeName != field.CustomTypeName {
+			return nil, fmt.Errorf("expected struct %s: %w", field.CustomTypeName, ErrInvalidMessage)
+		}
+		return hashStruct(types, structItem)
+	}
+
+	atomic, ok := item.(AtomicItem)
+	if !ok {
+		return nil, fmt.Errorf("expected atomic value, got item type %d: %w", item.Type(), ErrInvalidMessage)
+	}
+
+	return encodeAtomic(field, atomic.Item.Encode())
+}
+
+// Encode atomic value, as sent to device, to 32 bytes as EIP-712 `encodeData` does
+func encodeAtomic(field FieldDefinition, value []byte) ([]byte, error) {
+	switch field.TypeDescription.Type {
+	case FIELD_TYPE_DESC_TYPE_STRING, FIELD_TYPE_DESC_TYPE_DYNAMIC_SIZED_BYTES:
+		return schema.Keccak256(value), nil
+	}
+	if len(value) > 32 {
+		return nil, fmt.Errorf("value of %s is longer than 32 bytes: %w", field.TypeName(), ErrInvalidMessage)
+	}
+
+	res := make([]byte, 32)
+	switch field.TypeDescription.Type {
+	case FIELD_TYPE_DESC_TYPE_FIXED_SIZE_BYTES:
+		copy(res, value)
+		return res, nil
+	case FIELD_TYPE_DESC_TYPE_INT:
+		// Negative number is encoded as two's complement of its type size
+		if len(value) > 0 && len(value) == int(field.TypeSize) && value[0]&0x80 != 0 {
+			for i := range res {
+				res[i] = 0xFF
+			}
+		}
+	}
+	copy(res[32-len(value):], value)
+
+	return res, nil
+}
@@ -3,6 +3,7 @@ package eip712
 import (
 	"errors"
 	"fmt"
+	"strconv"
 )
 
 type Component uint8
@@ -59,6 +60,8 @@ const (
 	FIELD_TYPE_DESC_TYPE_STRING              FieldType = 0x05
 	FIELD_TYPE_DESC_TYPE_FIXED_SIZE_BYTES    FieldType = 0x06
 	FIELD_TYPE_DESC_TYPE_DYNAMIC_SIZED_BYTES FieldType = 0x07
+
+	FIELD_TYPE_DESC_TYPE_MASK FieldType = 0x0F
 )
 
 var (
@@ -146,3 +149,131 @@ func (s *FieldDefinition) MarshalADPU() ([]byte, error) {
 
 	return res, nil
 }
+
+func (s *FieldDefinition) UnmarshalADPU(data []byte) error {
+	offset := 0
+	readByte := func(name string) (byte, error) {
+		if offset >= len(data) {
+			return 0, fmt.Errorf("data is too short to read %s at offset %d: %w", name, offset, ErrInvalidData)
+		}
+		offset++
+		return data[offset-1], nil
+	}
+	readString := func(name string) (string, error) {
+		length, err := readByte(name + " length")
+		if err != nil {
+			return "", err
+		}
+		if offset+int(length) > len(data) {
+			return "", fmt.Errorf("data is too short to read %s, expected %d, got %d: %w", name, length, len(data)-offset, ErrInvalidData)
+		}
+		offset += int(length)
+		return string(data[offset-int(length) : offset]), nil
+	}
+	var res FieldDefinition
+
+	// #1: Get type description
+	typeDesc, err := readByte("type description")
+	if err != nil {
+		return err
+	}
+	res.TypeDescription.IsArray = typeDesc&byte(FIELD_TYPE_DESC_IS_ARRAY) != 0
+	res.TypeDescription.IsSizeSpecified = typeDesc&byte(FIELD_TYPE_DESC_IS_SIZE_SPECIFIED) != 0
+	res.TypeDescription.Type = FieldType(typeDesc) & FIELD_TYPE_DESC_TYPE_MASK
+	if res.TypeDescription.Type > FIELD_TYPE_DESC_TYPE_DYNAMIC_SIZED_BYTES {
+		return fmt.Errorf("unknown field type %d: %w", res.TypeDescription.Type, ErrInvalidData)
+	}
+
+	// #2: Get type name for custom type
+	if res.TypeDescription.Type == FIELD_TYPE_DESC_TYPE_CUSTOM {
+		if res.CustomTypeName, err = readString("type name"); err != nil {
+			return err
+		}
+	}
+
+	// #3: Get type size
+	if res.TypeDescription.IsSizeSpecified {
+		if res.TypeSize, err = readByte("type size"); err != nil {
+			return err
+		}
+	}
+
+	// #4: Get array levels
+	if res.TypeDescription.IsArray {
+		levelCount, err := readByte("array level count")
+		if err != nil {
+			return err
+		}
+		for i := 0; i < int(levelCount); i++ {
+			levelType, err := readByte("array level type")
+			if err != nil {
+				return err
+			}
+			level := FieldArrayLevel{
+				Type: StructDefArrayType(levelType),
+			}
+			switch level.Type {
+			case STRUCT_DEF_ARRAY_TYPE_FIXED:
+				if level.FixedArraySize, err = readByte("fixed array size"); err != nil {
+					return err
+				}
+			case STRUCT_DEF_ARRAY_TYPE_DYNAMIC:
+			default:
+				return fmt.Errorf("unknown array level type %d: %w", levelType, ErrInvalidData)
+			}
+			res.ArrayLevels = append(res.ArrayLevels, level)
+		}
+	}
+
+	// #5: Get field name
+	if res.KeyName, err = readString("key name"); err != nil {
+		return err
+	}
+	if offset != len(data) {
+		return fmt.Errorf("unexpected trailing data, %d bytes: %w", len(data)-offset, ErrInvalidData)
+	}
+
+	*s = res
+
+	return nil
+}
+
+// Solidity type name of the field, as used in EIP-712 `encodeType` i.e. `uint256`, `Person[]`, `bytes32[2]`
+func (s *FieldDefinition) TypeName() string {
+	var name string
+	switch s.TypeDescription.Type {
+	case FIELD_TYPE_DESC_TYPE_CUSTOM:
+		name = s.CustomTypeName
+	case FIELD_TYPE_DESC_TYPE_INT:
+		name = "int"
+	case FIELD_TYPE_DESC_TYPE_UINT:
+		name = "uint"
+	case FIELD_TYPE_DESC_TYPE_ADDRESS:
+		name = "address"
+	case FIELD_TYPE_DESC_TYPE_BOOL:
+		name = "bool"
+	case FIELD_TYPE_DESC_TYPE_STRING:
+		name = "string"
+	case FIELD_TYPE_DESC_TYPE_FIXED_SIZE_BYTES, FIELD_TYPE_DESC_TYPE_DYNAMIC_SIZED_BYTES:
+		name = "bytes"
+	}
+
+	if s.TypeDescription.IsSizeSpecified {
+		switch s.TypeDescription.Type {
+		case FIELD_TYPE_DESC_TYPE_INT, FIELD_TYPE_DESC_TYPE_UINT:
+			name += strconv.Itoa(int(s.TypeSize) * 8)
+		case FIELD_TYPE_DESC_TYPE_FIXED_SIZE_BYTES:
+			name += strconv.Itoa(int(s.TypeSize))
+		}
+	}
+
+	for _, level := range s.ArrayLevels {
+		if level.Type == STRUCT_DEF_ARRAY_TYPE_FIXED {
+			name += "[" + strconv.Itoa(int(level.FixedArraySize)) + "]"
+		} else {
+			name += "[]"
+		}
+	}
+
+	return name
+}
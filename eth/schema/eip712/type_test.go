@@ -121,3 +121,136 @@ func TestFieldDefinition_MarshalADPU(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldDefinition_UnmarshalADPU(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		fieldDef eip712.FieldDefinition
+		typeName string
+		err      error
+	}{
+		{
+			name: "Success_AtomicType_FixedSize",
+			data: []byte{
+				0b0100_0010,
+				0x08,
+				0x06, 0x66, 0x69, 0x65, 0x6C, 0x64, 0x31,
+			},
+			fieldDef: eip712.FieldDefinition{
+				TypeDescription: eip712.FieldTypeDescription{
+					IsSizeSpecified: true,
+					Type:            eip712.FIELD_TYPE_DESC_TYPE_UINT,
+				},
+				TypeSize: 8,
+				KeyName:  "field1",
+			},
+			typeName: "uint64",
+		},
+		{
+			name: "Success_FixedSizeBytes",
+			data: []byte{
+				0b0100_0110,
+				0x20,
+				0x04, 0x73, 0x61, 0x6C, 0x74,
+			},
+			fieldDef: eip712.FieldDefinition{
+				TypeDescription: eip712.FieldTypeDescription{
+					IsSizeSpecified: true,
+					Type:            eip712.FIELD_TYPE_DESC_TYPE_FIXED_SIZE_BYTES,
+				},
+				TypeSize: 32,
+				KeyName:  "salt",
+			},
+			typeName: "bytes32",
+		},
+		{
+			name: "Success_AtomicType_Array_uint8[3][]",
+			data: []byte{
+				0b1100_0010,
+				0x01,
+				0x02,
+				0x01, 0x03,
+				0x00,
+				0x06, 0x66, 0x69, 0x65, 0x6C, 0x64, 0x31,
+			},
+			fieldDef: eip712.FieldDefinition{
+				TypeDescription: eip712.FieldTypeDescription{
+					IsArray:         true,
+					IsSizeSpecified: true,
+					Type:            eip712.FIELD_TYPE_DESC_TYPE_UINT,
+				},
+				TypeSize: 1,
+				ArrayLevels: []eip712.FieldArrayLevel{
+					{
+						Type:           eip712.STRUCT_DEF_ARRAY_TYPE_FIXED,
+						FixedArraySize: 3,
+					},
+					{
+						Type: eip712.STRUCT_DEF_ARRAY_TYPE_DYNAMIC,
+					},
+				},
+				KeyName: "field1",
+			},
+			typeName: "uint8[3][]",
+		},
+		{
+			name: "Success_CustomType",
+			data: []byte{
+				0b1000_0000,
+				0x06, 0x50, 0x65, 0x72, 0x73, 0x6F, 0x6E,
+				0x01, 0x00,
+				0x02, 0x74, 0x6F,
+			},
+			fieldDef: eip712.FieldDefinition{
+				TypeDescription: eip712.FieldTypeDescription{
+					IsArray: true,
+					Type:    eip712.FIELD_TYPE_DESC_TYPE_CUSTOM,
+				},
+				CustomTypeName: "Person",
+				ArrayLevels: []eip712.FieldArrayLevel{
+					{
+						Type: eip712.STRUCT_DEF_ARRAY_TYPE_DYNAMIC,
+					},
+				},
+				KeyName: "to",
+			},
+			typeName: "Person[]",
+		},
+		{
+			name: "Error_UnknownType",
+			data: []byte{0x0A, 0x00},
+			err:  eip712.ErrInvalidData,
+		},
+		{
+			name: "Error_TruncatedTypeName",
+			data: []byte{0x00, 0x06, 0x50, 0x65},
+			err:  eip712.ErrInvalidData,
+		},
+		{
+			name: "Error_UnknownArrayLevelType",
+			data: []byte{0b1000_0011, 0x01, 0x02, 0x00},
+			err:  eip712.ErrInvalidData,
+		},
+		{
+			name: "Error_TrailingData",
+			data: []byte{0x03, 0x00, 0xFF},
+			err:  eip712.ErrInvalidData,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var fieldDef eip712.FieldDefinition
+			err := fieldDef.UnmarshalADPU(test.data)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.fieldDef, fieldDef)
+			if test.err == nil {
+				assert.Equal(t, test.typeName, fieldDef.TypeName())
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package schema
+
+import "golang.org/x/crypto/sha3"
+
+// Legacy Keccak-256 hash of concatenated data, as used by Ethereum
+func Keccak256(data ...[]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		hasher.Write(b)
+	}
+
+	return hasher.Sum(nil)
+}
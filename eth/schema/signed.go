@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/rlp"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Signed transaction, ready to be broadcast i.e. by `eth_sendRawTransaction`
+type SignedTx struct {
+	// EIP-2718 transaction envelope, or RLP-encoded legacy transaction
+	Raw []byte
+	// Transaction hash, keccak256(Raw)
+	Hash [32]byte
+}
+
+// Assemble signed transaction from unsigned raw tx and its signature returned by `SignTransaction`.
+//
+// V of legacy transaction must be already recovered, which is either 27/28,
+// or chain_id * 2 + 35/36 for EIP-155 transaction.
+// V of typed transaction is y parity, 0/1, where 27/28 is also accepted.
+func AssembleSignedTx(rawTx []byte, sig SignDataResponse) (SignedTx, error) {
+	var res SignedTx
+	txInfo, err := DecodeTxInfo(rawTx)
+	if err != nil {
+		return res, fmt.Errorf("unable to decode raw tx info: %w", err)
+	}
+	rlpPart := rawTx
+	if txInfo.TxType != TX_TYPE_LEGACY {
+		rlpPart = rawTx[1:]
+	}
+	// Already validated by `DecodeTxInfo`
+	item, _, err := rlp.DecodeStrict(rlpPart)
+	if err != nil {
+		return res, fmt.Errorf("unable to decode raw tx: %w: %w", ErrInvalidTx, err)
+	}
+
+	var v uint64
+	if txInfo.TxType == TX_TYPE_LEGACY {
+		v, err = legacySignatureV(item, sig.V)
+		item.List = item.List[:LEGACY_TX_FIELD_COUNT]
+	} else {
+		v, err = signatureYParity(sig.V)
+	}
+	if err != nil {
+		return res, err
+	}
+	item.List = append(item.List,
+		rlp.NewUint(v),
+		rlp.NewUint256(new(uint256.Int).SetBytes(sig.R[:])),
+		rlp.NewUint256(new(uint256.Int).SetBytes(sig.S[:])),
+	)
+
+	encoded, err := rlp.Encode(item)
+	if err != nil {
+		return res, fmt.Errorf("unable to encode signed tx: %w", err)
+	}
+	if txInfo.TxType != TX_TYPE_LEGACY {
+		encoded = append([]byte{byte(txInfo.TxType)}, encoded...)
+	}
+
+	res.Raw = encoded
+	copy(res.Hash[:], Keccak256(encoded))
+
+	return res, nil
+}
+
+// V of signed legacy transaction, which must match chain ID of unsigned transaction
+func legacySignatureV(item rlp.Item, v SignatureV) (uint64, error) {
+	if len(item.List) == LEGACY_TX_FIELD_COUNT {
+		if v != 27 && v != 28 {
+			return 0, fmt.Errorf("expected V of 27 or 28 for pre-EIP-155 tx, got %d: %w", v, ErrInvalidSignature)
+		}
+		return uint64(v), nil
+	}
+
+	chainID := item.List[LEGACY_TX_FIELD_COUNT].Uint64()
+	if uint64(v) != chainID*2+35 && uint64(v) != chainID*2+36 {
+		return 0, fmt.Errorf("expected V of %d or %d for chain ID %d, got %d: %w", chainID*2+35, chainID*2+36, chainID, v, ErrInvalidSignature)
+	}
+
+	return uint64(v), nil
+}
+
+// Y parity of typed transaction signature
+func signatureYParity(v SignatureV) (uint64, error) {
+	switch v {
+	case 0, 1:
+		return uint64(v), nil
+	case 27, 28:
+		return uint64(v - 27), nil
+	}
+
+	return 0, fmt.Errorf("expected V of 0 or 1 for typed tx, got %d: %w", v, ErrInvalidSignature)
+}
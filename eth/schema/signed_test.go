@@ -0,0 +1,109 @@
+package schema_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func TestAssembleSignedTx(t *testing.T) {
+	to := schema.Address{0xd8, 0xda, 0x6b, 0xf2}
+	sig := schema.SignDataResponse{
+		R: [32]byte(mustDecodeHex("28ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276")),
+		S: [32]byte(mustDecodeHex("67cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83")),
+	}
+	withV := func(v schema.SignatureV) schema.SignDataResponse {
+		res := sig
+		res.V = v
+		return res
+	}
+	dynamicFee, err := (&schema.DynamicFeeTx{ChainID: 1, Nonce: 7, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: &to}).MarshalUnsigned()
+	assert.NoError(t, err)
+	preEIP155, err := (&schema.LegacyTx{Nonce: 9, GasPrice: uint256.NewInt(1), Gas: 21000, To: &to}).MarshalUnsigned()
+	assert.NoError(t, err)
+
+	r, s := new(uint256.Int).SetBytes(sig.R[:]), new(uint256.Int).SetBytes(sig.S[:])
+	signedDynamicFee := append([]byte{0x02}, mustMarshalRLP(t, []any{uint64(1), uint64(7), uint64(1), uint64(2), uint64(21000), to, uint64(0), []byte{}, []any{}, uint64(1), r, s})...)
+
+	tests := []struct {
+		name     string
+		rawTx    []byte
+		sig      schema.SignDataResponse
+		expected []byte
+		err      error
+	}{
+		{
+			// Example of EIP-155 specification
+			name:     "Success_Legacy_EIP155",
+			rawTx:    mustDecodeHex("ec098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080018080"),
+			sig:      withV(37),
+			expected: mustDecodeHex("f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"),
+		},
+		{
+			name:     "Success_Legacy_PreEIP155",
+			rawTx:    preEIP155,
+			sig:      withV(28),
+			expected: mustMarshalRLP(t, []any{uint64(9), uint64(1), uint64(21000), to, uint64(0), []byte{}, uint64(28), r, s}),
+		},
+		{
+			name:     "Success_DynamicFee",
+			rawTx:    dynamicFee,
+			sig:      withV(1),
+			expected: signedDynamicFee,
+		},
+		{
+			name:     "Success_DynamicFee_V28",
+			rawTx:    dynamicFee,
+			sig:      withV(28),
+			expected: signedDynamicFee,
+		},
+		{
+			name:  "Error_Legacy_WrongChainID",
+			rawTx: mustDecodeHex("ec098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080018080"),
+			sig:   withV(27),
+			err:   schema.ErrInvalidSignature,
+		},
+		{
+			name:  "Error_PreEIP155_EIP155V",
+			rawTx: preEIP155,
+			sig:   withV(37),
+			err:   schema.ErrInvalidSignature,
+		},
+		{
+			name:  "Error_DynamicFee_InvalidV",
+			rawTx: dynamicFee,
+			sig:   withV(37),
+			err:   schema.ErrInvalidSignature,
+		},
+		{
+			name:  "Error_InvalidTx",
+			rawTx: []byte{0x02, 0xc0},
+			sig:   withV(0),
+			err:   schema.ErrInvalidTx,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signedTx, err := schema.AssembleSignedTx(test.rawTx, test.sig)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.expected, signedTx.Raw)
+			if test.err == nil {
+				assert.Equal(t, [32]byte(schema.Keccak256(test.expected)), signedTx.Hash)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package schema
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/ntchjb/ledger-go/adpu"
 	"github.com/ntchjb/ledger-go/eth/rlp"
@@ -43,13 +45,38 @@ const (
 	//
 	// signed tx: 0x03|| rlp([chain_id, nonce, max_priority_fee_per_gas, max_fee_per_gas, gas_limit, to, value, data, access_list, max_fee_per_blob_gas, blob_versioned_hashes, y_parity, r, s])
 	TX_TYPE_BLOB TxType = 0x03
+	// EIP-7702 transaction type
+	//
+	// unsigned: 0x04 || rlp([chain_id, nonce, max_priority_fee_per_gas, max_fee_per_gas, gas_limit, destination, value, data, access_list, authorization_list])
+	//
+	// signing content: keccak256(unsigned)
+	//
+	// signed tx: 0x04 || rlp([chain_id, nonce, max_priority_fee_per_gas, max_fee_per_gas, gas_limit, destination, value, data, access_list, authorization_list, signature_y_parity, signature_r, signature_s])
+	TX_TYPE_SET_CODE TxType = 0x04
+)
+
+const (
+	// Number of fields of legacy transaction without EIP-155 fields, [nonce, gasprice, startgas, to, value, data]
+	LEGACY_TX_FIELD_COUNT int = 6
 )
 
 var (
+	ErrInvalidTx = errors.New("invalid transaction")
+
+	// Number of fields of unsigned transaction, by type
+	unsignedTxFieldCounts = map[TxType][]int{
+		TX_TYPE_LEGACY:      {6, 9},
+		TX_TYPE_ACCESS_LIST: {8},
+		TX_TYPE_DYNAMIC_FEE: {9},
+		TX_TYPE_BLOB:        {11},
+		TX_TYPE_SET_CODE:    {10},
+	}
+
 	EIP2718TransactionTypes []bool = []bool{
 		TX_TYPE_ACCESS_LIST: true,
 		TX_TYPE_DYNAMIC_FEE: true,
 		TX_TYPE_BLOB:        true,
+		TX_TYPE_SET_CODE:    true,
 	}
 
 	SupportedTxTypes []bool = []bool{
@@ -57,6 +84,7 @@ var (
 		TX_TYPE_ACCESS_LIST: true,
 		TX_TYPE_DYNAMIC_FEE: true,
 		TX_TYPE_BLOB:        false,
+		TX_TYPE_SET_CODE:    false,
 	}
 )
 
@@ -67,17 +95,27 @@ type TxInfo struct {
 	TxType TxType
 	// Transaction data payload i.e. calldata
 	Data []byte
-	// Target address used by this tx
+	// Target address used by this tx, which is zero address for contract creation
 	To Address
+	// Whether this tx creates a contract, where `to` is empty
+	IsCreate bool
 	// Chain ID i.e. ethereum = 0x01
 	ChainID ChainID
+	// Whether chain ID is part of this tx. It is false for pre-EIP-155 legacy tx,
+	// whose signature can be replayed on every chain, even though `ChainID` is 1.
+	HasChainID bool
 	// Beginning position of chain ID data
 	// This will be used to mitigate Ledger bug
 	ChainIDOffset int
 }
 
+// Decode information of unsigned transaction needed for signing.
+// RLP data must be in canonical form, so malformed transactions are refused before reaching device.
 func DecodeTxInfo(rawTx []byte) (TxInfo, error) {
 	var txInfo TxInfo
+	if len(rawTx) == 0 {
+		return txInfo, fmt.Errorf("empty raw tx: %w", ErrInvalidTx)
+	}
 	// For Legacy Tx, this byte is >=0xC0 due to RLP encoding
 	txType := TX_TYPE_LEGACY
 	if int(rawTx[0]) < len(EIP2718TransactionTypes) && EIP2718TransactionTypes[rawTx[0]] {
@@ -88,38 +126,42 @@ func DecodeTxInfo(rawTx []byte) (TxInfo, error) {
 		rlpPart = rlpPart[1:]
 	}
 
-	rlpItem, n, err := rlp.Decode(rlpPart)
+	rlpItem, n, err := rlp.DecodeStrict(rlpPart)
 	if err != nil {
-		return txInfo, fmt.Errorf("unable to decode RLP data from raw tx: %w", err)
+		return txInfo, fmt.Errorf("unable to decode RLP data from raw tx: %w: %w", ErrInvalidTx, err)
 	}
 	if n < len(rlpPart) {
-		return txInfo, fmt.Errorf("incomplete RLP data decoding, expected %d, but got %d decoded", len(rlpPart), n)
+		return txInfo, fmt.Errorf("incomplete RLP data decoding, expected %d, but got %d decoded: %w", len(rlpPart), n, ErrInvalidTx)
+	}
+	if err := validateTxFields(txType, rlpItem); err != nil {
+		return txInfo, err
 	}
 
-	var data []byte
-	var to Address
+	var data, toData []byte
 	var chainID ChainID
+	hasChainID := true
 	switch txType {
-	case TX_TYPE_BLOB:
-		fallthrough
-	case TX_TYPE_DYNAMIC_FEE:
+	case TX_TYPE_DYNAMIC_FEE, TX_TYPE_BLOB, TX_TYPE_SET_CODE:
 		data = rlpItem.List[7].Data
-		to = Address(rlpItem.List[5].Data)
+		toData = rlpItem.List[5].Data
 		chainID = ChainID(rlpItem.List[0].Uint64())
 	case TX_TYPE_ACCESS_LIST:
 		data = rlpItem.List[6].Data
-		to = Address(rlpItem.List[4].Data)
+		toData = rlpItem.List[4].Data
 		chainID = ChainID(rlpItem.List[0].Uint64())
 	default:
 		data = rlpItem.List[5].Data
-		to = Address(rlpItem.List[3].Data)
+		toData = rlpItem.List[3].Data
 		if len(rlpItem.List) > 6 {
 			chainID = ChainID(rlpItem.List[6].Uint64())
 		} else {
 			// For non EIP-155 transaction
 			chainID = 1
+			hasChainID = false
 		}
 	}
+	var to Address
+	copy(to[:], toData)
 
 	chainIDOffset := 0
 	if txType == TX_TYPE_LEGACY && len(rlpItem.List) > 6 {
@@ -136,13 +178,57 @@ func DecodeTxInfo(rawTx []byte) (TxInfo, error) {
 
 	txInfo.TxType = txType
 	txInfo.ChainID = chainID
+	txInfo.HasChainID = hasChainID
 	txInfo.To = to
+	txInfo.IsCreate = len(toData) == 0
 	txInfo.Data = data
 	txInfo.ChainIDOffset = chainIDOffset
 
 	return txInfo, nil
 }
 
+// Check fields of unsigned transaction that are used by `DecodeTxInfo`
+func validateTxFields(txType TxType, tx rlp.Item) error {
+	if tx.List == nil {
+		return fmt.Errorf("expected list of transaction fields, got string: %w", ErrInvalidTx)
+	}
+	if !slices.Contains(unsignedTxFieldCounts[txType], len(tx.List)) {
+		return fmt.Errorf("expected %v fields for tx type %d, got %d: %w", unsignedTxFieldCounts[txType], txType, len(tx.List), ErrInvalidTx)
+	}
+
+	chainIDIndex, toIndex, dataIndex := 0, 4, 6
+	switch txType {
+	case TX_TYPE_LEGACY:
+		chainIDIndex, toIndex, dataIndex = 6, 3, 5
+	case TX_TYPE_DYNAMIC_FEE, TX_TYPE_BLOB, TX_TYPE_SET_CODE:
+		toIndex, dataIndex = 5, 7
+	}
+	for _, index := range []int{chainIDIndex, toIndex, dataIndex} {
+		if index < len(tx.List) && tx.List[index].List != nil {
+			return fmt.Errorf("expected string at field %d, got list: %w", index, ErrInvalidTx)
+		}
+	}
+	if to := tx.List[toIndex].Data; len(to) != 0 && len(to) != ADDRESS_LENGTH {
+		return fmt.Errorf("expected %d bytes of `to` address, got %d: %w", ADDRESS_LENGTH, len(to), ErrInvalidTx)
+	}
+	if chainIDIndex < len(tx.List) {
+		chainID := tx.List[chainIDIndex].Data
+		if len(chainID) > 8 || (len(chainID) > 0 && chainID[0] == 0) {
+			return fmt.Errorf("expected chain ID of at most 8 bytes without leading zeros, got 0x%x: %w", chainID, ErrInvalidTx)
+		}
+	}
+	// r and s of EIP-155 unsigned tx are empty
+	if txType == TX_TYPE_LEGACY && len(tx.List) > LEGACY_TX_FIELD_COUNT {
+		for i, zero := range tx.List[LEGACY_TX_FIELD_COUNT+1:] {
+			if zero.List != nil || len(zero.Data) != 0 {
+				return fmt.Errorf("expected empty r and s of EIP-155 unsigned tx at field %d: %w", LEGACY_TX_FIELD_COUNT+1+i, ErrInvalidTx)
+			}
+		}
+	}
+
+	return nil
+}
+
 type SignTxRequest struct {
 	// HD wallet path used for signing
 	BIP32Path BIP32Path
@@ -213,8 +299,11 @@ func (v SignatureV) RecoverLegacy(chainID ChainID) SignatureV {
 	// and try re-calculate V value from the Y parity and chain ID.
 	if chainID*2+35+1 > 255 {
 		// Simulate what Ledger device calculates
-		// by getting highest 4 bytes of chainID
-		chainIDTruncated := chainID >> 32
+		// by getting the first 4 bytes of big-endian encoded chainID, without leading zeros
+		chainIDTruncated := chainID
+		for chainIDTruncated > 0xFFFF_FFFF {
+			chainIDTruncated >>= 8
+		}
 		// V value returned from Ledger can be either
 		// - (chain_id * 2 + 35 + 0) % 256
 		// - (chain_id * 2 + 35 + 1) % 256
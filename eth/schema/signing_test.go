@@ -0,0 +1,183 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/ntchjb/ledger-go/eth/rlp"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustMarshalRLP(t *testing.T, v any) []byte {
+	encoded, err := rlp.Marshal(v)
+	assert.NoError(t, err)
+
+	return encoded
+}
+
+func TestDecodeTxInfo(t *testing.T) {
+	to := schema.Address{0xd8, 0xda, 0x6b, 0xf2}
+	data := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	legacy := mustMarshalRLP(t, []any{uint64(7), uint64(20_000_000_000), uint64(21000), to, uint64(1), data, uint64(137), uint64(0), uint64(0)})
+	dynamicFee := append([]byte{0x02}, mustMarshalRLP(t, []any{uint64(1), uint64(7), uint64(1), uint64(2), uint64(21000), to, uint64(1), data, []any{}})...)
+
+	tests := []struct {
+		name   string
+		rawTx  []byte
+		txInfo schema.TxInfo
+		err    error
+	}{
+		{
+			name:  "Success_Legacy",
+			rawTx: legacy,
+			txInfo: schema.TxInfo{
+				TxType:        schema.TX_TYPE_LEGACY,
+				Data:          data,
+				To:            to,
+				ChainID:       137,
+				HasChainID:    true,
+				ChainIDOffset: len(legacy) - 4,
+			},
+		},
+		{
+			name:  "Success_LegacyWithoutChainID",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), data}),
+			// Chain ID 1 is used for signing, but the signature is valid on every chain
+			txInfo: schema.TxInfo{
+				TxType:     schema.TX_TYPE_LEGACY,
+				Data:       data,
+				To:         to,
+				ChainID:    1,
+				HasChainID: false,
+			},
+		},
+		{
+			name:  "Success_DynamicFee",
+			rawTx: dynamicFee,
+			txInfo: schema.TxInfo{
+				TxType:     schema.TX_TYPE_DYNAMIC_FEE,
+				Data:       data,
+				To:         to,
+				ChainID:    1,
+				HasChainID: true,
+			},
+		},
+		{
+			name:  "Success_ContractCreation",
+			rawTx: append([]byte{0x01}, mustMarshalRLP(t, []any{uint64(5), uint64(7), uint64(1), uint64(21000), []byte{}, uint64(0), data, []any{}})...),
+			txInfo: schema.TxInfo{
+				TxType:     schema.TX_TYPE_ACCESS_LIST,
+				Data:       data,
+				IsCreate:   true,
+				ChainID:    5,
+				HasChainID: true,
+			},
+		},
+		{
+			name:  "Error_Empty",
+			rawTx: []byte{},
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_NonCanonical",
+			rawTx: []byte{0x02, 0xca, 0x81, 0x01, 0x07, 0x01, 0x02, 0x80, 0x80, 0x80, 0x80, 0xc0},
+			err:   rlp.ErrNonCanonicalSingleByte,
+		},
+		{
+			name:  "Error_TrailingData",
+			rawTx: append(append([]byte{}, legacy...), 0x00),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_NotList",
+			rawTx: []byte{0x02, 0x83, 'd', 'o', 'g'},
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_FieldCount",
+			rawTx: append([]byte{0x02}, mustMarshalRLP(t, []any{uint64(1), uint64(7)})...),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_ToLength",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), []byte{0x01, 0x02}, uint64(1), data}),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_DataIsList",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), []any{}}),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_ChainIDTooLarge",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), data, make([]byte, 9), uint64(0), uint64(0)}),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_EIP155NonEmptyR",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), data, uint64(1), uint64(1), uint64(0)}),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_EIP155NonEmptyS",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), data, uint64(1), uint64(0), uint64(1)}),
+			err:   schema.ErrInvalidTx,
+		},
+		{
+			name:  "Error_EIP155SIsList",
+			rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), data, uint64(1), uint64(0), []any{}}),
+			err:   schema.ErrInvalidTx,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txInfo, err := schema.DecodeTxInfo(test.rawTx)
+
+			assert.ErrorIs(t, err, test.err)
+			if test.err == nil {
+				assert.Equal(t, test.txInfo, txInfo)
+			}
+		})
+	}
+}
+
+func TestSignatureV_RecoverLegacy(t *testing.T) {
+	tests := []struct {
+		name     string
+		chainID  schema.ChainID
+		v        schema.SignatureV
+		expected schema.SignatureV
+	}{
+		{name: "PreEIP155", chainID: 0, v: 27, expected: 27},
+		{name: "Mainnet_Parity0", chainID: 1, v: 37, expected: 37},
+		{name: "Mainnet_Parity1", chainID: 1, v: 38, expected: 38},
+		// 109 * 2 + 36 = 254 still fits in 1 byte
+		{name: "Fit_Parity0", chainID: 109, v: 253, expected: 253},
+		{name: "Fit_Parity1", chainID: 109, v: 254, expected: 254},
+		// 110 * 2 + 36 = 256 overflows 1 byte, where V wraps around from 255 to 0
+		{name: "Boundary_Parity0", chainID: 110, v: 255, expected: 255},
+		{name: "Boundary_Parity1", chainID: 110, v: 0, expected: 256},
+		// (137 * 2 + 35) % 256 = 53
+		{name: "Polygon_Parity0", chainID: 137, v: 53, expected: 309},
+		{name: "Polygon_Parity1", chainID: 137, v: 54, expected: 310},
+		// 0xFFFFFFFF is the largest chain ID used by device as-is, (0xFFFFFFFF * 2 + 35) % 256 = 33
+		{name: "MaxUint32_Parity0", chainID: 0xFFFF_FFFF, v: 33, expected: 0x1_FFFF_FFFE + 35},
+		{name: "MaxUint32_Parity1", chainID: 0xFFFF_FFFF, v: 34, expected: 0x1_FFFF_FFFE + 36},
+		// Device uses the first 4 bytes of chain ID, 0x01000000, so (0x01000000 * 2 + 35) % 256 = 35.
+		// Using the highest 4 bytes of uint64, 0x00000001, would give 37 instead.
+		{name: "Above2Pow32_Parity0", chainID: 0x1_0000_0089, v: 35, expected: 0x2_0000_0112 + 35},
+		{name: "Above2Pow32_Parity1", chainID: 0x1_0000_0089, v: 36, expected: 0x2_0000_0112 + 36},
+		// The first 4 bytes are 0x0100006E, so (0x0100006E * 2 + 35) % 256 = 255 and V wraps around to 0
+		{name: "Above2Pow32_Boundary_Parity0", chainID: 0x1_0000_6EFF, v: 255, expected: 0x2_0000_DDFE + 35},
+		{name: "Above2Pow32_Boundary_Parity1", chainID: 0x1_0000_6EFF, v: 0, expected: 0x2_0000_DDFE + 36},
+		// 7-byte chain ID, where the first 4 bytes are 0x12345678, (0x12345678 * 2 + 35) % 256 = 19
+		{name: "SevenBytes_Parity1", chainID: 0x12_3456_789A_BCDE, v: 20, expected: 0x24_68AC_F135_79BC + 36},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.v.RecoverLegacy(test.chainID))
+		})
+	}
+}
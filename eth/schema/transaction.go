@@ -0,0 +1,264 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/rlp"
+)
+
+// Typed transaction, which can be encoded to unsigned transaction for `SignTransaction`
+type Transaction interface {
+	// Transaction type
+	Type() TxType
+	// Encode to unsigned transaction, i.e. raw tx to be signed
+	MarshalUnsigned() ([]byte, error)
+	// Decode from unsigned transaction of the same type
+	UnmarshalUnsigned(rawTx []byte) error
+}
+
+var (
+	_ Transaction = (*LegacyTx)(nil)
+	_ Transaction = (*AccessListTx)(nil)
+	_ Transaction = (*DynamicFeeTx)(nil)
+	_ Transaction = (*BlobTx)(nil)
+	_ Transaction = (*SetCodeTx)(nil)
+)
+
+// Storage slots of an address accessed by transaction, as declared in EIP-2930 access list
+type AccessTuple struct {
+	Address     Address
+	StorageKeys [][32]byte
+}
+
+type AccessList []AccessTuple
+
+// Signed authorization of EIP-7702, which sets code of the authority's account to delegate to `Address`
+type SetCodeAuthorization struct {
+	// Chain ID where the authorization is valid, or 0 for any chain
+	ChainID ChainID
+	// Address of delegated code
+	Address Address
+	// Nonce of the authority's account
+	Nonce   uint64
+	YParity uint8
+	R       *uint256.Int
+	S       *uint256.Int
+}
+
+// Legacy transaction, with EIP-155 replay protection if `ChainID` is not zero
+type LegacyTx struct {
+	Nonce    uint64
+	GasPrice *uint256.Int
+	Gas      uint64
+	// Recipient, or nil for contract creation
+	To    *Address `rlp:"nil"`
+	Value *uint256.Int
+	Data  []byte
+	// EIP-155 chain ID, or 0 for pre-EIP-155 transaction
+	ChainID ChainID `rlp:"-"`
+}
+
+func (tx *LegacyTx) Type() TxType {
+	return TX_TYPE_LEGACY
+}
+
+func (tx *LegacyTx) MarshalUnsigned() ([]byte, error) {
+	item, err := rlp.ToItem(tx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode legacy tx: %w", err)
+	}
+	if tx.ChainID != 0 {
+		item.List = append(item.List, rlp.NewUint(uint64(tx.ChainID)), rlp.NewUint(0), rlp.NewUint(0))
+	}
+
+	return rlp.Encode(item)
+}
+
+func (tx *LegacyTx) UnmarshalUnsigned(rawTx []byte) error {
+	item, n, err := rlp.DecodeStrict(rawTx)
+	if err != nil {
+		return fmt.Errorf("unable to decode legacy tx: %w: %w", ErrInvalidTx, err)
+	}
+	if n != len(rawTx) {
+		return fmt.Errorf("unexpected trailing data of legacy tx, %d bytes: %w", len(rawTx)-n, ErrInvalidTx)
+	}
+	if err := validateTxFields(TX_TYPE_LEGACY, item); err != nil {
+		return err
+	}
+
+	var chainID ChainID
+	if len(item.List) > LEGACY_TX_FIELD_COUNT {
+		if err := rlp.FromItem(item.List[LEGACY_TX_FIELD_COUNT], &chainID); err != nil {
+			return fmt.Errorf("unable to decode chain ID of legacy tx: %w: %w", ErrInvalidTx, err)
+		}
+		item.List = item.List[:LEGACY_TX_FIELD_COUNT]
+	}
+
+	var res LegacyTx
+	if err := rlp.FromItem(item, &res); err != nil {
+		return fmt.Errorf("unable to decode legacy tx: %w: %w", ErrInvalidTx, err)
+	}
+	res.ChainID = chainID
+	*tx = res
+
+	return nil
+}
+
+// EIP-2930 transaction
+type AccessListTx struct {
+	ChainID  ChainID
+	Nonce    uint64
+	GasPrice *uint256.Int
+	Gas      uint64
+	// Recipient, or nil for contract creation
+	To         *Address `rlp:"nil"`
+	Value      *uint256.Int
+	Data       []byte
+	AccessList AccessList
+}
+
+func (tx *AccessListTx) Type() TxType {
+	return TX_TYPE_ACCESS_LIST
+}
+
+func (tx *AccessListTx) MarshalUnsigned() ([]byte, error) {
+	return marshalTypedTx(TX_TYPE_ACCESS_LIST, tx)
+}
+
+func (tx *AccessListTx) UnmarshalUnsigned(rawTx []byte) error {
+	return unmarshalTypedTx(TX_TYPE_ACCESS_LIST, rawTx, tx)
+}
+
+// EIP-1559 transaction
+type DynamicFeeTx struct {
+	ChainID ChainID
+	Nonce   uint64
+	// Max priority fee per gas
+	GasTipCap *uint256.Int
+	// Max fee per gas
+	GasFeeCap *uint256.Int
+	Gas       uint64
+	// Recipient, or nil for contract creation
+	To         *Address `rlp:"nil"`
+	Value      *uint256.Int
+	Data       []byte
+	AccessList AccessList
+}
+
+func (tx *DynamicFeeTx) Type() TxType {
+	return TX_TYPE_DYNAMIC_FEE
+}
+
+func (tx *DynamicFeeTx) MarshalUnsigned() ([]byte, error) {
+	return marshalTypedTx(TX_TYPE_DYNAMIC_FEE, tx)
+}
+
+func (tx *DynamicFeeTx) UnmarshalUnsigned(rawTx []byte) error {
+	return unmarshalTypedTx(TX_TYPE_DYNAMIC_FEE, rawTx, tx)
+}
+
+// EIP-4844 transaction, which cannot create contract
+type BlobTx struct {
+	ChainID    ChainID
+	Nonce      uint64
+	GasTipCap  *uint256.Int
+	GasFeeCap  *uint256.Int
+	Gas        uint64
+	To         Address
+	Value      *uint256.Int
+	Data       []byte
+	AccessList AccessList
+	// Max fee per blob gas
+	BlobFeeCap *uint256.Int
+	// Versioned hashes of blobs
+	BlobHashes [][32]byte
+}
+
+func (tx *BlobTx) Type() TxType {
+	return TX_TYPE_BLOB
+}
+
+func (tx *BlobTx) MarshalUnsigned() ([]byte, error) {
+	return marshalTypedTx(TX_TYPE_BLOB, tx)
+}
+
+func (tx *BlobTx) UnmarshalUnsigned(rawTx []byte) error {
+	return unmarshalTypedTx(TX_TYPE_BLOB, rawTx, tx)
+}
+
+// EIP-7702 transaction, which cannot create contract
+type SetCodeTx struct {
+	ChainID    ChainID
+	Nonce      uint64
+	GasTipCap  *uint256.Int
+	GasFeeCap  *uint256.Int
+	Gas        uint64
+	To         Address
+	Value      *uint256.Int
+	Data       []byte
+	AccessList AccessList
+	AuthList   []SetCodeAuthorization
+}
+
+func (tx *SetCodeTx) Type() TxType {
+	return TX_TYPE_SET_CODE
+}
+
+func (tx *SetCodeTx) MarshalUnsigned() ([]byte, error) {
+	return marshalTypedTx(TX_TYPE_SET_CODE, tx)
+}
+
+func (tx *SetCodeTx) UnmarshalUnsigned(rawTx []byte) error {
+	return unmarshalTypedTx(TX_TYPE_SET_CODE, rawTx, tx)
+}
+
+// Encode EIP-2718 transaction, txType || rlp(tx)
+func marshalTypedTx(txType TxType, tx any) ([]byte, error) {
+	encoded, err := rlp.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode tx type %d: %w", txType, err)
+	}
+
+	return append([]byte{byte(txType)}, encoded...), nil
+}
+
+func unmarshalTypedTx(txType TxType, rawTx []byte, tx any) error {
+	if len(rawTx) == 0 || TxType(rawTx[0]) != txType {
+		return fmt.Errorf("expected tx type %d: %w", txType, ErrInvalidTx)
+	}
+	if err := rlp.Unmarshal(rawTx[1:], tx); err != nil {
+		return fmt.Errorf("unable to decode tx type %d: %w: %w", txType, ErrInvalidTx, err)
+	}
+
+	return nil
+}
+
+// Decode unsigned transaction of any known type
+func DecodeTransaction(rawTx []byte) (Transaction, error) {
+	if len(rawTx) == 0 {
+		return nil, fmt.Errorf("empty raw tx: %w", ErrInvalidTx)
+	}
+
+	var tx Transaction
+	switch TxType(rawTx[0]) {
+	case TX_TYPE_ACCESS_LIST:
+		tx = &AccessListTx{}
+	case TX_TYPE_DYNAMIC_FEE:
+		tx = &DynamicFeeTx{}
+	case TX_TYPE_BLOB:
+		tx = &BlobTx{}
+	case TX_TYPE_SET_CODE:
+		tx = &SetCodeTx{}
+	default:
+		if rawTx[0] < 0xC0 {
+			return nil, fmt.Errorf("unknown tx type 0x%02x: %w", rawTx[0], ErrInvalidTx)
+		}
+		tx = &LegacyTx{}
+	}
+	if err := tx.UnmarshalUnsigned(rawTx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,113 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_MarshalUnsigned(t *testing.T) {
+	to := schema.Address{0xd8, 0xda, 0x6b, 0xf2}
+	data := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	accessList := schema.AccessList{{Address: to, StorageKeys: [][32]byte{{0x01}}}}
+	rlpAccessList := []any{[]any{to, [][32]byte{{0x01}}}}
+
+	tests := []struct {
+		name     string
+		tx       schema.Transaction
+		expected []byte
+	}{
+		{
+			name: "Legacy_EIP155",
+			tx: &schema.LegacyTx{
+				Nonce: 7, GasPrice: uint256.NewInt(20_000_000_000), Gas: 21000, To: &to, Value: uint256.NewInt(1), Data: data, ChainID: 137,
+			},
+			expected: mustMarshalRLP(t, []any{uint64(7), uint64(20_000_000_000), uint64(21000), to, uint64(1), data, uint64(137), uint64(0), uint64(0)}),
+		},
+		{
+			name: "Legacy_PreEIP155_ContractCreation",
+			tx: &schema.LegacyTx{
+				Nonce: 7, GasPrice: uint256.NewInt(1), Gas: 21000, Value: uint256.NewInt(0), Data: data,
+			},
+			expected: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), []byte{}, uint64(0), data}),
+		},
+		{
+			name: "AccessList",
+			tx: &schema.AccessListTx{
+				ChainID: 1, Nonce: 3, GasPrice: uint256.NewInt(1_000_000_000), Gas: 50000, To: &to, Value: uint256.NewInt(0), Data: data, AccessList: accessList,
+			},
+			expected: append([]byte{0x01}, mustMarshalRLP(t, []any{uint64(1), uint64(3), uint64(1_000_000_000), uint64(50000), to, uint64(0), data, rlpAccessList})...),
+		},
+		{
+			name: "DynamicFee",
+			tx: &schema.DynamicFeeTx{
+				ChainID: 1, Nonce: 7, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: &to, Value: uint256.NewInt(1), Data: data, AccessList: schema.AccessList{},
+			},
+			expected: append([]byte{0x02}, mustMarshalRLP(t, []any{uint64(1), uint64(7), uint64(1), uint64(2), uint64(21000), to, uint64(1), data, []any{}})...),
+		},
+		{
+			name: "Blob",
+			tx: &schema.BlobTx{
+				ChainID: 1, Nonce: 7, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: to, Value: uint256.NewInt(0), Data: data,
+				AccessList: schema.AccessList{}, BlobFeeCap: uint256.NewInt(3), BlobHashes: [][32]byte{{0x01}},
+			},
+			expected: append([]byte{0x03}, mustMarshalRLP(t, []any{uint64(1), uint64(7), uint64(1), uint64(2), uint64(21000), to, uint64(0), data, []any{}, uint64(3), [][32]byte{{0x01}}})...),
+		},
+		{
+			name: "SetCode",
+			tx: &schema.SetCodeTx{
+				ChainID: 1, Nonce: 7, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: to, Value: uint256.NewInt(0), Data: []byte{},
+				AccessList: schema.AccessList{},
+				AuthList: []schema.SetCodeAuthorization{
+					{ChainID: 0, Address: to, Nonce: 8, YParity: 1, R: uint256.NewInt(4), S: uint256.NewInt(5)},
+				},
+			},
+			expected: append([]byte{0x04}, mustMarshalRLP(t, []any{uint64(1), uint64(7), uint64(1), uint64(2), uint64(21000), to, uint64(0), []byte{}, []any{},
+				[]any{[]any{uint64(0), to, uint64(8), uint64(1), uint64(4), uint64(5)}}})...),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rawTx, err := test.tx.MarshalUnsigned()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, rawTx)
+
+			decoded, err := schema.DecodeTransaction(rawTx)
+			assert.NoError(t, err)
+			assert.Equal(t, test.tx, decoded)
+			assert.Equal(t, test.tx.Type(), decoded.Type())
+
+			txInfo, err := schema.DecodeTxInfo(rawTx)
+			assert.NoError(t, err)
+			assert.Equal(t, test.tx.Type(), txInfo.TxType)
+		})
+	}
+}
+
+func TestDecodeTransaction_Error(t *testing.T) {
+	to := schema.Address{0xd8, 0xda, 0x6b, 0xf2}
+
+	tests := []struct {
+		name  string
+		rawTx []byte
+	}{
+		{name: "Empty", rawTx: nil},
+		{name: "UnknownType", rawTx: []byte{0x05, 0xc0}},
+		{name: "TooFewFields", rawTx: append([]byte{0x02}, mustMarshalRLP(t, []any{uint64(1), uint64(7)})...)},
+		{name: "TrailingData", rawTx: append(mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), []byte{}}), 0x00)},
+		{name: "Legacy_NonZeroSignature", rawTx: mustMarshalRLP(t, []any{uint64(7), uint64(1), uint64(21000), to, uint64(1), []byte{}, uint64(1), uint64(1), uint64(0)})},
+		{name: "Blob_ContractCreation", rawTx: append([]byte{0x03}, mustMarshalRLP(t, []any{uint64(1), uint64(7), uint64(1), uint64(2), uint64(21000), []byte{}, uint64(0), []byte{}, []any{}, uint64(3), []any{}})...)},
+		{name: "NonCanonicalInteger", rawTx: append([]byte{0x02}, mustMarshalRLP(t, []any{uint64(1), []byte{0x00, 0x07}, uint64(1), uint64(2), uint64(21000), to, uint64(1), []byte{}, []any{}})...)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := schema.DecodeTransaction(test.rawTx)
+
+			assert.ErrorIs(t, err, schema.ErrInvalidTx)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ntchjb/ledger-go/eth/rlp"
+)
+
+var (
+	ErrSignerMismatch = errors.New("signer does not match expected address")
+)
+
+// Recover address of the signer of 32-byte hash, where `yParity` is 0 or 1
+func RecoverSigner(hash []byte, sig SignDataResponse, yParity byte) (Address, error) {
+	if len(hash) != 32 {
+		return Address{}, fmt.Errorf("expected 32-byte hash, got %d bytes: %w", len(hash), ErrInvalidSignature)
+	}
+	if yParity > 1 {
+		return Address{}, fmt.Errorf("expected Y parity of 0 or 1, got %d: %w", yParity, ErrInvalidSignature)
+	}
+	// Compact signature is [27 + recovery ID, R, S]
+	compact := make([]byte, 0, 65)
+	compact = append(compact, 27+yParity)
+	compact = append(compact, sig.R[:]...)
+	compact = append(compact, sig.S[:]...)
+	publicKey, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return Address{}, fmt.Errorf("unable to recover public key: %w: %w", ErrInvalidSignature, err)
+	}
+	pub := PublicKey(publicKey.SerializeUncompressed())
+
+	return pub.Address(), nil
+}
+
+// Recover signer of unsigned raw tx from signature returned by `SignTransaction`,
+// where V is validated the same way as `AssembleSignedTx`
+func RecoverTxSigner(rawTx []byte, sig SignDataResponse) (Address, error) {
+	txInfo, err := DecodeTxInfo(rawTx)
+	if err != nil {
+		return Address{}, fmt.Errorf("unable to decode raw tx info: %w", err)
+	}
+
+	var yParity uint64
+	if txInfo.TxType == TX_TYPE_LEGACY {
+		// Already validated by `DecodeTxInfo`
+		item, _, err := rlp.DecodeStrict(rawTx)
+		if err != nil {
+			return Address{}, fmt.Errorf("unable to decode raw tx: %w: %w", ErrInvalidTx, err)
+		}
+		v, err := legacySignatureV(item, sig.V)
+		if err != nil {
+			return Address{}, err
+		}
+		// V is either 27 + y_parity, or chain_id * 2 + 35 + y_parity
+		yParity = (v - 27) % 2
+	} else if yParity, err = signatureYParity(sig.V); err != nil {
+		return Address{}, err
+	}
+
+	return RecoverSigner(Keccak256(rawTx), sig, byte(yParity))
+}
+
+// Recover signer of personal message from signature returned by `SignPersonalMessage`
+func RecoverPersonalMessageSigner(message []byte, sig SignDataResponse) (Address, error) {
+	if sig.V != 27 && sig.V != 28 {
+		return Address{}, fmt.Errorf("expected V of 27 or 28, got %d: %w", sig.V, ErrInvalidSignature)
+	}
+	hash := Keccak256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(message))), message)
+
+	return RecoverSigner(hash, sig, byte(sig.V-27))
+}
+
+// Recover signer of EIP-712 message from signature returned by `SignEIP712Message` or `SignEIP712MessageHash`
+func RecoverEIP712Signer(domainSeparatorHash []byte, messageHash []byte, sig SignDataResponse) (Address, error) {
+	if sig.V != 27 && sig.V != 28 {
+		return Address{}, fmt.Errorf("expected V of 27 or 28, got %d: %w", sig.V, ErrInvalidSignature)
+	}
+	if len(domainSeparatorHash) != 32 || len(messageHash) != 32 {
+		return Address{}, fmt.Errorf("expected 32-byte hashes, got %d and %d bytes: %w", len(domainSeparatorHash), len(messageHash), ErrInvalidSignature)
+	}
+	hash := Keccak256([]byte{0x19, 0x01}, domainSeparatorHash, messageHash)
+
+	return RecoverSigner(hash, sig, byte(sig.V-27))
+}
+
+// Check that recovered signer is the expected address, i.e. `GetAddressResponse.Address` of the signing path
+func VerifySigner(signer Address, expected Address) error {
+	if signer != expected {
+		return fmt.Errorf("recovered %s, expected %s: %w", signer.String(), expected.String(), ErrSignerMismatch)
+	}
+
+	return nil
+}
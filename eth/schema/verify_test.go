@@ -0,0 +1,119 @@
+package schema_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPrivateKey = mustDecodeHex("4646464646464646464646464646464646464646464646464646464646464646")
+
+func testSign(t *testing.T, hash []byte, v func(parity byte) schema.SignatureV) schema.SignDataResponse {
+	// [27 + recovery ID, R, S], where bit 0 of recovery ID is Y parity
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(testPrivateKey), hash, false)
+
+	return schema.SignDataResponse{V: v((compact[0] - 27) & 0x01), R: [32]byte(compact[1:33]), S: [32]byte(compact[33:65])}
+}
+
+func testSigner(t *testing.T) schema.Address {
+	pub := schema.PublicKey(secp256k1.PrivKeyFromBytes(testPrivateKey).PubKey().SerializeUncompressed())
+
+	return pub.Address()
+}
+
+func TestRecoverTxSigner(t *testing.T) {
+	to := schema.Address{0xd8, 0xda, 0x6b, 0xf2}
+	eip155 := mustDecodeHex("ec098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080018080")
+	preEIP155, err := (&schema.LegacyTx{Nonce: 9, GasPrice: uint256.NewInt(1), Gas: 21000, To: &to}).MarshalUnsigned()
+	assert.NoError(t, err)
+	dynamicFee, err := (&schema.DynamicFeeTx{ChainID: 1, Nonce: 7, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: &to}).MarshalUnsigned()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		rawTx []byte
+		v     func(parity byte) schema.SignatureV
+		err   error
+	}{
+		{
+			name:  "Success_Legacy_EIP155",
+			rawTx: eip155,
+			v:     func(parity byte) schema.SignatureV { return schema.SignatureV(37 + parity) },
+		},
+		{
+			name:  "Success_Legacy_PreEIP155",
+			rawTx: preEIP155,
+			v:     func(parity byte) schema.SignatureV { return schema.SignatureV(27 + parity) },
+		},
+		{
+			name:  "Success_DynamicFee",
+			rawTx: dynamicFee,
+			v:     func(parity byte) schema.SignatureV { return schema.SignatureV(parity) },
+		},
+		{
+			name:  "Error_Legacy_UnrecoveredV",
+			rawTx: eip155,
+			v:     func(parity byte) schema.SignatureV { return schema.SignatureV(27 + parity) },
+			err:   schema.ErrInvalidSignature,
+		},
+		{
+			name:  "Error_InvalidTx",
+			rawTx: []byte{0x02, 0xc0},
+			v:     func(parity byte) schema.SignatureV { return schema.SignatureV(parity) },
+			err:   schema.ErrInvalidTx,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sig := testSign(t, schema.Keccak256(test.rawTx), test.v)
+
+			signer, err := schema.RecoverTxSigner(test.rawTx, sig)
+
+			assert.ErrorIs(t, err, test.err)
+			if test.err == nil {
+				assert.NoError(t, schema.VerifySigner(signer, testSigner(t)))
+			}
+		})
+	}
+}
+
+func TestRecoverPersonalMessageSigner(t *testing.T) {
+	message := []byte("Hello, Ledger!")
+	hash := schema.Keccak256(append([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(message))), message...))
+	sig := testSign(t, hash, func(parity byte) schema.SignatureV { return schema.SignatureV(27 + parity) })
+
+	signer, err := schema.RecoverPersonalMessageSigner(message, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, testSigner(t), signer)
+
+	// Wrong Y parity recovers another signer
+	sig.V = 27 + 28 - sig.V
+	signer, err = schema.RecoverPersonalMessageSigner(message, sig)
+	if err == nil {
+		assert.ErrorIs(t, schema.VerifySigner(signer, testSigner(t)), schema.ErrSignerMismatch)
+	}
+
+	sig.V = 1
+	_, err = schema.RecoverPersonalMessageSigner(message, sig)
+	assert.ErrorIs(t, err, schema.ErrInvalidSignature)
+}
+
+func TestRecoverEIP712Signer(t *testing.T) {
+	domainHash := mustDecodeHex("f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f")
+	messageHash := mustDecodeHex("c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e")
+	hash := schema.Keccak256(append(append([]byte{0x19, 0x01}, domainHash...), messageHash...))
+	sig := testSign(t, hash, func(parity byte) schema.SignatureV { return schema.SignatureV(27 + parity) })
+
+	signer, err := schema.RecoverEIP712Signer(domainHash, messageHash, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, testSigner(t), signer)
+
+	_, err = schema.RecoverEIP712Signer(domainHash[:31], messageHash, sig)
+	assert.ErrorIs(t, err, schema.ErrInvalidSignature)
+}
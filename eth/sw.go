@@ -0,0 +1,186 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ntchjb/ledger-go/adpu"
+)
+
+// Status words returned by Ledger Ethereum app
+const (
+	SW_MODE_CHECK_FAILED         uint16 = 0x6001
+	SW_TX_TYPE_NOT_SUPPORTED     uint16 = 0x6501
+	SW_CHAIN_ID_BUFFER_TOO_SMALL uint16 = 0x6502
+	SW_SECURITY_NOT_SATISFIED    uint16 = 0x6982
+	SW_BLIND_SIGNING_DISABLED    uint16 = 0x6983
+	SW_PLUGIN_NOT_INSTALLED      uint16 = 0x6984
+	SW_CONDITION_NOT_SATISFIED   uint16 = 0x6985
+	SW_INVALID_DATA              uint16 = 0x6a80
+	SW_INSUFFICIENT_MEMORY       uint16 = 0x6a84
+	SW_WRONG_DATA_LENGTH         uint16 = 0x6a87
+	SW_REFERENCED_DATA_NOT_FOUND uint16 = 0x6a88
+	SW_INVALID_P1_P2             uint16 = 0x6b00
+	SW_INVALID_INS               uint16 = 0x6d00
+	SW_CLA_NOT_SUPPORTED         uint16 = 0x6e00
+	SW_INTERNAL_ERROR            uint16 = 0x6f00
+)
+
+var (
+	ErrModeCheckFailed       = errors.New("ethereum app mode check failed")
+	ErrTxTypeNotSupported    = errors.New("transaction type is not supported by ethereum app")
+	ErrChainIDBufferTooSmall = errors.New("chain ID is too large for ethereum app")
+	ErrBlindSigningDisabled  = errors.New("blind signing is disabled")
+	ErrPluginNotInstalled    = errors.New("plugin is not installed")
+	ErrInvalidData           = errors.New("ethereum app rejected invalid data")
+	ErrInsufficientMemory    = errors.New("ethereum app has insufficient memory")
+	ErrWrongDataLength       = errors.New("ethereum app rejected data length")
+	ErrReferencedDataMissing = errors.New("referenced data is not found by ethereum app")
+	ErrInternalError         = errors.New("ethereum app internal error")
+)
+
+// Meaning of a status word in context of Ledger Ethereum app
+type StatusWordInfo struct {
+	Name string
+	// What the app reports
+	Description string
+	// What user can do to resolve it
+	Action string
+	// Sentinel error matched by errors.Is, if any
+	Err error
+}
+
+// Status words of Ledger Ethereum app
+// https://github.com/LedgerHQ/app-ethereum/blob/develop/src/apdu_constants.h
+var SWInfo = map[uint16]StatusWordInfo{
+	SW_MODE_CHECK_FAILED: {
+		Name:        "SW_MODE_CHECK_FAILED",
+		Description: "app is not in the expected mode",
+		Action:      "restart the Ethereum app and retry",
+		Err:         ErrModeCheckFailed,
+	},
+	SW_TX_TYPE_NOT_SUPPORTED: {
+		Name:        "SW_TX_TYPE_NOT_SUPPORTED",
+		Description: "transaction type is not supported",
+		Action:      "update the Ethereum app, or use a supported transaction type",
+		Err:         ErrTxTypeNotSupported,
+	},
+	SW_CHAIN_ID_BUFFER_TOO_SMALL: {
+		Name:        "SW_CHAIN_ID_BUFFER_TOO_SMALL",
+		Description: "chain ID is too large to be displayed",
+		Action:      "update the Ethereum app",
+		Err:         ErrChainIDBufferTooSmall,
+	},
+	SW_SECURITY_NOT_SATISFIED: {
+		Name:        "SW_SECURITY_NOT_SATISFIED",
+		Description: "security status is not satisfied",
+		Action:      "unlock the device and retry",
+		Err:         adpu.ErrLockedDevice,
+	},
+	SW_BLIND_SIGNING_DISABLED: {
+		Name:        "SW_BLIND_SIGNING_DISABLED",
+		Description: "blind signing is disabled",
+		Action:      "enable blind signing in the settings of the Ethereum app",
+		Err:         ErrBlindSigningDisabled,
+	},
+	SW_PLUGIN_NOT_INSTALLED: {
+		Name:        "SW_PLUGIN_NOT_INSTALLED",
+		Description: "plugin required by the contract is not installed",
+		Action:      "install the plugin app on the device with Ledger Live, or enable blind signing",
+		Err:         ErrPluginNotInstalled,
+	},
+	SW_CONDITION_NOT_SATISFIED: {
+		Name:        "SW_CONDITION_NOT_SATISFIED",
+		Description: "request is rejected on device, or command is sent out of order",
+		Action:      "approve the request on the device",
+		Err:         adpu.ErrUserRefused,
+	},
+	SW_INVALID_DATA: {
+		Name:        "SW_INVALID_DATA",
+		Description: "data is invalid",
+		Action:      "check the encoding of transaction or message",
+		Err:         ErrInvalidData,
+	},
+	SW_INSUFFICIENT_MEMORY: {
+		Name:        "SW_INSUFFICIENT_MEMORY",
+		Description: "insufficient memory",
+		Action:      "reduce size of transaction or message, or restart the Ethereum app",
+		Err:         ErrInsufficientMemory,
+	},
+	SW_WRONG_DATA_LENGTH: {
+		Name:        "SW_WRONG_DATA_LENGTH",
+		Description: "data length is wrong",
+		Action:      "check the encoding of transaction or message",
+		Err:         ErrWrongDataLength,
+	},
+	SW_REFERENCED_DATA_NOT_FOUND: {
+		Name:        "SW_REFERENCED_DATA_NOT_FOUND",
+		Description: "referenced data is not found",
+		Action:      "provide token or plugin information before signing",
+		Err:         ErrReferencedDataMissing,
+	},
+	SW_INVALID_P1_P2: {
+		Name:        "SW_INVALID_P1_P2",
+		Description: "command parameters are invalid",
+		Action:      "update the Ethereum app",
+	},
+	SW_INVALID_INS: {
+		Name:        "SW_INVALID_INS",
+		Description: "command is not supported",
+		Action:      "open the Ethereum app, or update it",
+		Err:         adpu.ErrAppNotOpen,
+	},
+	SW_CLA_NOT_SUPPORTED: {
+		Name:        "SW_CLA_NOT_SUPPORTED",
+		Description: "Ethereum app is not open",
+		Action:      "open the Ethereum app on the device",
+		Err:         adpu.ErrAppNotOpen,
+	},
+	SW_INTERNAL_ERROR: {
+		Name:        "SW_INTERNAL_ERROR",
+		Description: "internal error",
+		Action:      "restart the Ethereum app and retry",
+		Err:         ErrInternalError,
+	},
+}
+
+// AppError is a status word error translated in context of Ledger Ethereum app
+//
+// It unwraps to `*adpu.StatusError`, so generic sentinel errors i.e. `adpu.ErrUserRefused` are still matched
+type AppError struct {
+	StatusWordInfo
+	StatusError *adpu.StatusError
+}
+
+func (e *AppError) Error() string {
+	return fmt.Sprintf("ethereum app: %s, %s (SW 0x%04x %s, command: %s)", e.Description, e.Action, e.StatusError.SW, e.Name, e.StatusError.Command)
+}
+
+func (e *AppError) Is(target error) bool {
+	return e.Err != nil && target == e.Err
+}
+
+func (e *AppError) Unwrap() error {
+	return e.StatusError
+}
+
+// Translate status word error returned by device to `*AppError`. Other errors are returned as is
+func translateError(err error) error {
+	statusErr, ok := err.(*adpu.StatusError)
+	if !ok {
+		return err
+	}
+	info, ok := SWInfo[statusErr.SW]
+	if !ok {
+		info = StatusWordInfo{
+			Name:        statusErr.Message(),
+			Description: "unexpected status word",
+			Action:      "check that the Ethereum app is open and up to date",
+		}
+	}
+
+	return &AppError{
+		StatusWordInfo: info,
+		StatusError:    statusErr,
+	}
+}
@@ -0,0 +1,113 @@
+package eth_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEthereumApp_StatusWordError(t *testing.T) {
+	tests := []struct {
+		name    string
+		sw      uint16
+		message string
+		is      []error
+		isNot   []error
+	}{
+		{
+			name:    "BlindSigningDisabled",
+			sw:      eth.SW_BLIND_SIGNING_DISABLED,
+			message: "unable to send set plugin command to device: ethereum app: blind signing is disabled, enable blind signing in the settings of the Ethereum app (SW 0x6983 SW_BLIND_SIGNING_DISABLED, command: CLA: 0xe0, INS: 0x16, P1: 0x00, P2: 0x00)",
+			is:      []error{eth.ErrBlindSigningDisabled, adpu.ErrSWNotOK},
+			isNot:   []error{eth.ErrPluginNotInstalled, adpu.ErrUserRefused},
+		},
+		{
+			name:    "PluginNotInstalled",
+			sw:      eth.SW_PLUGIN_NOT_INSTALLED,
+			message: "unable to send set plugin command to device: ethereum app: plugin required by the contract is not installed, install the plugin app on the device with Ledger Live, or enable blind signing (SW 0x6984 SW_PLUGIN_NOT_INSTALLED, command: CLA: 0xe0, INS: 0x16, P1: 0x00, P2: 0x00)",
+			is:      []error{eth.ErrPluginNotInstalled, adpu.ErrSWNotOK},
+			isNot:   []error{eth.ErrBlindSigningDisabled},
+		},
+		{
+			name:  "InsufficientMemory",
+			sw:    eth.SW_INSUFFICIENT_MEMORY,
+			is:    []error{eth.ErrInsufficientMemory, adpu.ErrSWNotOK},
+			isNot: []error{eth.ErrInvalidData},
+		},
+		{
+			name:  "InvalidData",
+			sw:    eth.SW_INVALID_DATA,
+			is:    []error{eth.ErrInvalidData, adpu.ErrSWNotOK},
+			isNot: []error{eth.ErrInsufficientMemory},
+		},
+		{
+			name:  "UserRefused",
+			sw:    eth.SW_CONDITION_NOT_SATISFIED,
+			is:    []error{adpu.ErrUserRefused, adpu.ErrSWNotOK},
+			isNot: []error{eth.ErrInvalidData},
+		},
+		{
+			name:  "AppNotOpen",
+			sw:    eth.SW_CLA_NOT_SUPPORTED,
+			is:    []error{adpu.ErrAppNotOpen, adpu.ErrSWNotOK},
+			isNot: []error{eth.ErrInvalidData},
+		},
+		{
+			name:    "UnknownStatusWord",
+			sw:      0x1234,
+			message: "unable to send set plugin command to device: ethereum app: unexpected status word, check that the Ethereum app is open and up to date (SW 0x1234 SW_UNKNOWN, command: CLA: 0xe0, INS: 0x16, P1: 0x00, P2: 0x00)",
+			is:      []error{adpu.ErrSWNotOK},
+			isNot:   []error{eth.ErrInvalidData, adpu.ErrUserRefused},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			ctx := context.Background()
+			proto := adpu.NewMockProtocol(ctrl)
+			proto.EXPECT().Send(ctx, eth.ADPU_CLA, eth.ADPU_INS_SET_PLUGIN, uint8(0x00), uint8(0x00), gomock.Any()).Return(nil, test.sw, nil)
+			app := eth.NewEthereumApp(proto, slog.Default())
+
+			err := app.SetPlugin(ctx, []byte{0x01})
+
+			if test.message != "" {
+				assert.EqualError(t, err, test.message)
+			}
+			for _, target := range test.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, target := range test.isNot {
+				assert.NotErrorIs(t, err, target)
+			}
+
+			var appErr *eth.AppError
+			assert.True(t, errors.As(err, &appErr))
+			assert.Equal(t, test.sw, appErr.StatusError.SW)
+			var statusErr *adpu.StatusError
+			assert.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, eth.ADPU_INS_SET_PLUGIN, statusErr.Command.INS)
+		})
+	}
+}
+
+func TestEthereumApp_SignTransaction_StatusWordError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	proto := adpu.NewMockProtocol(ctrl)
+	proto.EXPECT().Send(ctx, eth.ADPU_CLA, eth.ADPU_INS_SIGN_TRANSACTION, eth.P1_FIRST_CHUNK, uint8(0x00), gomock.Any()).Return(nil, eth.SW_BLIND_SIGNING_DISABLED, nil)
+	app := eth.NewEthereumApp(proto, slog.Default())
+
+	_, err := app.SignTransaction(ctx, testBIP32Path, legacyTx([]byte{0xAA, 0xBB}, 1))
+
+	var appErr *eth.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.ErrorIs(t, err, eth.ErrBlindSigningDisabled)
+	assert.Equal(t, eth.P1_FIRST_CHUNK, appErr.StatusError.Command.P1)
+}
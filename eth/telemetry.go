@@ -0,0 +1,168 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+)
+
+// Trace every operation of the app with given tracer. Spans of ADPU exchanges, created by protocol
+// with `adpu.WithTracer`, become children of the operation span.
+func WithTracer(tracer adpu.Tracer) EthereumAppOption {
+	return func(e *ethereumAppImpl) {
+		e.tracer = tracer
+	}
+}
+
+// Attach chunk index to span of the ADPU exchange, only if tracing is enabled
+func (e *ethereumAppImpl) chunkContext(ctx context.Context, chunk int) context.Context {
+	if e.tracer == nil {
+		return ctx
+	}
+
+	return adpu.ContextWithChunk(ctx, chunk)
+}
+
+// EthereumApp that starts a span per operation
+type tracedEthereumApp struct {
+	app    EthereumApp
+	tracer adpu.Tracer
+}
+
+func traced[T any](ctx context.Context, tracer adpu.Tracer, name string, operation func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := tracer.Start(ctx, name)
+	res, err := operation(ctx)
+	span.End(err)
+
+	return res, err
+}
+
+func tracedNoResult(ctx context.Context, tracer adpu.Tracer, name string, operation func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, name)
+	err := operation(ctx)
+	span.End(err)
+
+	return err
+}
+
+func (t *tracedEthereumApp) GetConfiguration(ctx context.Context) (schema.GetConfigurationResponse, error) {
+	return traced(ctx, t.tracer, "eth.GetConfiguration", func(ctx context.Context) (schema.GetConfigurationResponse, error) {
+		return t.app.GetConfiguration(ctx)
+	})
+}
+
+func (t *tracedEthereumApp) GetAddress(ctx context.Context, bip32Path string, needHWConfirm bool, chaincode bool, chainID uint64) (schema.GetAddressResponse, error) {
+	return traced(ctx, t.tracer, "eth.GetAddress", func(ctx context.Context) (schema.GetAddressResponse, error) {
+		return t.app.GetAddress(ctx, bip32Path, needHWConfirm, chaincode, chainID)
+	})
+}
+
+func (t *tracedEthereumApp) SignTransaction(ctx context.Context, bip32Path string, rawTx []byte) (schema.SignDataResponse, error) {
+	return traced(ctx, t.tracer, "eth.SignTransaction", func(ctx context.Context) (schema.SignDataResponse, error) {
+		return t.app.SignTransaction(ctx, bip32Path, rawTx)
+	})
+}
+
+func (t *tracedEthereumApp) SignTypedTransaction(ctx context.Context, bip32Path string, tx schema.Transaction) (schema.SignDataResponse, error) {
+	return traced(ctx, t.tracer, "eth.SignTypedTransaction", func(ctx context.Context) (schema.SignDataResponse, error) {
+		return t.app.SignTypedTransaction(ctx, bip32Path, tx)
+	})
+}
+
+func (t *tracedEthereumApp) SignPersonalMessage(ctx context.Context, bip32Path string, message []byte) (schema.SignDataResponse, error) {
+	return traced(ctx, t.tracer, "eth.SignPersonalMessage", func(ctx context.Context) (schema.SignDataResponse, error) {
+		return t.app.SignPersonalMessage(ctx, bip32Path, message)
+	})
+}
+
+func (t *tracedEthereumApp) SignEIP712Message(ctx context.Context, bip32Path string, message eip712.Message) (schema.SignDataResponse, error) {
+	return traced(ctx, t.tracer, "eth.SignEIP712Message", func(ctx context.Context) (schema.SignDataResponse, error) {
+		return t.app.SignEIP712Message(ctx, bip32Path, message)
+	})
+}
+
+func (t *tracedEthereumApp) EIP712SendStructDefinition(ctx context.Context, component eip712.Component, value []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.EIP712SendStructDefinition", func(ctx context.Context) error {
+		return t.app.EIP712SendStructDefinition(ctx, component, value)
+	})
+}
+
+func (t *tracedEthereumApp) EIP712SendStructData(ctx context.Context, component eip712.Component, value []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.EIP712SendStructData", func(ctx context.Context) error {
+		return t.app.EIP712SendStructData(ctx, component, value)
+	})
+}
+
+func (t *tracedEthereumApp) EIP712SendClearSigningData(ctx context.Context, action eip712.Action, value []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.EIP712SendClearSigningData", func(ctx context.Context) error {
+		return t.app.EIP712SendClearSigningData(ctx, action, value)
+	})
+}
+
+func (t *tracedEthereumApp) SignEIP712MessageHash(ctx context.Context, bip32Path string, domainSeparatorHash []byte, messageHash []byte) (schema.SignDataResponse, error) {
+	return traced(ctx, t.tracer, "eth.SignEIP712MessageHash", func(ctx context.Context) (schema.SignDataResponse, error) {
+		return t.app.SignEIP712MessageHash(ctx, bip32Path, domainSeparatorHash, messageHash)
+	})
+}
+
+func (t *tracedEthereumApp) ETH2GetPublicKey(ctx context.Context, bip32Path string, needHWConfirm bool) (schema.ETH2PublicKey, error) {
+	return traced(ctx, t.tracer, "eth.ETH2GetPublicKey", func(ctx context.Context) (schema.ETH2PublicKey, error) {
+		return t.app.ETH2GetPublicKey(ctx, bip32Path, needHWConfirm)
+	})
+}
+
+func (t *tracedEthereumApp) ETH2SetWithdrawalIndex(ctx context.Context, index uint32) error {
+	return tracedNoResult(ctx, t.tracer, "eth.ETH2SetWithdrawalIndex", func(ctx context.Context) error {
+		return t.app.ETH2SetWithdrawalIndex(ctx, index)
+	})
+}
+
+func (t *tracedEthereumApp) GetPrivacyPublicKey(ctx context.Context, bip32Path string, needHWConfirm bool) (schema.GetPrivacyPublicKeyResponse, error) {
+	return traced(ctx, t.tracer, "eth.GetPrivacyPublicKey", func(ctx context.Context) (schema.GetPrivacyPublicKeyResponse, error) {
+		return t.app.GetPrivacyPublicKey(ctx, bip32Path, needHWConfirm)
+	})
+}
+
+func (t *tracedEthereumApp) GetPrivacySharedSecret(ctx context.Context, bip32Path string, remotePublicKey []byte, needHWConfirm bool) (schema.GetPrivacySharedSecretResponse, error) {
+	return traced(ctx, t.tracer, "eth.GetPrivacySharedSecret", func(ctx context.Context) (schema.GetPrivacySharedSecretResponse, error) {
+		return t.app.GetPrivacySharedSecret(ctx, bip32Path, remotePublicKey, needHWConfirm)
+	})
+}
+
+func (t *tracedEthereumApp) GetChallenge(ctx context.Context) (schema.Challenge, error) {
+	return traced(ctx, t.tracer, "eth.GetChallenge", func(ctx context.Context) (schema.Challenge, error) {
+		return t.app.GetChallenge(ctx)
+	})
+}
+
+func (t *tracedEthereumApp) ProvideDomainNameInformation(ctx context.Context, info []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.ProvideDomainNameInformation", func(ctx context.Context) error {
+		return t.app.ProvideDomainNameInformation(ctx, info)
+	})
+}
+
+func (t *tracedEthereumApp) ProvideNFTInformation(ctx context.Context, info []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.ProvideNFTInformation", func(ctx context.Context) error {
+		return t.app.ProvideNFTInformation(ctx, info)
+	})
+}
+
+func (t *tracedEthereumApp) ProvideERC20Information(ctx context.Context, info []byte) (schema.ProvideERC20InfoResponse, error) {
+	return traced(ctx, t.tracer, "eth.ProvideERC20Information", func(ctx context.Context) (schema.ProvideERC20InfoResponse, error) {
+		return t.app.ProvideERC20Information(ctx, info)
+	})
+}
+
+func (t *tracedEthereumApp) SetPlugin(ctx context.Context, info []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.SetPlugin", func(ctx context.Context) error {
+		return t.app.SetPlugin(ctx, info)
+	})
+}
+
+func (t *tracedEthereumApp) SetExternalPlugin(ctx context.Context, payload []byte, signature []byte) error {
+	return tracedNoResult(ctx, t.tracer, "eth.SetExternalPlugin", func(ctx context.Context) error {
+		return t.app.SetExternalPlugin(ctx, payload, signature)
+	})
+}
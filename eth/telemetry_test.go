@@ -0,0 +1,61 @@
+package eth_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	name string
+	err  error
+}
+
+func (s *fakeSpan) IsRecording() bool                { return true }
+func (s *fakeSpan) SetAttributes(attrs ...slog.Attr) {}
+func (s *fakeSpan) End(err error)                    { s.err = err }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, adpu.Span) {
+	span := &fakeSpan{name: name}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestEthereumApp_WithTracer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	proto := adpu.NewMockProtocol(ctrl)
+	tracer := &fakeTracer{}
+	var chunks []int
+	proto.EXPECT().Send(gomock.Any(), eth.ADPU_CLA, eth.ADPU_INS_SIGN_PERSONAL_MESSAGE, gomock.Any(), uint8(0x00), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
+			// ADPU exchanges are children of the operation span
+			assert.Equal(t, tracer.spans[0], ctx.Value(spanKey{}))
+			chunk, ok := adpu.ChunkFromContext(ctx)
+			assert.True(t, ok)
+			chunks = append(chunks, chunk)
+			if len(chunks) < 2 {
+				return nil, adpu.SW_OK, nil
+			}
+			return nil, adpu.SW_CONDITIONS_OF_USE_NOT_SATISFIED, nil
+		}).Times(2)
+	app := eth.NewEthereumApp(proto, slog.Default(), eth.WithTracer(tracer))
+
+	_, err := app.SignPersonalMessage(context.Background(), testBIP32Path, make([]byte, 300))
+
+	assert.ErrorIs(t, err, adpu.ErrUserRefused)
+	assert.Equal(t, []int{0, 1}, chunks)
+	assert.Len(t, tracer.spans, 1)
+	assert.Equal(t, "eth.SignPersonalMessage", tracer.spans[0].name)
+	assert.ErrorIs(t, tracer.spans[0].err, adpu.ErrUserRefused)
+}
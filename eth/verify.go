@@ -0,0 +1,181 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/ntchjb/ledger-go/eth/schema/eip712"
+)
+
+// Verify every signature returned by device before returning it, by recovering its signer
+// and comparing it with address of the signing path, which is got from device once per path.
+// Mismatched signatures return error wrapping `schema.ErrSignerMismatch`.
+func WithSignatureVerification() EthereumAppOption {
+	return func(e *ethereumAppImpl) {
+		e.verifySignatures = true
+	}
+}
+
+// EthereumApp that verifies signatures returned by device
+type verifyingEthereumApp struct {
+	app EthereumApp
+
+	mu sync.Mutex
+	// Addresses by BIP-32 path
+	addresses map[string]schema.Address
+}
+
+func (v *verifyingEthereumApp) address(ctx context.Context, bip32Path string) (schema.Address, error) {
+	v.mu.Lock()
+	address, ok := v.addresses[bip32Path]
+	v.mu.Unlock()
+	if ok {
+		return address, nil
+	}
+
+	res, err := v.app.GetAddress(ctx, bip32Path, false, false, 0)
+	if err != nil {
+		return schema.Address{}, fmt.Errorf("unable to get address of signing path: %w", err)
+	}
+	v.mu.Lock()
+	v.addresses[bip32Path] = res.Address
+	v.mu.Unlock()
+
+	return res.Address, nil
+}
+
+// Check that signer of the signature, recovered by `recoverSigner`, is address of the signing path
+func (v *verifyingEthereumApp) verify(ctx context.Context, bip32Path string, sig schema.SignDataResponse, recoverSigner func(sig schema.SignDataResponse) (schema.Address, error)) (schema.SignDataResponse, error) {
+	signer, err := recoverSigner(sig)
+	if err != nil {
+		return schema.SignDataResponse{}, fmt.Errorf("unable to recover signer: %w", err)
+	}
+	address, err := v.address(ctx, bip32Path)
+	if err != nil {
+		return schema.SignDataResponse{}, err
+	}
+	if err := schema.VerifySigner(signer, address); err != nil {
+		return schema.SignDataResponse{}, fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	return sig, nil
+}
+
+func (v *verifyingEthereumApp) GetConfiguration(ctx context.Context) (schema.GetConfigurationResponse, error) {
+	return v.app.GetConfiguration(ctx)
+}
+
+func (v *verifyingEthereumApp) GetAddress(ctx context.Context, bip32Path string, needHWConfirm bool, chaincode bool, chainID uint64) (schema.GetAddressResponse, error) {
+	return v.app.GetAddress(ctx, bip32Path, needHWConfirm, chaincode, chainID)
+}
+
+func (v *verifyingEthereumApp) SignTransaction(ctx context.Context, bip32Path string, rawTx []byte) (schema.SignDataResponse, error) {
+	sig, err := v.app.SignTransaction(ctx, bip32Path, rawTx)
+	if err != nil {
+		return sig, err
+	}
+
+	return v.verify(ctx, bip32Path, sig, func(sig schema.SignDataResponse) (schema.Address, error) {
+		return schema.RecoverTxSigner(rawTx, sig)
+	})
+}
+
+func (v *verifyingEthereumApp) SignTypedTransaction(ctx context.Context, bip32Path string, tx schema.Transaction) (schema.SignDataResponse, error) {
+	rawTx, err := tx.MarshalUnsigned()
+	if err != nil {
+		return schema.SignDataResponse{}, fmt.Errorf("unable to encode typed tx: %w", err)
+	}
+
+	return v.SignTransaction(ctx, bip32Path, rawTx)
+}
+
+func (v *verifyingEthereumApp) SignPersonalMessage(ctx context.Context, bip32Path string, message []byte) (schema.SignDataResponse, error) {
+	sig, err := v.app.SignPersonalMessage(ctx, bip32Path, message)
+	if err != nil {
+		return sig, err
+	}
+
+	return v.verify(ctx, bip32Path, sig, func(sig schema.SignDataResponse) (schema.Address, error) {
+		return schema.RecoverPersonalMessageSigner(message, sig)
+	})
+}
+
+func (v *verifyingEthereumApp) SignEIP712Message(ctx context.Context, bip32Path string, message eip712.Message) (schema.SignDataResponse, error) {
+	sig, err := v.app.SignEIP712Message(ctx, bip32Path, message)
+	if err != nil {
+		return sig, err
+	}
+
+	return v.verify(ctx, bip32Path, sig, func(sig schema.SignDataResponse) (schema.Address, error) {
+		domainSeparator, messageHash, err := message.Hash()
+		if err != nil {
+			return schema.Address{}, fmt.Errorf("unable to hash EIP712 message: %w", err)
+		}
+		return schema.RecoverEIP712Signer(domainSeparator[:], messageHash[:], sig)
+	})
+}
+
+func (v *verifyingEthereumApp) SignEIP712MessageHash(ctx context.Context, bip32Path string, domainSeparatorHash []byte, messageHash []byte) (schema.SignDataResponse, error) {
+	sig, err := v.app.SignEIP712MessageHash(ctx, bip32Path, domainSeparatorHash, messageHash)
+	if err != nil {
+		return sig, err
+	}
+
+	return v.verify(ctx, bip32Path, sig, func(sig schema.SignDataResponse) (schema.Address, error) {
+		return schema.RecoverEIP712Signer(domainSeparatorHash, messageHash, sig)
+	})
+}
+
+func (v *verifyingEthereumApp) EIP712SendStructDefinition(ctx context.Context, component eip712.Component, value []byte) error {
+	return v.app.EIP712SendStructDefinition(ctx, component, value)
+}
+
+func (v *verifyingEthereumApp) EIP712SendStructData(ctx context.Context, component eip712.Component, value []byte) error {
+	return v.app.EIP712SendStructData(ctx, component, value)
+}
+
+func (v *verifyingEthereumApp) EIP712SendClearSigningData(ctx context.Context, action eip712.Action, value []byte) error {
+	return v.app.EIP712SendClearSigningData(ctx, action, value)
+}
+
+func (v *verifyingEthereumApp) ETH2GetPublicKey(ctx context.Context, bip32Path string, needHWConfirm bool) (schema.ETH2PublicKey, error) {
+	return v.app.ETH2GetPublicKey(ctx, bip32Path, needHWConfirm)
+}
+
+func (v *verifyingEthereumApp) ETH2SetWithdrawalIndex(ctx context.Context, index uint32) error {
+	return v.app.ETH2SetWithdrawalIndex(ctx, index)
+}
+
+func (v *verifyingEthereumApp) GetPrivacyPublicKey(ctx context.Context, bip32Path string, needHWConfirm bool) (schema.GetPrivacyPublicKeyResponse, error) {
+	return v.app.GetPrivacyPublicKey(ctx, bip32Path, needHWConfirm)
+}
+
+func (v *verifyingEthereumApp) GetPrivacySharedSecret(ctx context.Context, bip32Path string, remotePublicKey []byte, needHWConfirm bool) (schema.GetPrivacySharedSecretResponse, error) {
+	return v.app.GetPrivacySharedSecret(ctx, bip32Path, remotePublicKey, needHWConfirm)
+}
+
+func (v *verifyingEthereumApp) GetChallenge(ctx context.Context) (schema.Challenge, error) {
+	return v.app.GetChallenge(ctx)
+}
+
+func (v *verifyingEthereumApp) ProvideDomainNameInformation(ctx context.Context, info []byte) error {
+	return v.app.ProvideDomainNameInformation(ctx, info)
+}
+
+func (v *verifyingEthereumApp) ProvideNFTInformation(ctx context.Context, info []byte) error {
+	return v.app.ProvideNFTInformation(ctx, info)
+}
+
+func (v *verifyingEthereumApp) ProvideERC20Information(ctx context.Context, info []byte) (schema.ProvideERC20InfoResponse, error) {
+	return v.app.ProvideERC20Information(ctx, info)
+}
+
+func (v *verifyingEthereumApp) SetPlugin(ctx context.Context, info []byte) error {
+	return v.app.SetPlugin(ctx, info)
+}
+
+func (v *verifyingEthereumApp) SetExternalPlugin(ctx context.Context, payload []byte, signature []byte) error {
+	return v.app.SetExternalPlugin(ctx, payload, signature)
+}
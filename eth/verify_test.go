@@ -0,0 +1,66 @@
+package eth_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/emulator"
+	"github.com/ntchjb/ledger-go/eth/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// Protocol that flips Y parity of signatures of personal messages, as a device with V recovery bug would do
+type flippingParityProtocol struct {
+	adpu.Protocol
+}
+
+func (p *flippingParityProtocol) Send(ctx context.Context, cla, ins, p1, p2 uint8, data []byte) ([]byte, uint16, error) {
+	response, sw, err := p.Protocol.Send(ctx, cla, ins, p1, p2, data)
+	if err == nil && ins == eth.ADPU_INS_SIGN_PERSONAL_MESSAGE && sw == adpu.SW_OK && len(response) > 0 {
+		response[0] = 27 + 28 - response[0]
+	}
+
+	return response, sw, err
+}
+
+func newTestProtocol(t *testing.T) adpu.Protocol {
+	proto, err := emulator.NewProtocol(mustDecodeHex("5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"), slog.Default())
+	assert.NoError(t, err)
+
+	return proto
+}
+
+func TestEthereumApp_WithSignatureVerification(t *testing.T) {
+	ctx := context.Background()
+	app := eth.NewEthereumApp(newTestProtocol(t), slog.Default(), eth.WithSignatureVerification())
+	to := schema.Address(mustDecodeHex("d8da6bf26964af9d7eed9e03e53415d37aa96045"))
+	mail := mailMessage()
+	domainHash, messageHash, err := mail.Hash()
+	assert.NoError(t, err)
+
+	_, err = app.SignTransaction(ctx, testBIP32Path, legacyTx(nil))
+	assert.NoError(t, err)
+	_, err = app.SignTransaction(ctx, testBIP32Path, legacyTx(nil, 0x01_0000_0089))
+	assert.NoError(t, err)
+	_, err = app.SignTypedTransaction(ctx, testBIP32Path, &schema.DynamicFeeTx{ChainID: 10, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2), Gas: 21000, To: &to})
+	assert.NoError(t, err)
+	_, err = app.SignPersonalMessage(ctx, testBIP32Path, []byte("Hello, Ledger!"))
+	assert.NoError(t, err)
+	_, err = app.SignEIP712Message(ctx, testBIP32Path, mail)
+	assert.NoError(t, err)
+	_, err = app.SignEIP712MessageHash(ctx, testBIP32Path, domainHash[:], messageHash[:])
+	assert.NoError(t, err)
+}
+
+func TestEthereumApp_WithSignatureVerification_Mismatch(t *testing.T) {
+	app := eth.NewEthereumApp(&flippingParityProtocol{Protocol: newTestProtocol(t)}, slog.Default(), eth.WithSignatureVerification())
+
+	sig, err := app.SignPersonalMessage(context.Background(), testBIP32Path, []byte("Hello, Ledger!"))
+
+	assert.ErrorIs(t, err, schema.ErrSignerMismatch)
+	assert.Equal(t, schema.SignDataResponse{}, sig)
+}
@@ -3,6 +3,8 @@ module github.com/ntchjb/ledger-go
 go 1.22
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
+	github.com/google/gousb v1.1.3
 	github.com/holiman/uint256 v1.3.1
 	github.com/ntchjb/gohid v0.0.0-20240820093356-86de04e71841
 	github.com/stretchr/testify v1.9.0
@@ -13,7 +15,6 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/google/gousb v1.1.3 // indirect
 	github.com/ntchjb/usbip-virtual-device v0.0.0-20240815145631-148bfeba3613 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/sys v0.23.0 // indirect
@@ -14,6 +14,16 @@ func (d HexDisplay) MarshalText() (text []byte, err error) {
 	return
 }
 
+func (d *HexDisplay) UnmarshalText(text []byte) error {
+	buf := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(buf, text); err != nil {
+		return err
+	}
+	*d = buf
+
+	return nil
+}
+
 func (d HexDisplay) String() string {
 	str, _ := d.MarshalText()
 
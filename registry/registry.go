@@ -0,0 +1,182 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/ntchjb/ledger-go/eth"
+)
+
+const (
+	// Default BIP-32 path of address used to identify devices
+	DEFAULT_PROBE_PATH = "m'/44'/60'/0'/0/0"
+)
+
+var (
+	ErrDeviceNotRegistered = errors.New("device is not registered")
+	ErrDuplicateID         = errors.New("device ID is already registered")
+	ErrEmptyID             = errors.New("device ID is empty")
+)
+
+// Get a stable ID of device connected via the protocol
+type Identifier func(ctx context.Context, proto adpu.Protocol) (string, error)
+
+// Identify device by Ethereum address at the probe path, which is unique per seed
+//
+// Ethereum app must be opened on device
+func AddressIdentifier(probePath string, logger *slog.Logger) Identifier {
+	return func(ctx context.Context, proto adpu.Protocol) (string, error) {
+		res, err := eth.NewEthereumApp(proto, logger).GetAddress(ctx, probePath, false, false, 0)
+		if err != nil {
+			return "", fmt.Errorf("unable to get address at probe path %s: %w", probePath, err)
+		}
+
+		return res.Address.String(), nil
+	}
+}
+
+// Identify device by its USB serial number
+//
+// Ledger devices may share the same serial number, so it is only suitable if serial numbers are known to be distinct
+func SerialNumberIdentifier(usbDevice device.USBDevice) Identifier {
+	return func(ctx context.Context, proto adpu.Protocol) (string, error) {
+		serial, err := usbDevice.SerialNumber()
+		if err != nil {
+			return "", fmt.Errorf("unable to get serial number: %w", err)
+		}
+
+		return serial, nil
+	}
+}
+
+type Registry interface {
+	// Register the protocol by identifying device with the identifier, and get its ID
+	Add(ctx context.Context, proto adpu.Protocol, identify Identifier) (string, error)
+	// Register the protocol by the known ID
+	Register(id string, proto adpu.Protocol) error
+	// Unregister the protocol by ID
+	Remove(id string) error
+	// Get protocol by ID
+	Protocol(id string) (adpu.Protocol, error)
+	// Get Ethereum app of device by ID
+	EthereumApp(id string) (eth.EthereumApp, error)
+	// List registered IDs in order
+	IDs() []string
+}
+
+type entry struct {
+	proto  adpu.Protocol
+	ethApp eth.EthereumApp
+}
+
+type registryImpl struct {
+	logger *slog.Logger
+	// Options of Ethereum apps of registered devices
+	ethAppOpts []eth.EthereumAppOption
+
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+// Create registry, where Ethereum app of each registered device is created with given options i.e. `eth.WithPolicy`
+func NewRegistry(logger *slog.Logger, ethAppOpts ...eth.EthereumAppOption) Registry {
+	return &registryImpl{
+		logger:     logger,
+		ethAppOpts: ethAppOpts,
+		entries:    make(map[string]entry),
+	}
+}
+
+func (r *registryImpl) Add(ctx context.Context, proto adpu.Protocol, identify Identifier) (string, error) {
+	id, err := identify(ctx, proto)
+	if err != nil {
+		return "", fmt.Errorf("unable to identify device: %w", err)
+	}
+	if err := r.Register(id, proto); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *registryImpl) Register(id string, proto adpu.Protocol) error {
+	if id == "" {
+		return ErrEmptyID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateID, id)
+	}
+	r.entries[id] = entry{
+		proto:  proto,
+		ethApp: eth.NewEthereumApp(proto, r.logger.With("deviceID", id), r.ethAppOpts...),
+	}
+	r.logger.Debug("Device is registered", "deviceID", id)
+
+	return nil
+}
+
+func (r *registryImpl) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrDeviceNotRegistered, id)
+	}
+	delete(r.entries, id)
+	r.logger.Debug("Device is removed", "deviceID", id)
+
+	return nil
+}
+
+func (r *registryImpl) get(id string) (entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.entries[id]
+	if !ok {
+		return entry{}, fmt.Errorf("%w: %s", ErrDeviceNotRegistered, id)
+	}
+
+	return e, nil
+}
+
+func (r *registryImpl) Protocol(id string) (adpu.Protocol, error) {
+	e, err := r.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.proto, nil
+}
+
+func (r *registryImpl) EthereumApp(id string) (eth.EthereumApp, error) {
+	e, err := r.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.ethApp, nil
+}
+
+func (r *registryImpl) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.entries))
+	for id := range r.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,146 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/ntchjb/ledger-go/adpu"
+	"github.com/ntchjb/ledger-go/device"
+	"github.com/ntchjb/ledger-go/eth"
+	"github.com/ntchjb/ledger-go/eth/emulator"
+	"github.com/ntchjb/ledger-go/eth/policy"
+	"github.com/ntchjb/ledger-go/registry"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newEmulator(t *testing.T, seed byte) adpu.Protocol {
+	proto, err := emulator.NewProtocol([]byte{seed, seed, seed, seed, seed, seed, seed, seed, seed, seed, seed, seed, seed, seed, seed, seed}, slog.Default())
+	assert.NoError(t, err)
+
+	return proto
+}
+
+func TestRegistry_AddressIdentifier(t *testing.T) {
+	ctx := context.Background()
+	r := registry.NewRegistry(slog.Default())
+	identify := registry.AddressIdentifier(registry.DEFAULT_PROBE_PATH, slog.Default())
+	treasuryA := newEmulator(t, 0x01)
+	treasuryB := newEmulator(t, 0x02)
+
+	idA, err := r.Add(ctx, treasuryA, identify)
+	assert.NoError(t, err)
+	idB, err := r.Add(ctx, treasuryB, identify)
+	assert.NoError(t, err)
+	assert.NotEqual(t, idA, idB)
+
+	// Same device cannot be registered twice
+	_, err = r.Add(ctx, treasuryA, identify)
+	assert.ErrorIs(t, err, registry.ErrDuplicateID)
+
+	for id, proto := range map[string]adpu.Protocol{idA: treasuryA, idB: treasuryB} {
+		registered, err := r.Protocol(id)
+		assert.NoError(t, err)
+		assert.Equal(t, proto, registered)
+
+		// Lookups are routed to the device which owns the ID
+		app, err := r.EthereumApp(id)
+		assert.NoError(t, err)
+		res, err := app.GetAddress(ctx, registry.DEFAULT_PROBE_PATH, false, false, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, id, res.Address.String())
+	}
+
+	ids := r.IDs()
+	assert.Len(t, ids, 2)
+	assert.ElementsMatch(t, []string{idA, idB}, ids)
+	assert.True(t, ids[0] < ids[1])
+
+	assert.NoError(t, r.Remove(idA))
+	_, err = r.EthereumApp(idA)
+	assert.ErrorIs(t, err, registry.ErrDeviceNotRegistered)
+	assert.ErrorIs(t, r.Remove(idA), registry.ErrDeviceNotRegistered)
+	assert.Equal(t, []string{idB}, r.IDs())
+}
+
+func TestRegistry_SerialNumberIdentifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		serial    string
+		serialErr error
+		err       bool
+		errIs     error
+	}{
+		{
+			name:   "Success",
+			serial: "0001",
+		},
+		{
+			name:  "Error_EmptySerialNumber",
+			err:   true,
+			errIs: registry.ErrEmptyID,
+		},
+		{
+			name:      "Error_SerialNumber",
+			serialErr: errors.New("LIBUSB_ERROR_PIPE"),
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			usbDevice := device.NewMockUSBDevice(ctrl)
+			usbDevice.EXPECT().SerialNumber().Return(test.serial, test.serialErr)
+			proto := adpu.NewMockProtocol(ctrl)
+			r := registry.NewRegistry(slog.Default())
+
+			id, err := r.Add(context.Background(), proto, registry.SerialNumberIdentifier(usbDevice))
+
+			if test.err {
+				assert.Error(t, err)
+				if test.errIs != nil {
+					assert.ErrorIs(t, err, test.errIs)
+				}
+				if test.serialErr != nil {
+					assert.ErrorIs(t, err, test.serialErr)
+				}
+				assert.Empty(t, r.IDs())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.serial, id)
+			registered, err := r.Protocol(id)
+			assert.NoError(t, err)
+			assert.Equal(t, proto, registered)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	r := registry.NewRegistry(slog.Default())
+
+	assert.NoError(t, r.Register("treasury-a", adpu.NewMockProtocol(ctrl)))
+	assert.ErrorIs(t, r.Register("treasury-a", adpu.NewMockProtocol(ctrl)), registry.ErrDuplicateID)
+	assert.ErrorIs(t, r.Register("", adpu.NewMockProtocol(ctrl)), registry.ErrEmptyID)
+	_, err := r.Protocol("treasury-b")
+	assert.ErrorIs(t, err, registry.ErrDeviceNotRegistered)
+}
+
+func TestRegistry_EthereumAppOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	p, err := policy.NewRulePolicy(policy.Rules{})
+	assert.NoError(t, err)
+	r := registry.NewRegistry(slog.Default(), eth.WithPolicy(p))
+	assert.NoError(t, r.Register("treasury-a", adpu.NewMockProtocol(ctrl)))
+
+	app, err := r.EthereumApp("treasury-a")
+	assert.NoError(t, err)
+	_, err = app.GetConfiguration(context.Background())
+
+	// Request is denied by policy without reaching device
+	assert.ErrorIs(t, err, policy.ErrPolicyViolation)
+}
@@ -3,15 +3,11 @@ package main
 import (
 	"context"
 	"encoding/hex"
-	"fmt"
 	"log/slog"
 	"os"
 	"time"
 
 	"github.com/holiman/uint256"
-	"github.com/ntchjb/gohid/hid"
-	"github.com/ntchjb/gohid/manager"
-	"github.com/ntchjb/gohid/usb"
 	"github.com/ntchjb/ledger-go/adpu"
 	"github.com/ntchjb/ledger-go/device"
 	"github.com/ntchjb/ledger-go/eth"
@@ -28,56 +24,23 @@ func main() {
 		Level: logLevel,
 	}))
 
-	usbCtx := usb.NewGOUSBContext()
-	man := manager.NewDeviceManager(usbCtx, logger)
-
+	usbCtx := device.NewUSBContext()
 	defer func() {
-		if err := man.Close(); err != nil {
-			logger.Error("unable to close device manager", "err", err)
+		if err := usbCtx.Close(); err != nil {
+			logger.Error("unable to close USB context", "err", err)
 		}
 	}()
 
-	// Open Ledger Nano S
-	// hidDevice, err := man.Open(0x2C97, 0x1011, hid.DeviceConfig{
-	// 	StreamLaneCount: hid.DEFAULT_ENDPOINT_STREAM_COUNT,
-	// })
-
-	// Open Ledger Nano S Plus
-	hidDevice, err := man.Open(0x2C97, 0x5011, hid.DeviceConfig{
-		StreamLaneCount: hid.DEFAULT_ENDPOINT_STREAM_COUNT,
-	})
-
+	// Open the first connected Ledger device
+	ledgerDevice, deviceInfo, err := device.OpenFirstUSBDevice(usbCtx, logger)
 	if err != nil {
 		logger.Error("unable to open device", "err", err)
 		return
 	}
-	defer hidDevice.Close()
-
-	if err := hidDevice.SetAutoDetach(true); err != nil {
-		logger.Error("unable to set auto detach", "err", err)
-		return
-	}
-	if err := hidDevice.SetTarget(1, 0, 0); err != nil {
-		logger.Error("unable to set target of hid device", "err", err)
-		return
-	}
-
-	// Get report descriptor
-	desc, err := hidDevice.GetReportDescriptor()
-	if err != nil {
-		logger.Error("unable to get report descriptor", "err", err)
-		return
-	}
-	str, err := desc.String()
-	if err != nil {
-		logger.Error("unable to get descriptor string", "err", err)
-		return
-	}
-	fmt.Printf("%s\n", str)
-	fmt.Printf("%v\n", desc)
+	defer ledgerDevice.Close()
+	logger.Info("Opened device", "device", deviceInfo)
 
 	// Create new Ethereum app instance
-	ledgerDevice := device.NewLedgerDevice(hidDevice)
 	adpuProto := adpu.NewProtocol(ledgerDevice, 1234, logger)
 	ethApp := eth.NewEthereumApp(adpuProto, logger)
 
@@ -8,9 +8,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/ntchjb/gohid/hid"
-	"github.com/ntchjb/gohid/manager"
-	"github.com/ntchjb/gohid/usb"
 	"github.com/ntchjb/ledger-go/adpu"
 	"github.com/ntchjb/ledger-go/device"
 	"github.com/ntchjb/ledger-go/eth"
@@ -24,58 +21,37 @@ func main() {
 		Level: logLevel,
 	}))
 
-	usbCtx := usb.NewGOUSBContext()
-	man := manager.NewDeviceManager(usbCtx, logger)
-
+	usbCtx := device.NewUSBContext()
 	defer func() {
-		if err := man.Close(); err != nil {
-			logger.Error("unable to close device manager", "err", err)
+		if err := usbCtx.Close(); err != nil {
+			logger.Error("unable to close USB context", "err", err)
 		}
 	}()
 
-	// List USB HID devices
-	deviceInfos, err := man.Enumerate(0, 0)
+	// List connected Ledger devices
+	deviceInfos, err := device.Discover(usbCtx)
 	if err != nil {
-		logger.Error("unable to enumerate devices", "err", err)
+		logger.Error("unable to discover devices", "err", err)
 		return
 	}
-
-	fmt.Printf("DeviceInfos:\n%s\n", deviceInfos)
-
-	// Open Ledger Nano S
-	hidDevice, err := man.Open(0x2C97, 0x1011, hid.DeviceConfig{
-		StreamLaneCount: hid.DEFAULT_ENDPOINT_STREAM_COUNT,
-	})
-	if err != nil {
-		logger.Error("unable to open device", "err", err)
-		return
-	}
-	defer hidDevice.Close()
-	if err := hidDevice.SetAutoDetach(true); err != nil {
-		logger.Error("unable to set auto detach", "err", err)
-		return
-	}
-	if err := hidDevice.SetTarget(1, 0, 0); err != nil {
-		logger.Error("unable to set target of hid device", "err", err)
-		return
+	for _, info := range deviceInfos {
+		fmt.Printf("Found %s\n", info)
 	}
 
-	// Get report descriptor
-	desc, err := hidDevice.GetReportDescriptor()
-	if err != nil {
-		logger.Error("unable to get report descriptor", "err", err)
+	if len(deviceInfos) == 0 {
+		logger.Error("no Ledger device is connected")
 		return
 	}
-	str, err := desc.String()
+
+	// Open the first connected Ledger device
+	ledgerDevice, err := device.OpenUSBDevice(usbCtx, deviceInfos[0], logger)
 	if err != nil {
-		logger.Error("unable to get descriptor string", "err", err)
+		logger.Error("unable to open device", "err", err)
 		return
 	}
-	fmt.Printf("%s\n", str)
-	fmt.Printf("%v\n", desc)
+	defer ledgerDevice.Close()
 
 	// Create new Ethereum app instance
-	ledgerDevice := device.NewLedgerDevice(hidDevice)
 	adpuProto := adpu.NewProtocol(ledgerDevice, 1234, logger)
 	ethApp := eth.NewEthereumApp(adpuProto, logger)
 
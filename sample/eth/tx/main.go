@@ -11,9 +11,6 @@ import (
 	"time"
 
 	"github.com/holiman/uint256"
-	"github.com/ntchjb/gohid/hid"
-	"github.com/ntchjb/gohid/manager"
-	"github.com/ntchjb/gohid/usb"
 	"github.com/ntchjb/ledger-go/adpu"
 	"github.com/ntchjb/ledger-go/device"
 	"github.com/ntchjb/ledger-go/eth"
@@ -119,39 +116,27 @@ func main() {
 		Level: logLevel,
 	}))
 
-	usbCtx := usb.NewGOUSBContext()
-	man := manager.NewDeviceManager(usbCtx, logger)
+	usbCtx := device.NewUSBContext()
+	defer func() {
+		if err := usbCtx.Close(); err != nil {
+			logger.Error("unable to close USB context", "err", err)
+		}
+	}()
 
 	httpCli := &http.Client{
 		Timeout: time.Second * 30,
 	}
 
-	defer func() {
-		if err := man.Close(); err != nil {
-			logger.Error("unable to close device manager", "err", err)
-		}
-	}()
-
-	// Open Ledger Nano S Plus
-	hidDevice, err := man.Open(0x2C97, 0x5011, hid.DeviceConfig{
-		StreamLaneCount: hid.DEFAULT_ENDPOINT_STREAM_COUNT,
-	})
+	// Open the first connected Ledger device
+	ledgerDevice, deviceInfo, err := device.OpenFirstUSBDevice(usbCtx, logger)
 	if err != nil {
 		logger.Error("unable to open device", "err", err)
 		return
 	}
-	defer hidDevice.Close()
-	if err := hidDevice.SetAutoDetach(true); err != nil {
-		logger.Error("unable to set auto detach", "err", err)
-		return
-	}
-	if err := hidDevice.SetTarget(1, 0, 0); err != nil {
-		logger.Error("unable to set target of hid device", "err", err)
-		return
-	}
+	defer ledgerDevice.Close()
+	logger.Info("Opened device", "device", deviceInfo)
 
 	// Create new Ethereum app instance
-	ledgerDevice := device.NewLedgerDevice(hidDevice)
 	adpuProto := adpu.NewProtocol(ledgerDevice, 1234, logger)
 	ethApp := eth.NewEthereumApp(adpuProto, logger)
 
@@ -217,4 +202,11 @@ func main() {
 		return
 	}
 	logger.Info("Signature", "R", log.HexDisplay(txSig.R[:]), "S", log.HexDisplay(txSig.S[:]), "V", txSig.V)
+
+	signedTx, err := schema.AssembleSignedTx(rawTx, txSig)
+	if err != nil {
+		logger.Error("unable to assemble signed tx", "err", err)
+		return
+	}
+	logger.Info("Signed tx", "raw", log.HexDisplay(signedTx.Raw), "hash", log.HexDisplay(signedTx.Hash[:]))
 }